@@ -18,6 +18,8 @@ import (
 // ABI represents the ethereum abi format
 type ABI struct {
 	Constructor        *Method
+	Fallback           *Method
+	Receive            *Method
 	Methods            map[string]*Method
 	MethodsBySignature map[string]*Method
 	Events             map[string]*Event
@@ -34,6 +36,25 @@ func (a *ABI) GetMethodBySignature(methodSignature string) *Method {
 	return m
 }
 
+// GetOverloads returns all the methods with the given solidity name sorted
+// in declaration order (i.e. 'transfer', 'transfer0', 'transfer1').
+func (a *ABI) GetOverloads(name string) []*Method {
+	res := []*Method{}
+	if m := a.Methods[name]; m != nil && m.Name == name {
+		res = append(res, m)
+	}
+	for idx := 0; ; idx++ {
+		m, ok := a.Methods[fmt.Sprintf("%s%d", name, idx)]
+		if !ok {
+			break
+		}
+		if m.Name == name {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
 func (a *ABI) addError(e *Error) {
 	if len(a.Errors) == 0 {
 		a.Errors = map[string]*Error{}
@@ -117,8 +138,9 @@ func (a *ABI) UnmarshalJSON(data []byte) error {
 		Type            string
 		Name            string
 		Constant        bool
+		Payable         bool
 		Anonymous       bool
-		StateMutability string
+		StateMutability StateMutability
 		Inputs          []*ArgumentStr
 		Outputs         []*ArgumentStr
 	}
@@ -128,6 +150,19 @@ func (a *ABI) UnmarshalJSON(data []byte) error {
 	}
 
 	for _, field := range fields {
+		// old abis (solc < 0.4.16) do not include the state mutability
+		// but the 'constant' and 'payable' flags.
+		mutability := field.StateMutability
+		if mutability == "" {
+			if field.Payable {
+				mutability = StateMutabilityPayable
+			} else if field.Constant {
+				mutability = StateMutabilityView
+			} else {
+				mutability = StateMutabilityNonPayable
+			}
+		}
+
 		switch field.Type {
 		case "constructor":
 			if a.Constructor != nil {
@@ -137,16 +172,21 @@ func (a *ABI) UnmarshalJSON(data []byte) error {
 			if err != nil {
 				panic(err)
 			}
-			a.Constructor = &Method{
-				Inputs: input,
+			a.Constructor = newMethod("", mutability, input, nil)
+
+		case "fallback":
+			if a.Fallback != nil {
+				return fmt.Errorf("multiple fallback declaration")
 			}
+			a.Fallback = newMethod("", mutability, nil, nil)
 
-		case "function", "":
-			c := field.Constant
-			if field.StateMutability == "view" || field.StateMutability == "pure" {
-				c = true
+		case "receive":
+			if a.Receive != nil {
+				return fmt.Errorf("multiple receive declaration")
 			}
+			a.Receive = newMethod("", StateMutabilityPayable, nil, nil)
 
+		case "function", "":
 			inputs, err := NewTupleTypeFromArgs(field.Inputs)
 			if err != nil {
 				panic(err)
@@ -155,13 +195,7 @@ func (a *ABI) UnmarshalJSON(data []byte) error {
 			if err != nil {
 				panic(err)
 			}
-			method := &Method{
-				Name:    field.Name,
-				Const:   c,
-				Inputs:  inputs,
-				Outputs: outputs,
-			}
-			a.addMethod(method)
+			a.addMethod(newMethod(field.Name, mutability, inputs, outputs))
 
 		case "event":
 			input, err := NewTupleTypeFromArgs(field.Inputs)
@@ -186,10 +220,6 @@ func (a *ABI) UnmarshalJSON(data []byte) error {
 			}
 			a.addError(errObj)
 
-		case "fallback":
-		case "receive":
-			// do nothing
-
 		default:
 			return fmt.Errorf("unknown field type '%s'", field.Type)
 		}
@@ -197,12 +227,42 @@ func (a *ABI) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// StateMutability is the state mutability of a method
+type StateMutability string
+
+const (
+	// StateMutabilityPure does not read nor modify the state
+	StateMutabilityPure StateMutability = "pure"
+
+	// StateMutabilityView does not modify the state
+	StateMutabilityView StateMutability = "view"
+
+	// StateMutabilityNonPayable modifies the state but does not accept value
+	StateMutabilityNonPayable StateMutability = "nonpayable"
+
+	// StateMutabilityPayable modifies the state and accepts value
+	StateMutabilityPayable StateMutability = "payable"
+)
+
 // Method is a callable function in the contract
 type Method struct {
-	Name    string
-	Const   bool
-	Inputs  *Type
-	Outputs *Type
+	Name            string
+	Const           bool
+	Payable         bool
+	StateMutability StateMutability
+	Inputs          *Type
+	Outputs         *Type
+}
+
+func newMethod(name string, mutability StateMutability, inputs, outputs *Type) *Method {
+	return &Method{
+		Name:            name,
+		Const:           mutability == StateMutabilityView || mutability == StateMutabilityPure,
+		Payable:         mutability == StateMutabilityPayable,
+		StateMutability: mutability,
+		Inputs:          inputs,
+		Outputs:         outputs,
+	}
 }
 
 // Sig returns the signature of the method
@@ -229,10 +289,14 @@ func (m *Method) Encode(args interface{}) ([]byte, error) {
 	return data, nil
 }
 
+// ErrEmptyResponse is returned when decoding an empty method output. It usually
+// means that there is no contract deployed at the called address.
+var ErrEmptyResponse = fmt.Errorf("empty response")
+
 // Decode decodes the output with this function
 func (m *Method) Decode(data []byte) (map[string]interface{}, error) {
 	if len(data) == 0 {
-		return nil, fmt.Errorf("empty response")
+		return nil, ErrEmptyResponse
 	}
 	respInterface, err := Decode(m.Outputs, data)
 	if err != nil {
@@ -252,12 +316,21 @@ func MustNewMethod(name string) *Method {
 }
 
 func NewMethod(name string) (*Method, error) {
-	name, inputs, outputs, err := parseMethodSignature(name)
+	name, inputs, outputs, modifiers, err := parseMethodSignature(name)
 	if err != nil {
 		return nil, err
 	}
-	m := &Method{Name: name, Inputs: inputs, Outputs: outputs}
-	return m, nil
+	return newMethod(name, parseStateMutability(modifiers), inputs, outputs), nil
+}
+
+func parseStateMutability(modifiers string) StateMutability {
+	for _, modifier := range strings.Fields(modifiers) {
+		switch mutability := StateMutability(modifier); mutability {
+		case StateMutabilityPure, StateMutabilityView, StateMutabilityPayable:
+			return mutability
+		}
+	}
+	return StateMutabilityNonPayable
 }
 
 var (
@@ -265,41 +338,43 @@ var (
 	funcRegexpWithoutReturn = regexp.MustCompile(`(\w*)\s*\((.*)\)(.*)`)
 )
 
-func parseMethodSignature(name string) (string, *Type, *Type, error) {
+func parseMethodSignature(name string) (string, *Type, *Type, string, error) {
 	name = strings.Replace(name, "\n", " ", -1)
 	name = strings.Replace(name, "\t", " ", -1)
 
 	name = strings.TrimPrefix(name, "function ")
 	name = strings.TrimSpace(name)
 
-	var funcName, inputArgs, outputArgs string
+	var funcName, inputArgs, outputArgs, modifiers string
 
 	if strings.Contains(name, "returns") {
 		matches := funcRegexpWithReturn.FindAllStringSubmatch(name, -1)
 		if len(matches) == 0 {
-			return "", nil, nil, fmt.Errorf("no matches found")
+			return "", nil, nil, "", fmt.Errorf("no matches found")
 		}
 		funcName = strings.TrimSpace(matches[0][1])
 		inputArgs = strings.TrimSpace(matches[0][2])
+		modifiers = strings.TrimSpace(matches[0][3])
 		outputArgs = strings.TrimSpace(matches[0][4])
 	} else {
 		matches := funcRegexpWithoutReturn.FindAllStringSubmatch(name, -1)
 		if len(matches) == 0 {
-			return "", nil, nil, fmt.Errorf("no matches found")
+			return "", nil, nil, "", fmt.Errorf("no matches found")
 		}
 		funcName = strings.TrimSpace(matches[0][1])
 		inputArgs = strings.TrimSpace(matches[0][2])
+		modifiers = strings.TrimSpace(matches[0][3])
 	}
 
 	input, err := NewType("tuple(" + inputArgs + ")")
 	if err != nil {
-		return "", nil, nil, err
+		return "", nil, nil, "", err
 	}
 	output, err := NewType("tuple(" + outputArgs + ")")
 	if err != nil {
-		return "", nil, nil, err
+		return "", nil, nil, "", err
 	}
-	return funcName, input, output, nil
+	return funcName, input, output, modifiers, nil
 }
 
 // Event is a triggered log mechanism
@@ -335,11 +410,18 @@ func MustNewEvent(name string) *Event {
 
 // NewEvent creates a new solidity event object using the signature
 func NewEvent(name string) (*Event, error) {
+	name = strings.TrimSpace(name)
+	anonymous := strings.HasSuffix(name, " anonymous")
+	if anonymous {
+		name = strings.TrimSpace(strings.TrimSuffix(name, " anonymous"))
+	}
 	name, typ, err := parseEventOrErrorSignature("event ", name)
 	if err != nil {
 		return nil, err
 	}
-	return NewEventFromType(name, typ), nil
+	evnt := NewEventFromType(name, typ)
+	evnt.Anonymous = anonymous
+	return evnt, nil
 }
 
 // Error is a solidity error object
@@ -357,6 +439,32 @@ func NewError(name string) (*Error, error) {
 	return &Error{Name: name, Inputs: typ}, nil
 }
 
+// Sig returns the signature of the error
+func (e *Error) Sig() string {
+	return buildSignature(e.Name, e.Inputs)
+}
+
+// ID returns the id of the error used in the revert data
+func (e *Error) ID() []byte {
+	k := acquireKeccak()
+	k.Write([]byte(e.Sig()))
+	dst := k.Sum(nil)[:4]
+	releaseKeccak(k)
+	return dst
+}
+
+// Decode decodes the inputs of the error from the revert data
+func (e *Error) Decode(data []byte) (map[string]interface{}, error) {
+	if !bytes.HasPrefix(data, e.ID()) {
+		return nil, fmt.Errorf("revert data does not match error %s", e.Name)
+	}
+	respInterface, err := Decode(e.Inputs, data[4:])
+	if err != nil {
+		return nil, err
+	}
+	return respInterface.(map[string]interface{}), nil
+}
+
 func parseEventOrErrorSignature(prefix string, name string) (string, *Type, error) {
 	if !strings.HasPrefix(name, prefix) {
 		return "", nil, fmt.Errorf("prefix '%s' not found", prefix)
@@ -441,13 +549,21 @@ func NewABIFromList(humanReadableAbi []string) (*ABI, error) {
 	res := &ABI{}
 	for _, c := range humanReadableAbi {
 		if strings.HasPrefix(c, "constructor") {
-			typ, err := NewType("tuple" + strings.TrimPrefix(c, "constructor"))
+			_, typ, _, modifiers, err := parseMethodSignature(c)
 			if err != nil {
 				return nil, err
 			}
-			res.Constructor = &Method{
-				Inputs: typ,
+			res.Constructor = newMethod("", parseStateMutability(modifiers), typ, nil)
+
+		} else if strings.HasPrefix(c, "fallback") {
+			_, _, _, modifiers, err := parseMethodSignature(c)
+			if err != nil {
+				return nil, err
 			}
+			res.Fallback = newMethod("", parseStateMutability(modifiers), nil, nil)
+
+		} else if strings.HasPrefix(c, "receive") {
+			res.Receive = newMethod("", StateMutabilityPayable, nil, nil)
 
 		} else if strings.HasPrefix(c, "function ") {
 			method, err := NewMethod(c)
@@ -471,7 +587,7 @@ func NewABIFromList(humanReadableAbi []string) (*ABI, error) {
 			res.addError(errTyp)
 
 		} else {
-			return nil, fmt.Errorf("either event or function expected")
+			return nil, fmt.Errorf("either event, error or function expected")
 		}
 	}
 	return res, nil
@@ -1,7 +1,9 @@
 package abi
 
 import (
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -11,15 +13,17 @@ import (
 
 func TestAbi(t *testing.T) {
 	methodOutput := &Method{
-		Name:    "abc",
-		Inputs:  MustNewType("tuple()"),
-		Outputs: MustNewType("tuple()"),
+		Name:            "abc",
+		StateMutability: StateMutabilityNonPayable,
+		Inputs:          MustNewType("tuple()"),
+		Outputs:         MustNewType("tuple()"),
 	}
 	balanceFunc := &Method{
-		Name:    "balanceOf",
-		Const:   true,
-		Inputs:  MustNewType("tuple(address owner)"),
-		Outputs: MustNewType("tuple(uint256 balance)"),
+		Name:            "balanceOf",
+		Const:           true,
+		StateMutability: StateMutabilityView,
+		Inputs:          MustNewType("tuple(address owner)"),
+		Outputs:         MustNewType("tuple(uint256 balance)"),
 	}
 
 	cases := []struct {
@@ -245,38 +249,48 @@ func TestAbi_HumanReadable(t *testing.T) {
 
 	expect := &ABI{
 		Constructor: &Method{
-			Inputs: MustNewType("tuple(string symbol, string name)"),
+			StateMutability: StateMutabilityNonPayable,
+			Inputs:          MustNewType("tuple(string symbol, string name)"),
 		},
 		Methods: map[string]*Method{
 			"transferFrom": {
-				Name:    "transferFrom",
-				Inputs:  MustNewType("tuple(address from, address to, uint256 value)"),
-				Outputs: MustNewType("tuple()"),
+				Name:            "transferFrom",
+				StateMutability: StateMutabilityNonPayable,
+				Inputs:          MustNewType("tuple(address from, address to, uint256 value)"),
+				Outputs:         MustNewType("tuple()"),
 			},
 			"balanceOf": {
-				Name:    "balanceOf",
-				Inputs:  MustNewType("tuple(address owner)"),
-				Outputs: MustNewType("tuple(uint256 balance)"),
+				Name:            "balanceOf",
+				Const:           true,
+				StateMutability: StateMutabilityView,
+				Inputs:          MustNewType("tuple(address owner)"),
+				Outputs:         MustNewType("tuple(uint256 balance)"),
 			},
 			"balanceOf0": {
-				Name:    "balanceOf",
-				Inputs:  MustNewType("tuple()"),
-				Outputs: MustNewType("tuple()"),
+				Name:            "balanceOf",
+				Const:           true,
+				StateMutability: StateMutabilityView,
+				Inputs:          MustNewType("tuple()"),
+				Outputs:         MustNewType("tuple()"),
 			},
 			"addPerson": {
-				Name:    "addPerson",
-				Inputs:  MustNewType("tuple(tuple(string name, uint16 age) person)"),
-				Outputs: MustNewType("tuple()"),
+				Name:            "addPerson",
+				StateMutability: StateMutabilityNonPayable,
+				Inputs:          MustNewType("tuple(tuple(string name, uint16 age) person)"),
+				Outputs:         MustNewType("tuple()"),
 			},
 			"addPeople": {
-				Name:    "addPeople",
-				Inputs:  MustNewType("tuple(tuple(string name, uint16 age)[] person)"),
-				Outputs: MustNewType("tuple()"),
+				Name:            "addPeople",
+				StateMutability: StateMutabilityNonPayable,
+				Inputs:          MustNewType("tuple(tuple(string name, uint16 age)[] person)"),
+				Outputs:         MustNewType("tuple()"),
 			},
 			"getPerson": {
-				Name:    "getPerson",
-				Inputs:  MustNewType("tuple(uint256 id)"),
-				Outputs: MustNewType("tuple(tuple(string name, uint16 age))"),
+				Name:            "getPerson",
+				Const:           true,
+				StateMutability: StateMutabilityView,
+				Inputs:          MustNewType("tuple(uint256 id)"),
+				Outputs:         MustNewType("tuple(tuple(string name, uint16 age))"),
 			},
 		},
 		Events: map[string]*Event{
@@ -344,7 +358,7 @@ func TestAbi_ParseMethodSignature(t *testing.T) {
 	}
 
 	for _, c := range cases {
-		name, input, output, err := parseMethodSignature(c.signature)
+		name, input, output, _, err := parseMethodSignature(c.signature)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -376,3 +390,115 @@ func TestABI_FROMFILE(t *testing.T) {
 	t.Log(abi.Constructor)
 
 }
+
+func TestAbi_MarshalJSON(t *testing.T) {
+	abi, err := NewABIFromList([]string{
+		"constructor(address owner)",
+		"function balanceOf(address owner) view returns (uint256 balance)",
+		"function addPeople(tuple(string name, uint16 age)[] people)",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"error InsufficientBalance(address owner, uint256 balance)",
+	})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(abi)
+	require.NoError(t, err)
+
+	expected := `[
+		{"inputs":[{"name":"owner","type":"address"}],"stateMutability":"nonpayable","type":"constructor"},
+		{"inputs":[{"components":[{"name":"name","type":"string"},{"name":"age","type":"uint16"}],"name":"people","type":"tuple[]"}],"name":"addPeople","outputs":[],"stateMutability":"nonpayable","type":"function"},
+		{"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"balance","type":"uint256"}],"stateMutability":"view","type":"function"},
+		{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"},
+		{"inputs":[{"name":"owner","type":"address"},{"name":"balance","type":"uint256"}],"name":"InsufficientBalance","type":"error"}
+	]`
+	require.JSONEq(t, expected, string(data))
+}
+
+func TestAbi_RoundTrip(t *testing.T) {
+	files, err := filepath.Glob("../asset/*/*.abi")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			require.NoError(t, err)
+
+			abi, err := NewABI(string(data))
+			require.NoError(t, err)
+
+			// json round trip
+			raw, err := json.Marshal(abi)
+			require.NoError(t, err)
+
+			abi2, err := NewABI(string(raw))
+			require.NoError(t, err)
+			require.Equal(t, abi, abi2)
+
+			// human readable round trip does not keep the internal types
+			// but it must resolve to the same signatures
+			abi3, err := NewABIFromList(abi.HumanReadable())
+			require.NoError(t, err)
+			require.Equal(t, abi.HumanReadable(), abi3.HumanReadable())
+
+			for name, method := range abi.Methods {
+				require.Equal(t, method.Sig(), abi3.Methods[name].Sig())
+			}
+			for name, event := range abi.Events {
+				require.Equal(t, event.ID(), abi3.Events[name].ID())
+			}
+		})
+	}
+}
+
+func TestAbi_HumanReadableEvent(t *testing.T) {
+	evnt, err := NewEvent("event Anon(address indexed a) anonymous")
+	require.NoError(t, err)
+	require.True(t, evnt.Anonymous)
+	require.Equal(t, "event Anon(address indexed a) anonymous", evnt.HumanReadable())
+}
+
+func TestAbi_StateMutability(t *testing.T) {
+	const abiStr = `[
+		{"type": "constructor", "payable": true, "inputs": []},
+		{"type": "fallback", "stateMutability": "nonpayable"},
+		{"type": "receive", "stateMutability": "payable"},
+		{"type": "function", "name": "deposit", "stateMutability": "payable"},
+		{"type": "function", "name": "withdraw", "payable": false},
+		{"type": "function", "name": "total", "constant": true, "outputs": [{"name": "", "type": "uint256"}]},
+		{"type": "function", "name": "hash", "stateMutability": "pure", "inputs": [{"name": "a", "type": "bytes"}]},
+		{"type": "function", "name": "hash", "stateMutability": "pure", "inputs": [{"name": "a", "type": "bytes32"}]}
+	]`
+
+	abi, err := NewABI(abiStr)
+	require.NoError(t, err)
+
+	require.True(t, abi.Constructor.Payable)
+	require.Equal(t, StateMutabilityPayable, abi.Constructor.StateMutability)
+
+	require.False(t, abi.Fallback.Payable)
+	require.True(t, abi.Receive.Payable)
+
+	require.True(t, abi.GetMethod("deposit").Payable)
+	require.False(t, abi.GetMethod("deposit").Const)
+
+	require.Equal(t, StateMutabilityNonPayable, abi.GetMethod("withdraw").StateMutability)
+
+	require.True(t, abi.GetMethod("total").Const)
+	require.Equal(t, StateMutabilityView, abi.GetMethod("total").StateMutability)
+
+	overloads := abi.GetOverloads("hash")
+	require.Len(t, overloads, 2)
+	require.Equal(t, "hash(bytes)", overloads[0].Sig())
+	require.Equal(t, "hash(bytes32)", overloads[1].Sig())
+	require.Empty(t, abi.GetOverloads("unknown"))
+
+	// human readable round trip
+	abi2, err := NewABIFromList(abi.HumanReadable())
+	require.NoError(t, err)
+	require.Equal(t, abi.HumanReadable(), abi2.HumanReadable())
+	require.True(t, abi2.Constructor.Payable)
+	require.False(t, abi2.Fallback.Payable)
+	require.NotNil(t, abi2.Receive)
+	require.Equal(t, StateMutabilityPure, abi2.GetMethod("hash").StateMutability)
+}
@@ -0,0 +1,234 @@
+package abi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// argumentJSON is the json representation of an input or output
+// argument in the Solidity json abi format
+type argumentJSON struct {
+	Components   []*argumentJSON `json:"components,omitempty"`
+	Indexed      *bool           `json:"indexed,omitempty"`
+	InternalType string          `json:"internalType,omitempty"`
+	Name         string          `json:"name"`
+	Type         string          `json:"type"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. The fields are
+// written in the canonical Solidity json abi format sorted by kind:
+// constructor, fallback, receive, functions, events and errors.
+func (a *ABI) MarshalJSON() ([]byte, error) {
+	fields := []map[string]interface{}{}
+
+	if a.Constructor != nil {
+		fields = append(fields, map[string]interface{}{
+			"type":            "constructor",
+			"inputs":          encodeArguments(a.Constructor.Inputs, false),
+			"stateMutability": a.Constructor.stateMutability(),
+		})
+	}
+	if a.Fallback != nil {
+		fields = append(fields, map[string]interface{}{
+			"type":            "fallback",
+			"stateMutability": a.Fallback.stateMutability(),
+		})
+	}
+	if a.Receive != nil {
+		fields = append(fields, map[string]interface{}{
+			"type":            "receive",
+			"stateMutability": StateMutabilityPayable,
+		})
+	}
+	for _, name := range sortedKeys(a.Methods) {
+		m := a.Methods[name]
+		fields = append(fields, map[string]interface{}{
+			"type":            "function",
+			"name":            m.Name,
+			"inputs":          encodeArguments(m.Inputs, false),
+			"outputs":         encodeArguments(m.Outputs, false),
+			"stateMutability": m.stateMutability(),
+		})
+	}
+	for _, name := range sortedKeys(a.Events) {
+		e := a.Events[name]
+		fields = append(fields, map[string]interface{}{
+			"type":      "event",
+			"name":      e.Name,
+			"inputs":    encodeArguments(e.Inputs, true),
+			"anonymous": e.Anonymous,
+		})
+	}
+	for _, name := range sortedKeys(a.Errors) {
+		e := a.Errors[name]
+		fields = append(fields, map[string]interface{}{
+			"type":   "error",
+			"name":   e.Name,
+			"inputs": encodeArguments(e.Inputs, false),
+		})
+	}
+	return json.Marshal(fields)
+}
+
+// HumanReadable returns the human-readable representation of the abi.
+// The output can be parsed back with NewABIFromList.
+func (a *ABI) HumanReadable() []string {
+	res := []string{}
+
+	if a.Constructor != nil {
+		str := "constructor" + formatHumanArgs(a.Constructor.Inputs)
+		if a.Constructor.Payable {
+			str += " payable"
+		}
+		res = append(res, str)
+	}
+	if a.Fallback != nil {
+		str := "fallback() external"
+		if a.Fallback.Payable {
+			str += " payable"
+		}
+		res = append(res, str)
+	}
+	if a.Receive != nil {
+		res = append(res, "receive() external payable")
+	}
+	for _, name := range sortedKeys(a.Methods) {
+		res = append(res, a.Methods[name].HumanReadable())
+	}
+	for _, name := range sortedKeys(a.Events) {
+		res = append(res, a.Events[name].HumanReadable())
+	}
+	for _, name := range sortedKeys(a.Errors) {
+		res = append(res, a.Errors[name].HumanReadable())
+	}
+	return res
+}
+
+// HumanReadable returns the human-readable representation of the method
+func (m *Method) HumanReadable() string {
+	str := "function " + m.Name + formatHumanArgs(m.Inputs)
+	if mutability := m.stateMutability(); mutability != StateMutabilityNonPayable {
+		str += " " + string(mutability)
+	}
+	if m.Outputs != nil && len(m.Outputs.tuple) != 0 {
+		str += " returns " + formatHumanArgs(m.Outputs)
+	}
+	return str
+}
+
+// HumanReadable returns the human-readable representation of the event
+func (e *Event) HumanReadable() string {
+	str := "event " + e.Name + formatHumanArgs(e.Inputs)
+	if e.Anonymous {
+		str += " anonymous"
+	}
+	return str
+}
+
+// HumanReadable returns the human-readable representation of the error
+func (e *Error) HumanReadable() string {
+	return "error " + e.Name + formatHumanArgs(e.Inputs)
+}
+
+func (m *Method) stateMutability() StateMutability {
+	if m.StateMutability != "" {
+		return m.StateMutability
+	}
+	// the method was not created from an abi,
+	// use the flags to figure out the mutability
+	if m.Payable {
+		return StateMutabilityPayable
+	}
+	if m.Const {
+		return StateMutabilityView
+	}
+	return StateMutabilityNonPayable
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func encodeArguments(t *Type, withIndexed bool) []*argumentJSON {
+	res := []*argumentJSON{}
+	if t == nil {
+		return res
+	}
+	for _, elem := range t.tuple {
+		arg := encodeArgument(elem.Name, elem.Elem)
+		if withIndexed {
+			indexed := elem.Indexed
+			arg.Indexed = &indexed
+		}
+		res = append(res, arg)
+	}
+	return res
+}
+
+func encodeArgument(name string, t *Type) *argumentJSON {
+	arg := &argumentJSON{
+		Name:         name,
+		InternalType: t.itype,
+	}
+
+	// tuple types are written as 'tuple' plus any array suffix
+	// (i.e. tuple[2][]) with the elements in the components field.
+	suffix := ""
+	elem := t
+	for elem.kind == KindSlice || elem.kind == KindArray {
+		if elem.kind == KindSlice {
+			suffix = "[]" + suffix
+		} else {
+			suffix = fmt.Sprintf("[%d]", elem.size) + suffix
+		}
+		elem = elem.elem
+	}
+	if elem.kind != KindTuple {
+		arg.Type = t.String()
+		return arg
+	}
+
+	arg.Type = "tuple" + suffix
+	arg.Components = []*argumentJSON{}
+	for _, i := range elem.tuple {
+		arg.Components = append(arg.Components, encodeArgument(i.Name, i.Elem))
+	}
+	return arg
+}
+
+func formatHumanArgs(t *Type) string {
+	args := []string{}
+	if t != nil {
+		for _, elem := range t.tuple {
+			str := formatHumanType(elem.Elem)
+			if elem.Indexed {
+				str += " indexed"
+			}
+			if elem.Name != "" {
+				str += " " + elem.Name
+			}
+			args = append(args, str)
+		}
+	}
+	return "(" + strings.Join(args, ", ") + ")"
+}
+
+func formatHumanType(t *Type) string {
+	switch t.kind {
+	case KindTuple:
+		return "tuple" + formatHumanArgs(t)
+	case KindSlice:
+		return formatHumanType(t.elem) + "[]"
+	case KindArray:
+		return fmt.Sprintf("%s[%d]", formatHumanType(t.elem), t.size)
+	default:
+		return t.String()
+	}
+}
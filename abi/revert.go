@@ -3,10 +3,13 @@ package abi
 import (
 	"bytes"
 	"fmt"
+	"math/big"
 )
 
 var revertId = []byte{0x8, 0xC3, 0x79, 0xA0}
 
+var panicId = []byte{0x4E, 0x48, 0x7B, 0x71}
+
 func UnpackRevertError(b []byte) (string, error) {
 	if !bytes.HasPrefix(b, revertId) {
 		return "", fmt.Errorf("revert error prefix not found")
@@ -21,3 +24,29 @@ func UnpackRevertError(b []byte) (string, error) {
 	revVal := vals.(map[string]interface{})["0"].(string)
 	return revVal, nil
 }
+
+// UnpackPanicError returns the code of a solidity Panic(uint256) error
+func UnpackPanicError(b []byte) (*big.Int, error) {
+	if !bytes.HasPrefix(b, panicId) {
+		return nil, fmt.Errorf("panic error prefix not found")
+	}
+
+	b = b[4:]
+	tt := MustNewType("tuple(uint256)")
+	vals, err := tt.Decode(b)
+	if err != nil {
+		return nil, err
+	}
+	code := vals.(map[string]interface{})["0"].(*big.Int)
+	return code, nil
+}
+
+// GetErrorByID returns the custom error with the given id
+func (a *ABI) GetErrorByID(id []byte) *Error {
+	for _, e := range a.Errors {
+		if bytes.Equal(e.ID(), id) {
+			return e
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package abi
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,3 +17,40 @@ func TestUnpackRevertError(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "revert reason", reason)
 }
+
+func TestUnpackPanicError(t *testing.T) {
+	data := "4e487b710000000000000000000000000000000000000000000000000000000000000011"
+
+	raw, err := decodeHex(data)
+	assert.NoError(t, err)
+
+	code, err := UnpackPanicError(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0x11), code.Uint64())
+
+	_, err = UnpackPanicError(raw[1:])
+	assert.Error(t, err)
+}
+
+func TestCustomError(t *testing.T) {
+	abi, err := NewABIFromList([]string{
+		"error InsufficientBalance(uint256 available, uint256 required)",
+	})
+	assert.NoError(t, err)
+
+	errObj := abi.Errors["InsufficientBalance"]
+	assert.Equal(t, "InsufficientBalance(uint256,uint256)", errObj.Sig())
+	assert.Equal(t, []byte{0xcf, 0x47, 0x91, 0x81}, errObj.ID())
+
+	data, err := errObj.Inputs.Encode([]interface{}{1, 2})
+	assert.NoError(t, err)
+	data = append(errObj.ID(), data...)
+
+	assert.Equal(t, errObj, abi.GetErrorByID(data[:4]))
+	assert.Nil(t, abi.GetErrorByID(revertId))
+
+	vals, err := errObj.Decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), vals["available"].(*big.Int).Uint64())
+	assert.Equal(t, uint64(2), vals["required"].(*big.Int).Uint64())
+}
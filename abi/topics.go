@@ -113,6 +113,13 @@ func encodeTopic(t *Type, val reflect.Value) (core.Hash, error) {
 	case KindAddress:
 		return encodeTopicAddress(val)
 
+	case KindFixedBytes:
+		return encodeTopicFixedBytes(val)
+
+	case KindString, KindBytes:
+		// dynamic types are stored as the hash of the value
+		return encodeTopicHashed(t, val)
+
 	}
 	return core.Hash{}, fmt.Errorf("not found")
 }
@@ -133,6 +140,42 @@ func encodeTopicAddress(val reflect.Value) (res core.Hash, err error) {
 	return
 }
 
+func encodeTopicFixedBytes(val reflect.Value) (res core.Hash, err error) {
+	var b []byte
+	b, err = encodeFixedBytes(val)
+	if err != nil {
+		return
+	}
+	copy(res[:], b[:])
+	return
+}
+
+func encodeTopicHashed(t *Type, val reflect.Value) (res core.Hash, err error) {
+	var b []byte
+	if t.kind == KindString {
+		if val.Kind() != reflect.String {
+			return core.Hash{}, encodeErr(val, "string")
+		}
+		b = []byte(val.String())
+	} else {
+		if val.Kind() == reflect.Array {
+			val = convertArrayToBytes(val)
+		}
+		if val.Kind() == reflect.String {
+			if b, err = decodeHex(val.String()); err != nil {
+				return
+			}
+		} else {
+			b = val.Bytes()
+		}
+	}
+	k := acquireKeccak()
+	k.Write(b)
+	copy(res[:], k.Sum(nil))
+	releaseKeccak(k)
+	return
+}
+
 func encodeTopicNum(t *Type, val reflect.Value) (res core.Hash, err error) {
 	var b []byte
 	b, err = encodeNum(val)
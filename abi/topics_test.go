@@ -53,6 +53,20 @@ func TestTopicEncoding(t *testing.T) {
 	}
 }
 
+func TestTopicEncoding_Hashed(t *testing.T) {
+	res, err := EncodeTopic(MustNewType("string"), "hello")
+	require.NoError(t, err)
+	require.Equal(t, core.HexToHash("0x1c8aff950685c2ed4bc3174f3472287b56d9517b9c948127319a09a7a36deac8"), res)
+
+	res, err = EncodeTopic(MustNewType("bytes"), []byte("hello"))
+	require.NoError(t, err)
+	require.Equal(t, core.HexToHash("0x1c8aff950685c2ed4bc3174f3472287b56d9517b9c948127319a09a7a36deac8"), res)
+
+	res, err = EncodeTopic(MustNewType("bytes4"), [4]byte{0x1, 0x2, 0x3, 0x4})
+	require.NoError(t, err)
+	require.Equal(t, core.HexToHash("0x0102030400000000000000000000000000000000000000000000000000000000"), res)
+}
+
 func TestIntegrationTopics(t *testing.T) {
 	s := testutil.NewTestServer(t)
 
@@ -38,11 +38,13 @@ type BlockTracker struct {
 type Config struct {
 	Tracker         BlockTrackerInterface
 	MaxBlockBacklog uint64
+	Tag             core.BlockNumber
 }
 
 func DefaultConfig() *Config {
 	return &Config{
 		MaxBlockBacklog: defaultMaxBlockBacklog,
+		Tag:             core.Latest,
 	}
 }
 
@@ -60,6 +62,16 @@ func WithTracker(b BlockTrackerInterface) ConfigOption {
 	}
 }
 
+// WithBlockTag sets the head followed by the tracker. With core.Safe or
+// core.Finalized the tracker only includes blocks once they reach that
+// state, the blocks notified by the tracker interface are only used to
+// know when to check for a new head.
+func WithBlockTag(tag core.BlockNumber) ConfigOption {
+	return func(c *Config) {
+		c.Tag = tag
+	}
+}
+
 func NewBlockTracker(provider BlockProvider, opts ...ConfigOption) *BlockTracker {
 	config := DefaultConfig()
 	for _, opt := range opts {
@@ -95,7 +107,7 @@ func (b *BlockTracker) AcquireLock() Lock {
 func (t *BlockTracker) Init() (err error) {
 	var block *core.Block
 	t.once.Do(func() {
-		block, err = t.provider.GetBlockByNumber(core.Latest, false)
+		block, err = t.provider.GetBlockByNumber(t.config.Tag, false)
 		if err != nil {
 			return
 		}
@@ -163,6 +175,12 @@ func (b *BlockTracker) Start() error {
 	}()
 	// start the polling
 	err := b.subscriber.Track(ctx, func(block *core.Block) error {
+		if b.config.Tag != core.Latest {
+			var err error
+			if block, err = b.provider.GetBlockByNumber(b.config.Tag, false); err != nil {
+				return err
+			}
+		}
 		return b.HandleReconcile(block)
 	})
 	if err != nil {
@@ -224,7 +242,9 @@ func (t *BlockTracker) handleReconcileImpl(block *core.Block) ([]*core.Block, in
 
 	count := uint64(0)
 	for {
-		if count > t.config.MaxBlockBacklog {
+		// the safe and finalized heads can move more than the backlog
+		// at once but they are not expected to reorg
+		if count > t.config.MaxBlockBacklog && t.config.Tag == core.Latest {
 			return nil, -1, fmt.Errorf("cannot reconcile more than max backlog values")
 		}
 		count++
@@ -391,3 +391,45 @@ func TestBlockTracker_Events(t *testing.T) {
 		})
 	}
 }
+
+type mockTracker struct {
+	handle func(block *core.Block) error
+}
+
+func (m *mockTracker) Track(ctx context.Context, handle func(block *core.Block) error) error {
+	m.handle = handle
+	return nil
+}
+
+func TestBlockTracker_Finalized(t *testing.T) {
+	l := testutil.MockList{}
+	l.Create(0, 41, func(b *testutil.MockBlock) {})
+
+	m := &testutil.MockClient{}
+	m.AddScenario(l)
+	m.SetBlockTag(core.Finalized, 20)
+
+	mt := &mockTracker{}
+	tt := NewBlockTracker(m, WithTracker(mt), WithBlockTag(core.Finalized))
+	assert.NoError(t, tt.Init())
+	assert.True(t, testutil.CompareBlocks(l.ToBlocks()[11:21], tt.blocks))
+
+	assert.NoError(t, tt.Start())
+	sub := tt.Subscribe()
+
+	// the finalized head moves more than the backlog
+	m.SetBlockTag(core.Finalized, 40)
+
+	latest, err := m.GetBlockByNumber(core.Latest, false)
+	assert.NoError(t, err)
+	assert.NoError(t, mt.handle(latest))
+
+	select {
+	case evnt := <-sub:
+		assert.True(t, testutil.CompareBlocks(l.ToBlocks()[21:41], evnt.Added))
+		assert.Empty(t, evnt.Removed)
+	case <-time.After(1 * time.Second):
+		t.Fatal("block event timeout")
+	}
+	assert.True(t, testutil.CompareBlocks(l.ToBlocks()[31:41], tt.blocks))
+}
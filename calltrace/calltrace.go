@@ -0,0 +1,191 @@
+package calltrace
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/jsonrpc"
+)
+
+// Frame is a call of the call tree decoded with the abis of the registry
+type Frame struct {
+	// Type is the type of the call (i.e. CALL, STATICCALL, CREATE)
+	Type string
+
+	From    core.Address
+	To      core.Address
+	Value   *big.Int
+	Gas     uint64
+	GasUsed uint64
+
+	// Contract is the name of the contract called if it is registered
+	Contract string
+
+	// Method is the method called, nil if it is not known
+	Method *abi.Method
+
+	// Inputs and Outputs are the decoded arguments of the method
+	Inputs  map[string]interface{}
+	Outputs map[string]interface{}
+
+	// Input and Output are the raw calldata and return data
+	Input  []byte
+	Output []byte
+
+	// Error is the error of the call returned by the tracer
+	Error string
+
+	// Revert is the decoded revert data if the call reverted
+	Revert *Revert
+
+	// Events are the logs emitted by the call
+	Events []*Event
+
+	// Calls are the inner calls
+	Calls []*Frame
+}
+
+// IsCreate returns true if the frame deploys a contract
+func (f *Frame) IsCreate() bool {
+	return f.Type == "CREATE" || f.Type == "CREATE2"
+}
+
+// Revert is the decoded revert data of a call
+type Revert struct {
+	// Reason is the reason of a revert(string) or require statement
+	Reason string
+
+	// Panic is the code of a panic error
+	Panic *big.Int
+
+	// CustomError is the custom error in the abis of the registry
+	CustomError *abi.Error
+
+	// Values are the decoded inputs of the custom error
+	Values map[string]interface{}
+}
+
+// Event is a log emitted in a call decoded with the abis of the registry
+type Event struct {
+	Address core.Address
+
+	// Event is the abi event of the log, nil if it is not known
+	Event *abi.Event
+
+	// Values are the decoded inputs of the event
+	Values map[string]interface{}
+
+	Log *core.Log
+}
+
+// Decode decodes the call tree returned by the call tracer. The
+// logs are only included if the tracer is configured with WithLog.
+func (r *Registry) Decode(frame *jsonrpc.CallFrame) *Frame {
+	res := &Frame{
+		Type:    frame.Type,
+		From:    frame.From,
+		To:      frame.To,
+		Value:   frame.Value,
+		Gas:     frame.Gas,
+		GasUsed: frame.GasUsed,
+		Input:   frame.Input,
+		Output:  frame.Output,
+		Error:   frame.Error,
+	}
+	if c, ok := r.Contract(frame.To); ok {
+		res.Contract = c.Name
+	}
+
+	if !res.IsCreate() && len(frame.Input) >= 4 {
+		if method := r.Method(frame.To, frame.Input[:4]); method != nil {
+			if inputs, err := decodeTuple(method.Inputs, frame.Input[4:]); err == nil {
+				res.Method = method
+				res.Inputs = inputs
+			}
+		}
+	}
+
+	if frame.Error != "" {
+		res.Revert = r.decodeRevert(frame.To, frame.Output)
+	} else if res.Method != nil {
+		if outputs, err := decodeTuple(res.Method.Outputs, frame.Output); err == nil {
+			res.Outputs = outputs
+		}
+	}
+
+	for _, log := range frame.Logs {
+		res.Events = append(res.Events, r.decodeLog(log))
+	}
+	for _, call := range frame.Calls {
+		res.Calls = append(res.Calls, r.Decode(call))
+	}
+	return res
+}
+
+// decodeTuple decodes the arguments of a method which might be empty
+func decodeTuple(typ *abi.Type, data []byte) (map[string]interface{}, error) {
+	if len(typ.TupleElems()) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	res, err := abi.Decode(typ, data)
+	if err != nil {
+		return nil, err
+	}
+	return res.(map[string]interface{}), nil
+}
+
+func (r *Registry) decodeRevert(addr core.Address, data []byte) *Revert {
+	res := &Revert{}
+	if reason, err := abi.UnpackRevertError(data); err == nil {
+		res.Reason = reason
+	} else if code, err := abi.UnpackPanicError(data); err == nil {
+		res.Panic = code
+	} else if len(data) >= 4 {
+		if customErr := r.Error(addr, data[:4]); customErr != nil {
+			if vals, err := customErr.Decode(data); err == nil {
+				res.CustomError = customErr
+				res.Values = vals
+			}
+		}
+	}
+	return res
+}
+
+func (r *Registry) decodeLog(callLog *jsonrpc.CallLog) *Event {
+	log := &core.Log{
+		Address: callLog.Address,
+		Topics:  callLog.Topics,
+		Data:    callLog.Data,
+	}
+	res := &Event{
+		Address: log.Address,
+		Log:     log,
+	}
+	if len(log.Topics) == 0 {
+		// anonymous event
+		return res
+	}
+	if event := r.Event(log.Address, log.Topics[0]); event != nil {
+		if vals, err := event.ParseLog(log); err == nil {
+			res.Event = event
+			res.Values = vals
+		}
+	}
+	return res
+}
+
+// String implements the stringer interface
+func (r *Revert) String() string {
+	switch {
+	case r.Reason != "":
+		return r.Reason
+	case r.Panic != nil:
+		return fmt.Sprintf("panic: 0x%x", r.Panic)
+	case r.CustomError != nil:
+		return r.CustomError.Name + formatArgs(r.CustomError.Inputs, r.Values)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,162 @@
+package calltrace
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	tokenABI = abi.MustNewABI(`[
+		{"type": "function", "name": "transfer", "inputs": [{"name": "to", "type": "address"}, {"name": "amount", "type": "uint256"}], "outputs": [{"name": "", "type": "bool"}]},
+		{"type": "event", "name": "Transfer", "inputs": [{"name": "from", "type": "address", "indexed": true}, {"name": "to", "type": "address", "indexed": true}, {"name": "amount", "type": "uint256", "indexed": false}]}
+	]`)
+
+	oracleABI = abi.MustNewABI(`[
+		{"type": "function", "name": "price", "stateMutability": "view", "inputs": [], "outputs": [{"name": "", "type": "uint256"}]},
+		{"type": "error", "name": "Stale", "inputs": [{"name": "age", "type": "uint256"}]}
+	]`)
+)
+
+func encodeWord(t *testing.T, typ string, val interface{}) []byte {
+	res, err := abi.Encode(val, abi.MustNewType(typ))
+	require.NoError(t, err)
+	return res
+}
+
+func TestDecode(t *testing.T) {
+	token := core.Address{0x1}
+	oracle := core.Address{0x2}
+	sender := core.Address{0x3}
+	receiver := core.Address{0x4}
+	unknown := core.Address{0x5}
+
+	transferInput, err := tokenABI.GetMethod("transfer").Encode([]interface{}{receiver, big.NewInt(100)})
+	require.NoError(t, err)
+
+	staleErr := oracleABI.Errors["Stale"]
+	staleData := append(staleErr.ID(), encodeWord(t, "uint256", big.NewInt(10))...)
+
+	transferEvent := tokenABI.Events["Transfer"]
+
+	frame := &jsonrpc.CallFrame{
+		Type:    "CALL",
+		From:    sender,
+		To:      token,
+		GasUsed: 30000,
+		Input:   transferInput,
+		Output:  encodeWord(t, "bool", true),
+		Calls: []*jsonrpc.CallFrame{
+			{
+				Type:    "STATICCALL",
+				From:    token,
+				To:      oracle,
+				GasUsed: 2500,
+				Input:   oracleABI.GetMethod("price").ID(),
+				Output:  staleData,
+				Error:   "execution reverted",
+			},
+			{
+				Type:    "CALL",
+				From:    token,
+				To:      unknown,
+				GasUsed: 100,
+				Input:   append(abi.MustNewMethod("ping(uint256)").ID(), encodeWord(t, "uint256", big.NewInt(1))...),
+				Value:   big.NewInt(5),
+			},
+		},
+		Logs: []*jsonrpc.CallLog{
+			{
+				Address: token,
+				Topics: []core.Hash{
+					transferEvent.ID(),
+					core.BytesToHash(sender[:]),
+					core.BytesToHash(receiver[:]),
+				},
+				Data: encodeWord(t, "uint256", big.NewInt(100)),
+			},
+		},
+	}
+
+	var r *Registry
+
+	lookups := 0
+	lookup := func(selector []byte) (string, error) {
+		lookups++
+
+		// the registry is not locked during the lookup
+		_, ok := r.Contract(unknown)
+		assert.False(t, ok)
+		return "ping(uint256)", nil
+	}
+
+	r = NewRegistry(WithSelectorLookup(lookup))
+	r.Register(token, "Token", tokenABI)
+	r.AddABI(oracleABI)
+
+	res := r.Decode(frame)
+	assert.Equal(t, "transfer", res.Method.Name)
+	assert.Equal(t, receiver, res.Inputs["to"])
+	assert.Equal(t, true, res.Outputs["0"])
+
+	require.Len(t, res.Events, 1)
+	assert.Equal(t, "Transfer", res.Events[0].Event.Name)
+	assert.Equal(t, big.NewInt(100), res.Events[0].Values["amount"])
+
+	// the oracle is not registered but its abi is in the registry
+	price := res.Calls[0]
+	assert.Equal(t, "price", price.Method.Name)
+	assert.Equal(t, "Stale", price.Revert.CustomError.Name)
+	assert.Equal(t, big.NewInt(10), price.Revert.Values["age"])
+
+	// resolved with the lookup
+	assert.Equal(t, "ping", res.Calls[1].Method.Name)
+	assert.Equal(t, 1, lookups)
+
+	expected := `[30000] Token::transfer(to: ` + receiver.String() + `, amount: 100)
+    ├─ [2500] ` + oracle.String() + `::price() [staticcall]
+    │   └─ ← [Revert] Stale(age: 10)
+    ├─ [100] ` + unknown.String() + `::ping{value: 5}(1)
+    │   └─ ← ()
+    ├─ emit Transfer(from: ` + sender.String() + `, to: ` + receiver.String() + `, amount: 100)
+    └─ ← true
+`
+	assert.Equal(t, expected, res.String())
+
+	// the lookup result is cached
+	r.Decode(frame)
+	assert.Equal(t, 1, lookups)
+}
+
+func TestDecode_Unknown(t *testing.T) {
+	frame := &jsonrpc.CallFrame{
+		Type:    "DELEGATECALL",
+		To:      core.Address{0x1},
+		GasUsed: 10,
+		Input:   []byte{0x1, 0x2, 0x3, 0x4, 0x5},
+		Output:  []byte{0x6},
+		Calls: []*jsonrpc.CallFrame{
+			{
+				Type:    "CREATE",
+				To:      core.Address{0x2},
+				GasUsed: 20,
+				Output:  []byte{0x1, 0x2},
+			},
+		},
+	}
+
+	res := NewRegistry().Decode(frame)
+	assert.Nil(t, res.Method)
+
+	expected := `[10] ` + frame.To.String() + `::0x01020304(0x05) [delegatecall]
+    ├─ [20] new ` + frame.Calls[0].To.String() + `
+    │   └─ ← 2 bytes of code
+    └─ ← 0x06
+`
+	assert.Equal(t, expected, res.String())
+}
@@ -0,0 +1,196 @@
+package calltrace
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/core"
+)
+
+// String returns the tree in the same format as Print
+func (f *Frame) String() string {
+	var buf bytes.Buffer
+	f.Print(&buf)
+	return buf.String()
+}
+
+// Print writes the decoded tree in a format similar to the traces of forge:
+//
+//	[30000] Token::transfer(to: 0x..., amount: 100)
+//	    ├─ [2500] Oracle::price() [staticcall]
+//	    │   └─ ← 5
+//	    ├─ emit Transfer(from: 0x..., to: 0x..., amount: 100)
+//	    └─ ← true
+//
+// The inner calls of a frame are printed before its events since the
+// call tracer does not record the order between them.
+func (f *Frame) Print(w io.Writer) {
+	f.print(w, "", "    ")
+}
+
+func (f *Frame) print(w io.Writer, prefix, childPrefix string) {
+	fmt.Fprintf(w, "%s%s\n", prefix, f.header())
+
+	type item func(prefix, childPrefix string)
+
+	items := []item{}
+	for _, call := range f.Calls {
+		call := call
+		items = append(items, func(prefix, childPrefix string) {
+			call.print(w, prefix, childPrefix)
+		})
+	}
+	for _, event := range f.Events {
+		event := event
+		items = append(items, func(prefix, _ string) {
+			fmt.Fprintf(w, "%semit %s\n", prefix, event.format())
+		})
+	}
+	items = append(items, func(prefix, _ string) {
+		fmt.Fprintf(w, "%s← %s\n", prefix, f.result())
+	})
+
+	for indx, item := range items {
+		if indx == len(items)-1 {
+			item(childPrefix+"└─ ", childPrefix+"    ")
+		} else {
+			item(childPrefix+"├─ ", childPrefix+"│   ")
+		}
+	}
+}
+
+func (f *Frame) header() string {
+	name := f.Contract
+	if name == "" {
+		name = f.To.String()
+	}
+
+	var call string
+	switch {
+	case f.IsCreate():
+		call = "new " + name
+	case f.Method != nil:
+		call = name + "::" + f.Method.Name + f.value() + formatArgs(f.Method.Inputs, f.Inputs)
+	case len(f.Input) == 0:
+		call = name + "::receive" + f.value() + "()"
+	case len(f.Input) < 4:
+		call = name + "::fallback" + f.value() + "(0x" + hex.EncodeToString(f.Input) + ")"
+	default:
+		call = fmt.Sprintf("%s::0x%x%s(0x%x)", name, f.Input[:4], f.value(), f.Input[4:])
+	}
+
+	res := fmt.Sprintf("[%d] %s", f.GasUsed, call)
+	if f.Type != "CALL" && !f.IsCreate() {
+		res += " [" + strings.ToLower(f.Type) + "]"
+	}
+	return res
+}
+
+func (f *Frame) value() string {
+	if f.Value == nil || f.Value.Sign() == 0 {
+		return ""
+	}
+	return "{value: " + f.Value.String() + "}"
+}
+
+func (f *Frame) result() string {
+	if f.Error != "" {
+		if reason := f.Revert.String(); reason != "" {
+			return "[Revert] " + reason
+		}
+		return "[" + f.Error + "]"
+	}
+	if f.IsCreate() {
+		return fmt.Sprintf("%d bytes of code", len(f.Output))
+	}
+	if f.Outputs != nil {
+		return formatValues(f.Method.Outputs, f.Outputs)
+	}
+	if len(f.Output) == 0 {
+		return "()"
+	}
+	return "0x" + hex.EncodeToString(f.Output)
+}
+
+func (e *Event) format() string {
+	if e.Event == nil {
+		topics := []string{}
+		for _, topic := range e.Log.Topics {
+			topics = append(topics, topic.String())
+		}
+		return fmt.Sprintf("%s(topics: [%s], data: 0x%x)", e.Address, strings.Join(topics, ", "), e.Log.Data)
+	}
+	return e.Event.Name + formatArgs(e.Event.Inputs, e.Values)
+}
+
+// formatArgs formats the values of the tuple with the names of its elements
+func formatArgs(typ *abi.Type, vals map[string]interface{}) string {
+	args := []string{}
+	for indx, elem := range typ.TupleElems() {
+		val := formatValue(elem.Elem, vals[tupleKey(elem, indx)])
+		if elem.Name != "" {
+			val = elem.Name + ": " + val
+		}
+		args = append(args, val)
+	}
+	return "(" + strings.Join(args, ", ") + ")"
+}
+
+// formatValues formats the values of the tuple without the names
+// of its elements and without parenthesis if there is only one
+func formatValues(typ *abi.Type, vals map[string]interface{}) string {
+	args := []string{}
+	for indx, elem := range typ.TupleElems() {
+		args = append(args, formatValue(elem.Elem, vals[tupleKey(elem, indx)]))
+	}
+	if len(args) == 1 {
+		return args[0]
+	}
+	return "(" + strings.Join(args, ", ") + ")"
+}
+
+func tupleKey(elem *abi.TupleElem, indx int) string {
+	if elem.Name != "" {
+		return elem.Name
+	}
+	return fmt.Sprintf("%d", indx)
+}
+
+func formatValue(typ *abi.Type, val interface{}) string {
+	switch obj := val.(type) {
+	case nil:
+		return "<nil>"
+	case core.Address:
+		return obj.String()
+	case *big.Int:
+		return obj.String()
+	case []byte:
+		return "0x" + hex.EncodeToString(obj)
+	case string:
+		return fmt.Sprintf("%q", obj)
+	case map[string]interface{}:
+		return formatValues(typ, obj)
+	}
+
+	v := reflect.ValueOf(val)
+	switch typ.Kind() {
+	case abi.KindFixedBytes:
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return "0x" + hex.EncodeToString(b)
+
+	case abi.KindSlice, abi.KindArray:
+		elems := []string{}
+		for i := 0; i < v.Len(); i++ {
+			elems = append(elems, formatValue(typ.Elem(), v.Index(i).Interface()))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	}
+	return fmt.Sprintf("%v", val)
+}
@@ -0,0 +1,181 @@
+package calltrace
+
+import (
+	"sync"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/core"
+)
+
+// SelectorLookup returns the text signature of a method selector
+// (i.e. transfer(address,uint256)) or an empty string if it is not
+// known. fourbyte.ResolveBytes can be used as lookup.
+type SelectorLookup func(selector []byte) (string, error)
+
+// Contract is a contract registered in the Registry
+type Contract struct {
+	// Name is the label of the contract in the decoded tree
+	Name string
+
+	// ABI is the abi of the contract
+	ABI *abi.ABI
+}
+
+type Config struct {
+	Lookup SelectorLookup
+}
+
+type Option func(*Config)
+
+// WithSelectorLookup sets the lookup used for the selectors that
+// are not found in any of the abis of the registry
+func WithSelectorLookup(lookup SelectorLookup) Option {
+	return func(c *Config) {
+		c.Lookup = lookup
+	}
+}
+
+// Registry resolves the abi of the contracts in a call tree. The
+// methods, events and errors of the registered abis are also used
+// to decode the frames of contracts that are not registered.
+type Registry struct {
+	config *Config
+
+	lock      sync.Mutex
+	contracts map[core.Address]*Contract
+	methods   map[string]*abi.Method
+	events    map[core.Hash]*abi.Event
+	errors    map[string]*abi.Error
+	lookups   map[string]*abi.Method
+}
+
+// NewRegistry creates a new Registry
+func NewRegistry(opts ...Option) *Registry {
+	config := &Config{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	r := &Registry{
+		config:    config,
+		contracts: map[core.Address]*Contract{},
+		methods:   map[string]*abi.Method{},
+		events:    map[core.Hash]*abi.Event{},
+		errors:    map[string]*abi.Error{},
+		lookups:   map[string]*abi.Method{},
+	}
+	return r
+}
+
+// Register sets the name and the abi of the contract at the address
+func (r *Registry) Register(addr core.Address, name string, abi *abi.ABI) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.contracts[addr] = &Contract{Name: name, ABI: abi}
+	r.addABILocked(abi)
+}
+
+// AddABI adds the methods, events and errors of the abi to the
+// selectors of the registry without binding it to an address
+func (r *Registry) AddABI(abi *abi.ABI) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.addABILocked(abi)
+}
+
+func (r *Registry) addABILocked(abi *abi.ABI) {
+	for _, method := range abi.Methods {
+		r.methods[string(method.ID())] = method
+	}
+	for _, event := range abi.Events {
+		r.events[event.ID()] = event
+	}
+	for _, err := range abi.Errors {
+		r.errors[string(err.ID())] = err
+	}
+}
+
+// Contract returns the contract registered at the address
+func (r *Registry) Contract(addr core.Address) (*Contract, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	c, ok := r.contracts[addr]
+	return c, ok
+}
+
+// Method returns the method of the selector called on the contract. It
+// looks first in the abi of the contract, then in all the abis of the
+// registry and finally with the selector lookup.
+func (r *Registry) Method(addr core.Address, selector []byte) *abi.Method {
+	r.lock.Lock()
+	if c, ok := r.contracts[addr]; ok {
+		for _, method := range c.ABI.Methods {
+			if string(method.ID()) == string(selector) {
+				r.lock.Unlock()
+				return method
+			}
+		}
+	}
+	if method, ok := r.methods[string(selector)]; ok {
+		r.lock.Unlock()
+		return method
+	}
+	if r.config.Lookup == nil {
+		r.lock.Unlock()
+		return nil
+	}
+	if method, ok := r.lookups[string(selector)]; ok {
+		r.lock.Unlock()
+		return method
+	}
+	r.lock.Unlock()
+
+	// the lookup might query the network, do not block
+	// the registry in the meantime
+	sig, err := r.config.Lookup(selector)
+	if err != nil {
+		// try again the next time
+		return nil
+	}
+	var method *abi.Method
+	if sig != "" {
+		method, _ = abi.NewMethod(sig)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// cache the unknown selectors too
+	r.lookups[string(selector)] = method
+	return method
+}
+
+// Event returns the event of the log emitted by the contract
+func (r *Registry) Event(addr core.Address, topic core.Hash) *abi.Event {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if c, ok := r.contracts[addr]; ok {
+		for _, event := range c.ABI.Events {
+			if event.ID() == topic {
+				return event
+			}
+		}
+	}
+	return r.events[topic]
+}
+
+// Error returns the custom error of the revert data of the contract
+func (r *Registry) Error(addr core.Address, id []byte) *abi.Error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if c, ok := r.contracts[addr]; ok {
+		if err := c.ABI.GetErrorByID(id); err != nil {
+			return err
+		}
+	}
+	return r.errors[string(id)]
+}
@@ -50,7 +50,7 @@ func funcName(str string) string {
 func encodeSimpleArg(typ *abi.Type) string {
 	switch typ.Kind() {
 	case abi.KindAddress:
-		return "ethgo.Address"
+		return "core.Address"
 
 	case abi.KindString:
 		return "string"
@@ -142,6 +142,15 @@ func gen(artifacts map[string]*compiler.Artifact, config *config, hash string) e
 		if err != nil {
 			return err
 		}
+		// library placeholders that have to be linked before the deployment
+		refs, err := compiler.LinkReferences(artifact.Bin)
+		if err != nil {
+			return err
+		}
+		libraries := []string{}
+		for _, ref := range refs {
+			libraries = append(libraries, ref.String())
+		}
 		input := map[string]interface{}{
 			"Hash":     hash,
 			"Version":  version.Version,
@@ -150,6 +159,7 @@ func gen(artifacts map[string]*compiler.Artifact, config *config, hash string) e
 			"Contract": artifact,
 			"Abi":      abi,
 			"Name":     name,
+			"Libs":     libraries,
 		}
 
 		filename := strings.ToLower(name)
@@ -182,8 +192,8 @@ import (
 	"fmt"
 	"math/big"
 
-	"github.com/deep-nl/ethgo"
 	"github.com/deep-nl/ethgo/contract"
+	"github.com/deep-nl/ethgo/core"
 	"github.com/deep-nl/ethgo/jsonrpc"
 )
 
@@ -196,25 +206,34 @@ var (
 type {{.Name}} struct {
 	c *contract.Contract
 }
-{{if .Contract.Bin}}
+{{if .Contract.Bin}}{{if .Libs}}
+// Deploy{{.Name}} deploys a new {{.Name}} contract linked with the libraries:
+{{range .Libs}}// - {{.}}
+{{end}}func Deploy{{.Name}}(provider *jsonrpc.Client, from core.Address, libraries map[string]core.Address, args []interface{}, opts ...contract.ContractOption) (contract.Txn, error) {
+	bin, err := {{.Name}}LinkedBin(libraries)
+	if err != nil {
+		return nil, err
+	}
+	return contract.DeployContract(abi{{.Name}}, bin, args, opts...)
+}{{else}}
 // Deploy{{.Name}} deploys a new {{.Name}} contract
-func Deploy{{.Name}}(provider *jsonrpc.Client, from ethgo.Address, args []interface{}, opts ...contract.ContractOption) (contract.Txn, error) {
+func Deploy{{.Name}}(provider *jsonrpc.Client, from core.Address, args []interface{}, opts ...contract.ContractOption) (contract.Txn, error) {
 	return contract.DeployContract(abi{{.Name}}, bin{{.Name}}, args, opts...)
-}
+}{{end}}
 {{end}}
 // New{{.Name}} creates a new instance of the contract at a specific address
-func New{{.Name}}(addr ethgo.Address, opts ...contract.ContractOption) *{{.Name}} {
+func New{{.Name}}(addr core.Address, opts ...contract.ContractOption) *{{.Name}} {
 	return &{{.Name}}{c: contract.NewContract(addr, abi{{.Name}}, opts...)}
 }
 
 // calls
 {{range $key, $value := .Abi.Methods}}{{if .Const}}
 // {{funcName $key}} calls the {{$key}} method in the solidity contract
-func ({{$.Ptr}} *{{$.Name}}) {{funcName $key}}({{range $index, $val := tupleElems .Inputs}}{{if .Name}}{{clean .Name}}{{else}}val{{$index}}{{end}} {{arg .}}, {{end}}block ...ethgo.BlockNumber) ({{range $index, $val := tupleElems .Outputs}}retval{{$index}} {{arg .}}, {{end}}err error) {
+func ({{$.Ptr}} *{{$.Name}}) {{funcName $key}}({{range $index, $val := tupleElems .Inputs}}{{if .Name}}{{clean .Name}}{{else}}val{{$index}}{{end}} {{arg .}}, {{end}}block ...core.BlockNumber) ({{range $index, $val := tupleElems .Outputs}}retval{{$index}} {{arg .}}, {{end}}err error) {
 	var out map[string]interface{}
 	{{ $length := tupleLen .Outputs }}{{ if ne $length 0 }}var ok bool{{ end }}
 
-	out, err = {{$.Ptr}}.c.Call("{{$key}}", ethgo.EncodeBlock(block...){{range $index, $val := tupleElems .Inputs}}, {{if .Name}}{{clean .Name}}{{else}}val{{$index}}{{end}}{{end}})
+	out, err = {{$.Ptr}}.c.Call("{{$key}}", core.EncodeBlock(block...){{range $index, $val := tupleElems .Inputs}}, {{if .Name}}{{clean .Name}}{{else}}val{{$index}}{{end}}{{end}})
 	if err != nil {
 		return
 	}
@@ -231,14 +250,24 @@ func ({{$.Ptr}} *{{$.Name}}) {{funcName $key}}({{range $index, $val := tupleElem
 {{end}}{{end}}
 // txns
 {{range $key, $value := .Abi.Methods}}{{if not .Const}}
-// {{funcName $key}} sends a {{$key}} transaction in the solidity contract
+// {{funcName $key}} sends a {{if .Payable}}payable {{end}}{{$key}} transaction in the solidity contract
 func ({{$.Ptr}} *{{$.Name}}) {{funcName $key}}({{range $index, $input := tupleElems .Inputs}}{{if $index}}, {{end}}{{clean .Name}} {{arg .}}{{end}}) (contract.Txn, error) {
 	return {{$.Ptr}}.c.Txn("{{$key}}"{{range $index, $elem := tupleElems .Inputs}}, {{clean $elem.Name}}{{end}})
 }
-{{end}}{{end}}
+{{end}}{{end}}{{if .Abi.Receive}}
+// Receive sends a transaction with empty calldata to the receive function in the solidity contract
+func ({{$.Ptr}} *{{$.Name}}) Receive() (contract.Txn, error) {
+	return {{$.Ptr}}.c.Txn("receive")
+}
+{{end}}{{if .Abi.Fallback}}
+// Fallback sends a {{if .Abi.Fallback.Payable}}payable {{end}}transaction with raw calldata to the fallback function in the solidity contract
+func ({{$.Ptr}} *{{$.Name}}) Fallback(data []byte) (contract.Txn, error) {
+	return {{$.Ptr}}.c.Txn("fallback", data)
+}
+{{end}}
 // events
 {{range $key, $value := .Abi.Events}}
-func ({{$.Ptr}} *{{$.Name}}) {{funcName $key}}EventSig() ethgo.Hash {
+func ({{$.Ptr}} *{{$.Name}}) {{funcName $key}}EventSig() core.Hash {
 	return {{$.Ptr}}.c.GetABI().Events["{{funcName $key}}"].ID()
 }
 {{end}}`
@@ -249,7 +278,9 @@ import (
 	"encoding/hex"
 	"fmt"
 
-	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/abi"{{if .Libs}}
+	"github.com/deep-nl/ethgo/compiler"
+	"github.com/deep-nl/ethgo/core"{{end}}
 )
 
 var abi{{.Name}} *abi.ABI
@@ -260,7 +291,16 @@ func {{.Name}}Abi() *abi.ABI {
 }
 
 var bin{{.Name}} []byte
-{{if .Contract.Bin}}
+{{if .Libs}}
+// {{.Name}}LinkedBin returns the bin of the {{.Name}} contract linked with the libraries
+func {{.Name}}LinkedBin(libraries map[string]core.Address) ([]byte, error) {
+	bin, err := compiler.Link(bin{{.Name}}Str, libraries)
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(bin[2:])
+}
+{{else if .Contract.Bin}}
 // {{.Name}}Bin returns the bin of the {{.Name}} contract
 func {{.Name}}Bin() []byte {
 	return bin{{.Name}}
@@ -271,13 +311,13 @@ func init() {
 	abi{{.Name}}, err = abi.NewABI(abi{{.Name}}Str)
 	if err != nil {
 		panic(fmt.Errorf("cannot parse {{.Name}} abi: %v", err))
-	}
+	}{{if not .Libs}}
 	if len(bin{{.Name}}Str) != 0 {
 		bin{{.Name}}, err = hex.DecodeString(bin{{.Name}}Str[2:])
 		if err != nil {
 			panic(fmt.Errorf("cannot parse {{.Name}} bin: %v", err))
 		}
-	}
+	}{{end}}
 }
 
 var bin{{.Name}}Str = "{{.Contract.Bin}}"
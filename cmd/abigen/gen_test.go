@@ -0,0 +1,50 @@
+package abigen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestGen_Golden(t *testing.T) {
+	cases := []string{
+		"testdata/testdata.abi",
+		// the bin has the placeholder of a library
+		"testdata/linked.abi",
+	}
+	for _, source := range cases {
+		t.Run(source, func(t *testing.T) {
+			output := t.TempDir()
+			if *update {
+				output = "testdata"
+			}
+			config := &config{
+				Package: "testdata",
+				Output:  output,
+			}
+			artifacts, err := process(source, config)
+			require.NoError(t, err)
+
+			raw := sha256.Sum256([]byte(source))
+			require.NoError(t, gen(artifacts, config, hex.EncodeToString(raw[:])))
+
+			name := strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+			for _, file := range []string{name + ".go", name + "_artifacts.go"} {
+				expected, err := ioutil.ReadFile(filepath.Join("testdata", file))
+				require.NoError(t, err)
+
+				found, err := ioutil.ReadFile(filepath.Join(output, file))
+				require.NoError(t, err)
+				require.Equal(t, string(expected), string(found), "golden file %s is outdated, run the test with -update", file)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// Code generated by ethgo/abigen. DO NOT EDIT.
+// Hash: 9714a0cf3801320ffabffa4e68043cdadc4d359627cb4dd6e6bb0d0e65278dfc
+// Version: 0.1.3
+package testdata
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/deep-nl/ethgo/contract"
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/jsonrpc"
+)
+
+var (
+	_ = big.NewInt
+	_ = jsonrpc.NewClient
+)
+
+// Linked is a solidity contract
+type Linked struct {
+	c *contract.Contract
+}
+
+// DeployLinked deploys a new Linked contract linked with the libraries:
+// - $6ad30996409d058139477db06ae39abaac$
+func DeployLinked(provider *jsonrpc.Client, from core.Address, libraries map[string]core.Address, args []interface{}, opts ...contract.ContractOption) (contract.Txn, error) {
+	bin, err := LinkedLinkedBin(libraries)
+	if err != nil {
+		return nil, err
+	}
+	return contract.DeployContract(abiLinked, bin, args, opts...)
+}
+
+// NewLinked creates a new instance of the contract at a specific address
+func NewLinked(addr core.Address, opts ...contract.ContractOption) *Linked {
+	return &Linked{c: contract.NewContract(addr, abiLinked, opts...)}
+}
+
+// calls
+
+// Sum calls the sum method in the solidity contract
+func (l *Linked) Sum(a *big.Int, b *big.Int, block ...core.BlockNumber) (retval0 *big.Int, err error) {
+	var out map[string]interface{}
+	var ok bool
+
+	out, err = l.c.Call("sum", core.EncodeBlock(block...), a, b)
+	if err != nil {
+		return
+	}
+
+	// decode outputs
+	retval0, ok = out["0"].(*big.Int)
+	if !ok {
+		err = fmt.Errorf("failed to encode output at index 0")
+		return
+	}
+	
+	return
+}
+
+// txns
+
+// events
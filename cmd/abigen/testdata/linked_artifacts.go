@@ -0,0 +1,63 @@
+package testdata
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/compiler"
+	"github.com/deep-nl/ethgo/core"
+)
+
+var abiLinked *abi.ABI
+
+// LinkedAbi returns the abi of the Linked contract
+func LinkedAbi() *abi.ABI {
+	return abiLinked
+}
+
+var binLinked []byte
+
+// LinkedLinkedBin returns the bin of the Linked contract linked with the libraries
+func LinkedLinkedBin(libraries map[string]core.Address) ([]byte, error) {
+	bin, err := compiler.Link(binLinkedStr, libraries)
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(bin[2:])
+}
+
+func init() {
+	var err error
+	abiLinked, err = abi.NewABI(abiLinkedStr)
+	if err != nil {
+		panic(fmt.Errorf("cannot parse Linked abi: %v", err))
+	}
+}
+
+var binLinkedStr = "0x608060405273__$6ad30996409d058139477db06ae39abaac$__6000f3"
+
+var abiLinkedStr = `[
+    {
+        "inputs": [
+            {
+                "name": "a",
+                "type": "uint256"
+            },
+            {
+                "name": "b",
+                "type": "uint256"
+            }
+        ],
+        "name": "sum",
+        "outputs": [
+            {
+                "name": "",
+                "type": "uint256"
+            }
+        ],
+        "stateMutability": "view",
+        "type": "function"
+    }
+]
+`
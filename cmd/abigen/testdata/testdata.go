@@ -1,14 +1,14 @@
 // Code generated by ethgo/abigen. DO NOT EDIT.
-// Hash: 3f1af52b391dcf1991b5cee7468a69f382cfa0f819eaff85474464c969fe7ea9
-// Version: 0.1.1
+// Hash: 14c1d34d4467bb0a5646d94675c61453d22420c43c24f9eaf313e9574b68b624
+// Version: 0.1.3
 package testdata
 
 import (
 	"fmt"
 	"math/big"
 
-	"github.com/deep-nl/ethgo"
 	"github.com/deep-nl/ethgo/contract"
+	"github.com/deep-nl/ethgo/core"
 	"github.com/deep-nl/ethgo/jsonrpc"
 )
 
@@ -23,18 +23,18 @@ type Testdata struct {
 }
 
 // NewTestdata creates a new instance of the contract at a specific address
-func NewTestdata(addr ethgo.Address, opts ...contract.ContractOption) *Testdata {
+func NewTestdata(addr core.Address, opts ...contract.ContractOption) *Testdata {
 	return &Testdata{c: contract.NewContract(addr, abiTestdata, opts...)}
 }
 
 // calls
 
 // CallBasicInput calls the callBasicInput method in the solidity contract
-func (t *Testdata) CallBasicInput(block ...ethgo.BlockNumber) (retval0 *big.Int, retval1 ethgo.Address, err error) {
+func (t *Testdata) CallBasicInput(block ...core.BlockNumber) (retval0 *big.Int, retval1 core.Address, err error) {
 	var out map[string]interface{}
 	var ok bool
 
-	out, err = t.c.Call("callBasicInput", ethgo.EncodeBlock(block...))
+	out, err = t.c.Call("callBasicInput", core.EncodeBlock(block...))
 	if err != nil {
 		return
 	}
@@ -45,7 +45,7 @@ func (t *Testdata) CallBasicInput(block ...ethgo.BlockNumber) (retval0 *big.Int,
 		err = fmt.Errorf("failed to encode output at index 0")
 		return
 	}
-	retval1, ok = out["1"].(ethgo.Address)
+	retval1, ok = out["1"].(core.Address)
 	if !ok {
 		err = fmt.Errorf("failed to encode output at index 1")
 		return
@@ -57,12 +57,12 @@ func (t *Testdata) CallBasicInput(block ...ethgo.BlockNumber) (retval0 *big.Int,
 // txns
 
 // TxnBasicInput sends a txnBasicInput transaction in the solidity contract
-func (t *Testdata) TxnBasicInput(val1 ethgo.Address, val2 *big.Int) (contract.Txn, error) {
+func (t *Testdata) TxnBasicInput(val1 core.Address, val2 *big.Int) (contract.Txn, error) {
 	return t.c.Txn("txnBasicInput", val1, val2)
 }
 
 // events
 
-func (t *Testdata) EventBasicEventSig() ethgo.Hash {
+func (t *Testdata) EventBasicEventSig() core.Hash {
 	return t.c.GetABI().Events["EventBasic"].ID()
 }
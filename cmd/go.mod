@@ -4,6 +4,7 @@ go 1.17
 
 require (
 	github.com/mitchellh/cli v1.1.2
+	github.com/stretchr/testify v1.6.1
 	github.com/umbracle/ethgo v0.0.0-20220303093617-1621d9ff042b
 )
 
@@ -0,0 +1,167 @@
+package compiler
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/deep-nl/ethgo/core"
+)
+
+// placeholderLen is the length in hex characters of a library placeholder,
+// the same as the address it replaces
+const placeholderLen = 40
+
+// LinkReference is a library placeholder in the bytecode of a contract
+type LinkReference struct {
+	// Placeholder is the placeholder as it appears in the bytecode
+	Placeholder string
+
+	// Name is the name of the library for legacy placeholders (__Name___).
+	// It might be truncated by the compiler.
+	Name string
+
+	// Hash is the hash of the fully qualified name of the library for the
+	// hashed placeholders (__$hash$__).
+	Hash string
+
+	// Offsets are the positions of the placeholder in the hex bytecode
+	Offsets []int
+}
+
+// String implements the stringer interface
+func (l *LinkReference) String() string {
+	if l.Name != "" {
+		return l.Name
+	}
+	return "$" + l.Hash + "$"
+}
+
+// LibraryPlaceholder returns the placeholder used since solidity 0.5.0 for
+// a library with the fully qualified name (i.e. path/to/file.sol:Name)
+func LibraryPlaceholder(name string) string {
+	hash := hex.EncodeToString(core.Keccak256([]byte(name))[:17])
+	return "__$" + hash + "$__"
+}
+
+// legacyPlaceholder returns the placeholder used before solidity 0.5.0
+func legacyPlaceholder(name string) string {
+	if len(name) > placeholderLen-4 {
+		name = name[:placeholderLen-4]
+	}
+	name = "__" + name
+	return name + strings.Repeat("_", placeholderLen-len(name))
+}
+
+// LinkReferences returns the library placeholders in the hex bytecode
+func LinkReferences(bin string) ([]*LinkReference, error) {
+	refs := map[string]*LinkReference{}
+
+	for i := 0; i < len(bin); {
+		indx := strings.Index(bin[i:], "__")
+		if indx == -1 {
+			break
+		}
+		pos := i + indx
+		if pos+placeholderLen > len(bin) {
+			return nil, fmt.Errorf("incomplete library placeholder at %d", pos)
+		}
+		placeholder := bin[pos : pos+placeholderLen]
+
+		ref, ok := refs[placeholder]
+		if !ok {
+			ref = &LinkReference{
+				Placeholder: placeholder,
+			}
+			if strings.HasPrefix(placeholder, "__$") && strings.HasSuffix(placeholder, "$__") {
+				ref.Hash = placeholder[3 : placeholderLen-3]
+			} else {
+				ref.Name = strings.TrimRight(placeholder[2:], "_")
+			}
+			refs[placeholder] = ref
+		}
+		ref.Offsets = append(ref.Offsets, pos)
+		i = pos + placeholderLen
+	}
+
+	res := make([]*LinkReference, 0, len(refs))
+	for _, ref := range refs {
+		res = append(res, ref)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Offsets[0] < res[j].Offsets[0]
+	})
+	return res, nil
+}
+
+// matches checks if the library with the given name resolves the reference.
+// Hashed placeholders require the fully qualified name of the library while
+// legacy placeholders can also be resolved with only the name of the library.
+func (l *LinkReference) matches(name string) bool {
+	if l.Hash != "" {
+		return LibraryPlaceholder(name) == l.Placeholder
+	}
+	if legacyPlaceholder(name) == l.Placeholder {
+		return true
+	}
+	if indx := strings.LastIndex(l.Name, ":"); indx != -1 {
+		return l.Name[indx+1:] == name
+	}
+	return false
+}
+
+// Link replaces the library placeholders in the hex bytecode with the addresses
+// of the libraries. The libraries are indexed by the fully qualified name (i.e.
+// path/to/file.sol:Name) or, for legacy placeholders, by the name. It fails if
+// any of the placeholders is not resolved.
+func Link(bin string, libraries map[string]core.Address) (string, error) {
+	refs, err := LinkReferences(bin)
+	if err != nil {
+		return "", err
+	}
+
+	linked := []byte(bin)
+	unresolved := []string{}
+	for _, ref := range refs {
+		addr, ok := resolveLibrary(ref, libraries)
+		if !ok {
+			unresolved = append(unresolved, ref.String())
+			continue
+		}
+		addrStr := hex.EncodeToString(addr[:])
+		for _, offset := range ref.Offsets {
+			copy(linked[offset:], addrStr)
+		}
+	}
+	if len(unresolved) != 0 {
+		return "", fmt.Errorf("unresolved libraries: %s", strings.Join(unresolved, ", "))
+	}
+	return string(linked), nil
+}
+
+func resolveLibrary(ref *LinkReference, libraries map[string]core.Address) (core.Address, bool) {
+	for name, addr := range libraries {
+		if ref.matches(name) {
+			return addr, true
+		}
+	}
+	return core.Address{}, false
+}
+
+// Link returns a copy of the artifact with the library placeholders in
+// the bytecode and the runtime bytecode replaced by the libraries
+func (a *Artifact) Link(libraries map[string]core.Address) (*Artifact, error) {
+	bin, err := Link(a.Bin, libraries)
+	if err != nil {
+		return nil, err
+	}
+	binRuntime, err := Link(a.BinRuntime, libraries)
+	if err != nil {
+		return nil, err
+	}
+	res := *a
+	res.Bin = bin
+	res.BinRuntime = binRuntime
+	return &res, nil
+}
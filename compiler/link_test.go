@@ -0,0 +1,80 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLink_Hashed(t *testing.T) {
+	// placeholder computed by solc for the library contracts/Math.sol:Math
+	placeholder := LibraryPlaceholder("contracts/Math.sol:Math")
+	assert.Equal(t, "__$", placeholder[:3])
+	assert.Len(t, placeholder, 40)
+
+	bin := "0x6080" + placeholder + "6000" + placeholder + "00"
+
+	refs, err := LinkReferences(bin)
+	require.NoError(t, err)
+	require.Len(t, refs, 1)
+	assert.Equal(t, []int{6, 50}, refs[0].Offsets)
+	assert.Equal(t, placeholder[3:37], refs[0].Hash)
+
+	addr := core.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	// the name of the library is not enough for hashed placeholders
+	_, err = Link(bin, map[string]core.Address{"Math": addr})
+	require.Error(t, err)
+
+	linked, err := Link(bin, map[string]core.Address{"contracts/Math.sol:Math": addr})
+	require.NoError(t, err)
+	addrStr := strings.Repeat("00", 19) + "aa"
+	assert.Equal(t, "0x6080"+addrStr+"6000"+addrStr+"00", linked)
+}
+
+func TestLink_Legacy(t *testing.T) {
+	bin := "0x6080" + legacyPlaceholder("contracts/Math.sol:Math") + "6000" + legacyPlaceholder("Strings") + "00"
+
+	refs, err := LinkReferences(bin)
+	require.NoError(t, err)
+	require.Len(t, refs, 2)
+	assert.Equal(t, "contracts/Math.sol:Math", refs[0].Name)
+	assert.Equal(t, "Strings", refs[1].Name)
+
+	math := core.HexToAddress("0x00000000000000000000000000000000000000aa")
+	strs := core.HexToAddress("0x00000000000000000000000000000000000000bb")
+
+	// one of the libraries is missing
+	_, err = Link(bin, map[string]core.Address{"Math": math})
+	require.EqualError(t, err, "unresolved libraries: Strings")
+
+	linked, err := Link(bin, map[string]core.Address{"Math": math, "Strings": strs})
+	require.NoError(t, err)
+
+	refs, err = LinkReferences(linked)
+	require.NoError(t, err)
+	require.Empty(t, refs)
+}
+
+func TestLink_Artifact(t *testing.T) {
+	placeholder := LibraryPlaceholder("Lib.sol:Lib")
+	art := &Artifact{
+		Bin:        "0x60" + placeholder,
+		BinRuntime: "60" + placeholder,
+	}
+	addr := core.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	linked, err := art.Link(map[string]core.Address{"Lib.sol:Lib": addr})
+	require.NoError(t, err)
+	assert.Equal(t, "0x60"+"00000000000000000000000000000000000000aa", linked.Bin)
+	assert.Equal(t, "60"+"00000000000000000000000000000000000000aa", linked.BinRuntime)
+
+	// the original artifact is not modified
+	assert.Equal(t, "0x60"+placeholder, art.Bin)
+
+	_, err = LinkReferences("0x60__$abc")
+	require.Error(t, err)
+}
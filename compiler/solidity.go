@@ -14,9 +14,10 @@ import (
 )
 
 type Output struct {
-	Contracts map[string]*Artifact
-	Sources   map[string]*Source
-	Version   string
+	Contracts  map[string]*Artifact
+	Sources    map[string]*Source
+	SourceList []string `json:"sourceList"`
+	Version    string
 }
 
 type Source struct {
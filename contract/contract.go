@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/deep-nl/ethgo/core"
 	"math/big"
+	"time"
 
 	"github.com/deep-nl/ethgo/abi"
 	"github.com/deep-nl/ethgo/jsonrpc"
@@ -17,14 +18,18 @@ type Provider interface {
 }
 
 type jsonRPCNodeProvider struct {
-	client  *jsonrpc.Eth
-	eip1559 bool
+	client       *jsonrpc.Eth
+	eip1559      bool
+	pollInterval time.Duration
 }
 
 func (j *jsonRPCNodeProvider) Call(addr core.Address, input []byte, opts *CallOpts) ([]byte, error) {
 	msg := &core.CallMsg{
-		To:   &addr,
-		Data: input,
+		Data:  input,
+		Value: opts.Value,
+	}
+	if addr != core.ZeroAddress {
+		msg.To = &addr
 	}
 	if opts.From != core.ZeroAddress {
 		msg.From = opts.From
@@ -60,12 +65,21 @@ type Txn interface {
 	Wait() (*core.Receipt, error)
 }
 
+// RawTxn is implemented by the transactions that are signed locally
+type RawTxn interface {
+	// Raw returns the signed transaction in rlp format once Do is called
+	Raw() []byte
+}
+
 type Opts struct {
 	JsonRPCEndpoint string
 	JsonRPCClient   *jsonrpc.Eth
 	Provider        Provider
 	Sender          core.Key
 	EIP1559         bool
+	LogPollInterval time.Duration
+	Preflight       bool
+	Factory         core.Address
 }
 
 type ContractOption func(*Opts)
@@ -100,6 +114,23 @@ func WithEIP1559() ContractOption {
 	}
 }
 
+// WithLogPollInterval sets the interval to poll for new logs in WatchLogs
+// when the json-rpc transport does not support subscriptions
+func WithLogPollInterval(interval time.Duration) ContractOption {
+	return func(o *Opts) {
+		o.LogPollInterval = interval
+	}
+}
+
+// WithPreflight simulates the transactions with an eth_call at the pending
+// block before sending them. If the simulation reverts the transaction is
+// not sent and a RevertError is returned.
+func WithPreflight() ContractOption {
+	return func(o *Opts) {
+		o.Preflight = true
+	}
+}
+
 func DeployContract(abi *abi.ABI, bin []byte, args []interface{}, opts ...ContractOption) (Txn, error) {
 	a := NewContract(core.Address{}, abi, opts...)
 	a.bin = bin
@@ -109,6 +140,7 @@ func DeployContract(abi *abi.ABI, bin []byte, args []interface{}, opts ...Contra
 func NewContract(addr core.Address, abi *abi.ABI, opts ...ContractOption) *Contract {
 	opt := &Opts{
 		JsonRPCEndpoint: "http://localhost:8545",
+		LogPollInterval: time.Second,
 	}
 	for _, c := range opts {
 		c(opt)
@@ -118,17 +150,18 @@ func NewContract(addr core.Address, abi *abi.ABI, opts ...ContractOption) *Contr
 	if opt.Provider != nil {
 		provider = opt.Provider
 	} else if opt.JsonRPCClient != nil {
-		provider = &jsonRPCNodeProvider{client: opt.JsonRPCClient, eip1559: opt.EIP1559}
+		provider = &jsonRPCNodeProvider{client: opt.JsonRPCClient, eip1559: opt.EIP1559, pollInterval: opt.LogPollInterval}
 	} else {
 		client, _ := jsonrpc.NewClient(opt.JsonRPCEndpoint)
-		provider = &jsonRPCNodeProvider{client: client.Eth(), eip1559: opt.EIP1559}
+		provider = &jsonRPCNodeProvider{client: client.Eth(), eip1559: opt.EIP1559, pollInterval: opt.LogPollInterval}
 	}
 
 	a := &Contract{
-		addr:     addr,
-		abi:      abi,
-		provider: provider,
-		key:      opt.Sender,
+		addr:      addr,
+		abi:       abi,
+		provider:  provider,
+		key:       opt.Sender,
+		preflight: opt.Preflight,
 	}
 
 	return a
@@ -136,68 +169,176 @@ func NewContract(addr core.Address, abi *abi.ABI, opts ...ContractOption) *Contr
 
 // Contract is a wrapper to make abi calls to contract with a state provider
 type Contract struct {
-	addr     core.Address
-	abi      *abi.ABI
-	bin      []byte
-	provider Provider
-	key      core.Key
+	addr      core.Address
+	abi       *abi.ABI
+	bin       []byte
+	provider  Provider
+	key       core.Key
+	preflight bool
 }
 
 func (a *Contract) GetABI() *abi.ABI {
 	return a.abi
 }
 
+// Addr returns the address of the contract
+func (a *Contract) Addr() core.Address {
+	return a.addr
+}
+
+// Provider returns the provider of the contract
+func (a *Contract) Provider() Provider {
+	return a.provider
+}
+
 type TxnOpts struct {
 	Value    *big.Int
 	GasPrice uint64
 	GasLimit uint64
 	Nonce    uint64
+
+	// DryRun builds and signs the transaction in Do but does not send it.
+	// The signed transaction is returned by the RawTxn interface.
+	DryRun bool
 }
 
+// Txn creates a transaction to call the method with the given arguments.
+// The 'constructor' method deploys the contract. The 'receive' method sends
+// a transaction with empty calldata that is routed to the receive function
+// (or the fallback if there is no receive function) and the 'fallback'
+// method sends the raw calldata passed as the only argument.
 func (a *Contract) Txn(method string, args ...interface{}) (Txn, error) {
 	if a.key == nil {
 		return nil, fmt.Errorf("no key selected")
 	}
 
-	isContractDeployment := method == "constructor"
-
 	var input []byte
-	if isContractDeployment {
-		input = append(input, a.bin...)
-	}
-
 	var abiMethod *abi.Method
-	if isContractDeployment {
-		if a.abi.Constructor != nil {
-			abiMethod = a.abi.Constructor
+
+	switch method {
+	case "constructor":
+		abiMethod = a.abi.Constructor
+
+		var err error
+		if input, err = encodeDeployment(a.abi, a.bin, args); err != nil {
+			return nil, err
 		}
-	} else {
+
+	case "receive":
+		if abiMethod = a.abi.Receive; abiMethod == nil {
+			if abiMethod = a.abi.Fallback; abiMethod == nil {
+				return nil, fmt.Errorf("receive or fallback function not found")
+			}
+		}
+
+	case "fallback":
+		if abiMethod = a.abi.Fallback; abiMethod == nil {
+			return nil, fmt.Errorf("fallback function not found")
+		}
+		if len(args) > 1 {
+			return nil, fmt.Errorf("fallback expects only the calldata argument")
+		}
+		if len(args) == 1 {
+			data, ok := args[0].([]byte)
+			if !ok {
+				return nil, fmt.Errorf("fallback calldata must be []byte but found %T", args[0])
+			}
+			input = append(input, data...)
+		}
+
+	default:
 		if abiMethod = a.abi.GetMethod(method); abiMethod == nil {
 			return nil, fmt.Errorf("method %s not found", method)
 		}
-	}
-	if abiMethod != nil {
 		data, err := abi.Encode(args, abiMethod.Inputs)
 		if err != nil {
 			return nil, fmt.Errorf("failed to encode arguments: %v", err)
 		}
-		if isContractDeployment {
-			input = append(input, data...)
-		} else {
-			input = append(abiMethod.ID(), data...)
-		}
+		input = append(abiMethod.ID(), data...)
 	}
 
-	txn, err := a.provider.Txn(a.addr, a.key, input)
+	return a.newMethodTxn(a.addr, input, method, abiMethod, args)
+}
+
+func (a *Contract) newMethodTxn(to core.Address, input []byte, method string, abiMethod *abi.Method, args []interface{}) (Txn, error) {
+	txn, err := a.provider.Txn(to, a.key, input)
 	if err != nil {
 		return nil, err
 	}
-	return txn, nil
+
+	// contracts without an explicit constructor are not payable
+	payable := abiMethod != nil && abiMethod.Payable
+	mTxn := &methodTxn{
+		Txn:      txn,
+		contract: a,
+		to:       to,
+		method:   method,
+		args:     args,
+		input:    input,
+		payable:  payable,
+	}
+	return mTxn, nil
+}
+
+// encodeDeployment returns the bytecode with the encoded constructor arguments
+func encodeDeployment(abi0 *abi.ABI, bin []byte, args []interface{}) ([]byte, error) {
+	input := append([]byte{}, bin...)
+	if abi0.Constructor != nil {
+		data, err := abi.Encode(args, abi0.Constructor.Inputs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode arguments: %v", err)
+		}
+		input = append(input, data...)
+	}
+	return input, nil
+}
+
+// methodTxn is a Txn for a specific contract method that checks if the
+// method accepts value and decodes the revert errors with the contract abi
+type methodTxn struct {
+	Txn
+
+	contract *Contract
+	to       core.Address
+	method   string
+	args     []interface{}
+	input    []byte
+	payable  bool
+	opts     *TxnOpts
+}
+
+func (m *methodTxn) WithOpts(opts *TxnOpts) {
+	m.opts = opts
+	m.Txn.WithOpts(opts)
+}
+
+// Raw implements the RawTxn interface if the inner transaction is signed locally
+func (m *methodTxn) Raw() []byte {
+	if raw, ok := m.Txn.(RawTxn); ok {
+		return raw.Raw()
+	}
+	return nil
+}
+
+func (m *methodTxn) Do() error {
+	if !m.payable && m.opts != nil && m.opts.Value != nil && m.opts.Value.Sign() != 0 {
+		return fmt.Errorf("method %s is not payable", m.method)
+	}
+	if m.contract.preflight {
+		if err := m.preflight(); err != nil {
+			return m.decodeRevert(err)
+		}
+	}
+	if err := m.Txn.Do(); err != nil {
+		return m.decodeRevert(err)
+	}
+	return nil
 }
 
 type CallOpts struct {
 	Block core.BlockNumber
 	From  core.Address
+	Value *big.Int
 }
 
 func (a *Contract) Call(method string, block core.BlockNumber, args ...interface{}) (map[string]interface{}, error) {
@@ -2,14 +2,18 @@ package contract
 
 import (
 	"encoding/hex"
+	"fmt"
 	"github.com/deep-nl/ethgo/core"
 	"math/big"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/deep-nl/ethgo/abi"
 	"github.com/deep-nl/ethgo/jsonrpc"
+	"github.com/deep-nl/ethgo/jsonrpc/codec"
 	"github.com/deep-nl/ethgo/testutil"
+	"github.com/deep-nl/ethgo/testutil/simulated"
 	"github.com/deep-nl/ethgo/wallet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -21,7 +25,7 @@ var (
 )
 
 func TestContract_NoInput(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 	//http.ResponseWriter()
 	//http.Request{}
 	//http.Handle()
@@ -53,7 +57,7 @@ func TestContract_NoInput(t *testing.T) {
 }
 
 func TestContract_IO(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	cc := &testutil.Contract{}
 	cc.AddDualCaller("setA", "address", "uint256")
@@ -74,7 +78,7 @@ func TestContract_IO(t *testing.T) {
 }
 
 func TestContract_From(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	cc := &testutil.Contract{}
 	cc.AddCallback(func() string {
@@ -98,7 +102,7 @@ func TestContract_From(t *testing.T) {
 }
 
 func TestContract_Deploy(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	// create an address and fund it
 	key, _ := wallet.GenerateKey()
@@ -110,7 +114,7 @@ func TestContract_Deploy(t *testing.T) {
 	cc.AddConstructor("address", "uint256")
 
 	artifact, err := cc.Compile()
-	assert.NoError(t, err)
+	require.NoError(t, err)
 
 	abi, err := abi.NewABI(artifact.Abi)
 	assert.NoError(t, err)
@@ -136,7 +140,7 @@ func TestContract_Deploy(t *testing.T) {
 }
 
 func TestContract_Transaction(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	// create an address and fund it
 	key, _ := wallet.GenerateKey()
@@ -170,7 +174,7 @@ func TestContract_Transaction(t *testing.T) {
 }
 
 func TestContract_CallAtBlock(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	// create an address and fund it
 	key, _ := wallet.GenerateKey()
@@ -229,7 +233,7 @@ func TestContract_CallAtBlock(t *testing.T) {
 }
 
 func TestContract_SendValueContractCall(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	key, _ := wallet.GenerateKey()
 	s.Fund(key.Address())
@@ -268,7 +272,7 @@ func TestContract_SendValueContractCall(t *testing.T) {
 }
 
 func TestContract_EIP1559(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	key, _ := wallet.GenerateKey()
 	s.Fund(key.Address())
@@ -304,20 +308,15 @@ func TestContract_EIP1559(t *testing.T) {
 	assert.NotZero(t, txnObj.MaxPriorityFeePerGas)
 }
 
-// --------------------------------------------------------------------------------------------------------------------
-// -------------------------------------------TestnetTest----------------------------------------------------------------
-// --------------------------------------------------------------------------------------------------------------------
-
 func TestContract_Basic(t *testing.T) {
-	server := testutil.NewServer()
-	keyFrom := os.Getenv("TEST2KEY")
-	//t.Log(http, ws)
-	//s := testutil.NewTestingServer(t, http, ws)
+	s, _ := simulated.NewTestServer(t)
 
-	client, _ := jsonrpc.NewClient(server.HTTPAddr())
+	client, _ := jsonrpc.NewClient(s.HTTPAddr())
+
+	key, _ := wallet.GenerateKey()
+	_, err := s.Fund(key.Address())
+	require.NoError(t, err)
 
-	//key, _ := wallet.GenerateKey()
-	key := wallet.KeyFromString(keyFrom)
 	account, err := client.Eth().GetBalance(key.Address(), core.Latest)
 	assert.NoError(t, err)
 	t.Log(core.ToFloatEther(account))
@@ -325,12 +324,581 @@ func TestContract_Basic(t *testing.T) {
 	abi, err := abi.NewABIFromFile("../asset/uniswap-v2/pair.abi")
 	assert.NoError(t, err)
 
-	addr := core.HexToAddress("0x186b57aFFE222D6176347D338Ed66Ea2e20D630d") // dai_weth pair
-	//contract := NewContract(addr, abi, WithJsonRPC(client.Eth()), WithSender(key), WithEIP1559())
-	contract := NewContract(addr, abi, WithJsonRPC(client.Eth()))
+	// contract that returns the reserves (1, 2, 3) on any call
+	code := []byte{
+		0x60, 0x14, 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, 0x14, 0x60, 0x00, 0xf3,
+		0x60, 0x01, 0x60, 0x00, 0x52, 0x60, 0x02, 0x60, 0x20, 0x52,
+		0x60, 0x03, 0x60, 0x40, 0x52, 0x60, 0x60, 0x60, 0x00, 0xf3,
+	}
+	receipt, err := s.SendTxn(&core.Transaction{
+		Input: code,
+	})
+	require.NoError(t, err)
+
+	contract := NewContract(receipt.ContractAddress, abi, WithJsonRPC(client.Eth()), WithSender(key))
 
 	resp, err := contract.Call("getReserves", core.Latest)
 	assert.NoError(t, err)
-	t.Log(resp)
+	assert.Equal(t, big.NewInt(1), resp["_reserve0"])
+	assert.Equal(t, big.NewInt(2), resp["_reserve1"])
+	assert.Equal(t, uint32(3), resp["_blockTimestampLast"])
+}
+
+type mockProvider struct {
+	input   []byte
+	callErr error
+}
+
+func (m *mockProvider) Call(addr core.Address, input []byte, opts *CallOpts) ([]byte, error) {
+	m.input = input
+	return nil, m.callErr
+}
+
+func (m *mockProvider) Txn(addr core.Address, key core.Key, input []byte) (Txn, error) {
+	m.input = input
+	return &mockTxn{}, nil
+}
+
+type mockTxn struct {
+	done bool
+}
+
+func (m *mockTxn) Hash() core.Hash {
+	return core.Hash{}
+}
+
+func (m *mockTxn) WithOpts(opts *TxnOpts) {
+}
+
+func (m *mockTxn) Do() error {
+	m.done = true
+	return nil
+}
+
+func (m *mockTxn) Wait() (*core.Receipt, error) {
+	return &core.Receipt{}, nil
+}
+
+func TestContract_Payable(t *testing.T) {
+	key, _ := wallet.GenerateKey()
+
+	abi0, err := abi.NewABIFromList([]string{
+		"function deposit() payable",
+		"function withdraw()",
+		"receive() external payable",
+		"fallback() external",
+	})
+	require.NoError(t, err)
+
+	provider := &mockProvider{}
+	c := NewContract(core.Address{0x1}, abi0, WithProvider(provider), WithSender(key))
+
+	send := func(method string, value int64, args ...interface{}) error {
+		txn, err := c.Txn(method, args...)
+		require.NoError(t, err)
+
+		txn.WithOpts(&TxnOpts{Value: big.NewInt(value)})
+		return txn.Do()
+	}
+
+	require.NoError(t, send("deposit", 1))
+	require.NoError(t, send("withdraw", 0))
+	require.Error(t, send("withdraw", 1))
+
+	// empty calldata is routed to the receive function
+	require.NoError(t, send("receive", 1))
+	require.Empty(t, provider.input)
+
+	// the fallback function is not payable
+	require.Error(t, send("fallback", 1, []byte{0x1, 0x2}))
+	require.NoError(t, send("fallback", 0, []byte{0x1, 0x2}))
+	require.Equal(t, []byte{0x1, 0x2}, provider.input)
+
+	// without receive function the empty calldata goes to the fallback
+	abi1, err := abi.NewABIFromList([]string{
+		"fallback() external payable",
+	})
+	require.NoError(t, err)
+
+	c = NewContract(core.Address{0x1}, abi1, WithProvider(provider), WithSender(key))
+	require.NoError(t, send("receive", 1))
+
+	// without receive nor fallback the contract cannot receive value
+	c = NewContract(core.Address{0x1}, &abi.ABI{}, WithProvider(provider), WithSender(key))
+	_, err = c.Txn("receive")
+	require.Error(t, err)
+}
 
+type mockEventProvider struct {
+	mockProvider
+
+	filter   *core.LogFilter
+	logs     []*core.Log
+	callback func(log *core.Log)
+}
+
+func (m *mockEventProvider) GetLogs(filter *core.LogFilter) ([]*core.Log, error) {
+	m.filter = filter
+	return m.logs, nil
+}
+
+func (m *mockEventProvider) WatchLogs(filter *core.LogFilter, callback func(log *core.Log)) (func() error, error) {
+	m.filter = filter
+	m.callback = callback
+	return func() error { return nil }, nil
+}
+
+func TestContract_Events(t *testing.T) {
+	abi0, err := abi.NewABIFromList([]string{
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+	})
+	require.NoError(t, err)
+
+	event := abi0.Events["Transfer"]
+	from, to := core.Address{0x1}, core.Address{0x2}
+
+	fromTopic, err := abi.EncodeTopic(abi.MustNewType("address"), from)
+	require.NoError(t, err)
+	toTopic, err := abi.EncodeTopic(abi.MustNewType("address"), to)
+	require.NoError(t, err)
+	data, err := abi.MustNewType("uint256").Encode(big.NewInt(10))
+	require.NoError(t, err)
+
+	log := &core.Log{
+		Address: core.Address{0x10},
+		Topics:  []core.Hash{event.ID(), fromTopic, toTopic},
+		Data:    data,
+	}
+
+	// erc721 transfer with the value indexed does not decode with the abi
+	erc721Log := &core.Log{
+		Address: core.Address{0x10},
+		Topics:  []core.Hash{event.ID(), fromTopic, toTopic, {0x1}},
+	}
+
+	provider := &mockEventProvider{logs: []*core.Log{erc721Log, log}}
+	c := NewContract(core.Address{0x10}, abi0, WithProvider(provider))
+
+	// filter by the 'to' indexed argument only
+	events, err := c.FilterLogs("Transfer", core.BlockNumber(1), core.Latest, nil, []interface{}{to, from})
+	require.NoError(t, err)
+
+	require.Equal(t, []core.Address{{0x10}}, provider.filter.Address)
+	require.Len(t, provider.filter.Topics, 3)
+	require.Equal(t, event.ID(), *provider.filter.Topics[0][0])
+	require.Nil(t, provider.filter.Topics[1])
+	require.Equal(t, []*core.Hash{&toTopic, &fromTopic}, provider.filter.Topics[2])
+	require.Equal(t, core.BlockNumber(1), *provider.filter.From)
+	require.Equal(t, core.Latest, *provider.filter.To)
+
+	require.Len(t, events, 1)
+	require.Equal(t, "Transfer", events[0].Name)
+	require.Equal(t, from, events[0].Values["from"])
+	require.Equal(t, to, events[0].Values["to"])
+	require.Equal(t, big.NewInt(10), events[0].Values["value"])
+
+	// too many indexed arguments
+	_, err = c.FilterLogs("Transfer", core.Earliest, core.Latest, from, to, 1)
+	require.Error(t, err)
+
+	// unknown event
+	_, err = c.FilterLogs("Approval", core.Earliest, core.Latest)
+	require.Error(t, err)
+
+	// watch logs
+	ch := make(chan *Event, 2)
+	_, err = c.WatchLogs("Transfer", ch, from)
+	require.NoError(t, err)
+	require.Nil(t, provider.filter.From)
+	require.Equal(t, []*core.Hash{&fromTopic}, provider.filter.Topics[1])
+
+	provider.callback(log)
+	removed := log.Copy()
+	removed.Removed = true
+	provider.callback(removed)
+
+	evnt := <-ch
+	require.False(t, evnt.Removed)
+	require.Equal(t, big.NewInt(10), evnt.Values["value"])
+
+	evnt = <-ch
+	require.True(t, evnt.Removed)
+
+	// the callback does not block once the watch is stopped
+	closeFn, err := c.WatchLogs("Transfer", make(chan *Event))
+	require.NoError(t, err)
+	require.NoError(t, closeFn())
+
+	doneCh := make(chan struct{})
+	go func() {
+		provider.callback(log)
+		close(doneCh)
+	}()
+	select {
+	case <-doneCh:
+	case <-time.After(time.Second):
+		t.Fatal("callback blocked after the watch was stopped")
+	}
+
+	// the provider does not support logs
+	c = NewContract(core.Address{0x10}, abi0, WithProvider(&mockProvider{}))
+	_, err = c.FilterLogs("Transfer", core.Earliest, core.Latest)
+	require.Error(t, err)
+}
+
+func TestContract_Preflight(t *testing.T) {
+	key, _ := wallet.GenerateKey()
+
+	abi0, err := abi.NewABIFromList([]string{
+		"function withdraw(uint256 amount)",
+		"error InsufficientBalance(uint256 available, uint256 required)",
+	})
+	require.NoError(t, err)
+
+	customErr := abi0.Errors["InsufficientBalance"]
+	data, err := customErr.Inputs.Encode([]interface{}{1, 2})
+	require.NoError(t, err)
+	data = append(customErr.ID(), data...)
+
+	provider := &mockProvider{
+		callErr: &codec.ErrorObject{
+			Code:    3,
+			Message: "execution reverted",
+			Data:    "0x" + hex.EncodeToString(data),
+		},
+	}
+
+	// without preflight the transaction is sent
+	c := NewContract(core.Address{0x1}, abi0, WithProvider(provider), WithSender(key))
+	txn, err := c.Txn("withdraw", 2)
+	require.NoError(t, err)
+	require.NoError(t, txn.Do())
+	require.True(t, txn.(*methodTxn).Txn.(*mockTxn).done)
+
+	// with preflight the revert is decoded with the abi
+	c = NewContract(core.Address{0x1}, abi0, WithProvider(provider), WithSender(key), WithPreflight())
+	txn, err = c.Txn("withdraw", 2)
+	require.NoError(t, err)
+
+	err = txn.Do()
+	require.Error(t, err)
+	require.False(t, txn.(*methodTxn).Txn.(*mockTxn).done)
+
+	revertErr, ok := err.(*RevertError)
+	require.True(t, ok)
+	require.Equal(t, "withdraw", revertErr.Method)
+	require.Equal(t, []interface{}{2}, revertErr.Args)
+	require.Equal(t, customErr, revertErr.CustomError)
+	require.Equal(t, big.NewInt(1), revertErr.Values["available"])
+	require.Equal(t, big.NewInt(2), revertErr.Values["required"])
+
+	// revert reason
+	reason, err := abi.MustNewType("tuple(string)").Encode([]interface{}{"not enough"})
+	require.NoError(t, err)
+	provider.callErr = &codec.ErrorObject{
+		Message: "execution reverted: not enough",
+		Data:    "0x08c379a0" + hex.EncodeToString(reason),
+	}
+	err = txn.Do()
+	require.Error(t, err)
+	require.Equal(t, "not enough", err.(*RevertError).Reason)
+
+	// other errors are returned as they are
+	provider.callErr = fmt.Errorf("connection refused")
+	require.Equal(t, provider.callErr, txn.Do())
+}
+
+// dryRunProvider returns transactions signed locally with the fee values set
+type dryRunProvider struct {
+	mockProvider
+}
+
+func (d *dryRunProvider) Txn(addr core.Address, key core.Key, input []byte) (Txn, error) {
+	return &jsonrpcTransaction{
+		key:   key,
+		to:    addr,
+		input: input,
+		txn: &core.Transaction{
+			To:       &addr,
+			Input:    input,
+			GasPrice: 1,
+			Gas:      21000,
+			Nonce:    1,
+			ChainID:  big.NewInt(1),
+		},
+	}, nil
+}
+
+func TestContract_DryRun(t *testing.T) {
+	key, _ := wallet.GenerateKey()
+
+	abi0, err := abi.NewABIFromList([]string{
+		"function withdraw(uint256 amount)",
+	})
+	require.NoError(t, err)
+
+	c := NewContract(core.Address{0x1}, abi0, WithProvider(&dryRunProvider{}), WithSender(key))
+
+	txn, err := c.Txn("withdraw", 1)
+	require.NoError(t, err)
+	txn.WithOpts(&TxnOpts{DryRun: true})
+
+	require.NoError(t, txn.Do())
+
+	raw, ok := txn.(RawTxn)
+	require.True(t, ok)
+	require.NotEmpty(t, raw.Raw())
+	require.NotEqual(t, core.Hash{}, txn.Hash())
+
+	_, err = txn.Wait()
+	require.Error(t, err)
+
+	// the raw transaction is signed by the key
+	signed := &core.Transaction{}
+	require.NoError(t, signed.UnmarshalRLP(raw.Raw()))
+	require.Equal(t, uint64(1), signed.Nonce)
+	require.Equal(t, abi0.GetMethod("withdraw").ID(), signed.Input[:4])
+	require.NotEmpty(t, signed.R)
+
+	// the transactions that are not signed locally have no raw transaction
+	c = NewContract(core.Address{0x1}, abi0, WithProvider(&mockProvider{}), WithSender(key))
+
+	txn, err = c.Txn("withdraw", 1)
+	require.NoError(t, err)
+	require.Nil(t, txn.(RawTxn).Raw())
+}
+
+type mockCodeProvider struct {
+	mockProvider
+
+	to   core.Address
+	code map[core.Address][]byte
+}
+
+func (m *mockCodeProvider) Txn(addr core.Address, key core.Key, input []byte) (Txn, error) {
+	m.to = addr
+	return m.mockProvider.Txn(addr, key, input)
+}
+
+func (m *mockCodeProvider) GetCode(addr core.Address, block core.BlockNumber) ([]byte, error) {
+	return m.code[addr], nil
+}
+
+func TestContract_DeployDeterministic(t *testing.T) {
+	key, _ := wallet.GenerateKey()
+
+	abi0, err := abi.NewABIFromList([]string{
+		"constructor(uint256 a)",
+	})
+	require.NoError(t, err)
+
+	bin := []byte{0x60, 0x80}
+	salt := core.Hash{0x1}
+
+	initCode := append([]byte{0x60, 0x80}, make([]byte, 32)...)
+	initCode[len(initCode)-1] = 0x5
+
+	provider := &mockCodeProvider{code: map[core.Address][]byte{}}
+
+	// the factory is not deployed
+	_, _, err = DeployDeterministic(abi0, bin, salt, []interface{}{5}, WithProvider(provider), WithSender(key))
+	require.Error(t, err)
+
+	provider.code[DeterministicDeployer] = []byte{0x1}
+
+	c, txn, err := DeployDeterministic(abi0, bin, salt, []interface{}{5}, WithProvider(provider), WithSender(key))
+	require.NoError(t, err)
+	require.NotNil(t, txn)
+
+	expected := core.CreateAddress2(DeterministicDeployer, salt, initCode)
+	require.Equal(t, expected, c.Addr())
+	require.Equal(t, DeterministicDeployer, provider.to)
+	require.Equal(t, append(salt[:], initCode...), provider.input)
+
+	// custom factory
+	factory := core.Address{0x2}
+	provider.code[factory] = []byte{0x1}
+	c, _, err = DeployDeterministic(abi0, bin, salt, []interface{}{5}, WithProvider(provider), WithSender(key), WithFactory(factory))
+	require.NoError(t, err)
+	require.Equal(t, core.CreateAddress2(factory, salt, initCode), c.Addr())
+	require.Equal(t, factory, provider.to)
+
+	// the contract is already deployed
+	provider.code[expected] = []byte{0x1}
+	c, txn, err = DeployDeterministic(abi0, bin, salt, []interface{}{5}, WithProvider(provider))
+	require.NoError(t, err)
+	require.Nil(t, txn)
+	require.Equal(t, expected, c.Addr())
+}
+
+type mockProxyProvider struct {
+	mockCodeProvider
+
+	storage map[core.Address]map[core.Hash]core.Hash
+	calls   map[core.Address]map[string][]byte
+}
+
+func (m *mockProxyProvider) GetStorageAt(addr core.Address, slot core.Hash, block core.BlockNumber) (core.Hash, error) {
+	return m.storage[addr][slot], nil
+}
+
+func (m *mockProxyProvider) Call(addr core.Address, input []byte, opts *CallOpts) ([]byte, error) {
+	res, ok := m.calls[addr][string(input)]
+	if !ok {
+		return nil, fmt.Errorf("execution reverted")
+	}
+	return res, nil
+}
+
+type mockABISource map[core.Address]*abi.ABI
+
+func (m mockABISource) GetABI(addr core.Address) (*abi.ABI, error) {
+	res, ok := m[addr]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return res, nil
+}
+
+func TestContract_DetectProxy(t *testing.T) {
+	proxy := core.Address{0x1}
+	impl := core.Address{0x2}
+	beacon := core.Address{0x3}
+
+	addrWord := func(addr core.Address) core.Hash {
+		var h core.Hash
+		copy(h[12:], addr[:])
+		return h
+	}
+
+	newProvider := func() *mockProxyProvider {
+		return &mockProxyProvider{
+			mockCodeProvider: mockCodeProvider{
+				code: map[core.Address][]byte{proxy: {0x1}},
+			},
+			storage: map[core.Address]map[core.Hash]core.Hash{proxy: {}},
+			calls:   map[core.Address]map[string][]byte{},
+		}
+	}
+
+	// the runtime code of the gnosis safe proxy compares the
+	// selector with masterCopy before the delegatecall
+	setupGnosisSafe := func(m *mockProxyProvider) {
+		m.code[proxy] = append(append([]byte{0x7f}, masterCopySig...), make([]byte, 28)...)
+		m.storage[proxy][core.Hash{}] = addrWord(impl)
+
+		word := addrWord(impl)
+		m.calls[proxy] = map[string][]byte{string(masterCopySig): word[:]}
+	}
+
+	cases := []struct {
+		typ   ProxyType
+		setup func(m *mockProxyProvider)
+	}{
+		{
+			ProxyEIP1167,
+			func(m *mockProxyProvider) {
+				code := append(append(append([]byte{}, eip1167Prefix...), impl[:]...), eip1167Suffix...)
+				m.code[proxy] = code
+			},
+		},
+		{
+			ProxyEIP1967,
+			func(m *mockProxyProvider) {
+				m.storage[proxy][eip1967ImplementationSlot] = addrWord(impl)
+			},
+		},
+		{
+			ProxyBeacon,
+			func(m *mockProxyProvider) {
+				m.storage[proxy][eip1967BeaconSlot] = addrWord(beacon)
+				word := addrWord(impl)
+				m.calls[beacon] = map[string][]byte{string(beaconImplementationSig): word[:]}
+			},
+		},
+		{
+			ProxyEIP1822,
+			func(m *mockProxyProvider) {
+				m.storage[proxy][eip1822Slot] = addrWord(impl)
+			},
+		},
+		{
+			ProxyGnosisSafe,
+			func(m *mockProxyProvider) {
+				setupGnosisSafe(m)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.typ), func(t *testing.T) {
+			provider := newProvider()
+			c.setup(provider)
+
+			res, err := NewContract(proxy, nil, WithProvider(provider)).DetectProxy(core.Latest)
+			require.NoError(t, err)
+			require.Equal(t, c.typ, res.Type)
+			require.Equal(t, impl, res.Implementation)
+
+			if c.typ == ProxyBeacon {
+				require.Equal(t, beacon, res.Beacon)
+			}
+		})
+	}
+
+	// not a proxy
+	_, err := NewContract(proxy, nil, WithProvider(newProvider())).DetectProxy(core.Latest)
+	require.Equal(t, ErrNotProxy, err)
+
+	// a contract that returns a word for any call is not a gnosis safe
+	provider := newProvider()
+	word := addrWord(impl)
+	provider.calls[proxy] = map[string][]byte{string(masterCopySig): word[:]}
+
+	_, err = NewContract(proxy, nil, WithProvider(provider)).DetectProxy(core.Latest)
+	require.Equal(t, ErrNotProxy, err)
+
+	// the singleton in the storage does not match the masterCopy call
+	provider = newProvider()
+	setupGnosisSafe(provider)
+	provider.storage[proxy][core.Hash{}] = addrWord(beacon)
+
+	_, err = NewContract(proxy, nil, WithProvider(provider)).DetectProxy(core.Latest)
+	require.Equal(t, ErrNotProxy, err)
+
+	// the runtime code does not handle masterCopy
+	provider = newProvider()
+	setupGnosisSafe(provider)
+	provider.code[proxy] = []byte{0x1}
+
+	_, err = NewContract(proxy, nil, WithProvider(provider)).DetectProxy(core.Latest)
+	require.Equal(t, ErrNotProxy, err)
+
+	// resolve the abi of the implementation
+	implABI := abi.MustNewABI(`[{"type": "function", "name": "foo", "inputs": [], "outputs": []}]`)
+
+	provider = newProvider()
+	provider.storage[proxy][eip1967ImplementationSlot] = addrWord(impl)
+
+	c, res, err := NewProxyContract(proxy, mockABISource{impl: implABI}, WithProvider(provider))
+	require.NoError(t, err)
+	require.Equal(t, impl, res.Implementation)
+	require.Equal(t, proxy, c.Addr())
+	require.NotNil(t, c.GetABI().GetMethod("foo"))
+}
+
+func TestContract_LocalABISource(t *testing.T) {
+	dir := t.TempDir()
+	addr := core.Address{0x1}
+
+	source := NewLocalABISource(dir)
+	_, err := source.GetABI(addr)
+	require.Error(t, err)
+
+	artifact := `{"abi": [{"type": "function", "name": "foo", "inputs": [], "outputs": []}]}`
+	require.NoError(t, os.WriteFile(dir+"/"+addr.String()+".json", []byte(artifact), 0644))
+
+	res, err := source.GetABI(addr)
+	require.NoError(t, err)
+	require.NotNil(t, res.GetMethod("foo"))
 }
@@ -0,0 +1,89 @@
+package contract
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/core"
+)
+
+// DeterministicDeployer is the address of the canonical deterministic deployment
+// proxy (https://github.com/Arachnid/deterministic-deployment-proxy). It deploys
+// the init code in the calldata after the 32 bytes salt with CREATE2.
+var DeterministicDeployer = core.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+
+// CodeProvider is a Provider that can query the code of an account
+type CodeProvider interface {
+	GetCode(addr core.Address, block core.BlockNumber) ([]byte, error)
+}
+
+func (j *jsonRPCNodeProvider) GetCode(addr core.Address, block core.BlockNumber) ([]byte, error) {
+	code, err := j.client.GetCode(addr, block)
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(strings.TrimPrefix(code, "0x"))
+}
+
+// WithFactory sets the CREATE2 factory used by DeployDeterministic. The factory
+// must follow the calldata format of the DeterministicDeployer proxy.
+func WithFactory(factory core.Address) ContractOption {
+	return func(o *Opts) {
+		o.Factory = factory
+	}
+}
+
+// DeployDeterministic deploys the contract with CREATE2 through the deterministic
+// deployment factory and returns the contract bound to the predicted address. If
+// there is already code at that address the contract is not deployed again and the
+// returned transaction is nil. It fails if the factory is not deployed in the chain.
+func DeployDeterministic(abi *abi.ABI, bin []byte, salt core.Hash, args []interface{}, opts ...ContractOption) (*Contract, Txn, error) {
+	opt := &Opts{
+		Factory: DeterministicDeployer,
+	}
+	for _, c := range opts {
+		c(opt)
+	}
+
+	initCode, err := encodeDeployment(abi, bin, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	addr := core.CreateAddress2(opt.Factory, salt, initCode)
+
+	a := NewContract(addr, abi, opts...)
+
+	provider, ok := a.provider.(CodeProvider)
+	if !ok {
+		return nil, nil, fmt.Errorf("provider does not support querying the code")
+	}
+	code, err := provider.GetCode(addr, core.Latest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(code) != 0 {
+		// already deployed
+		return a, nil, nil
+	}
+	if a.key == nil {
+		return nil, nil, fmt.Errorf("no key selected")
+	}
+
+	// a transaction to a factory without code succeeds without deploying anything
+	factoryCode, err := provider.GetCode(opt.Factory, core.Latest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(factoryCode) == 0 {
+		return nil, nil, fmt.Errorf("factory %s has no code", opt.Factory)
+	}
+
+	input := append(append([]byte{}, salt[:]...), initCode...)
+	txn, err := a.newMethodTxn(opt.Factory, input, "constructor", abi.Constructor, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, txn, nil
+}
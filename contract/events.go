@@ -0,0 +1,260 @@
+package contract
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/jsonrpc"
+)
+
+// EventProvider is a Provider that can query and watch the logs of the chain
+type EventProvider interface {
+	// GetLogs returns the logs that match the filter
+	GetLogs(filter *core.LogFilter) ([]*core.Log, error)
+
+	// WatchLogs notifies the new logs that match the filter
+	WatchLogs(filter *core.LogFilter, callback func(log *core.Log)) (func() error, error)
+}
+
+// Event is a log emitted by the contract decoded with the abi event
+type Event struct {
+	Name    string
+	Values  map[string]interface{}
+	Removed bool
+	Log     *core.Log
+}
+
+// FilterLogs returns the decoded events emitted by the contract between the from
+// and to blocks. The indexed arguments filter the event by the value of its indexed
+// inputs in order. A nil argument matches any value and a []interface{} argument
+// matches any of its values. The logs that match the signature of the event
+// but cannot be decoded with the abi are skipped.
+func (a *Contract) FilterLogs(event string, from, to core.BlockNumber, indexedArgs ...interface{}) ([]*Event, error) {
+	provider, ok := a.provider.(EventProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider does not support logs")
+	}
+	abiEvent, filter, err := a.buildLogFilter(event, indexedArgs)
+	if err != nil {
+		return nil, err
+	}
+	filter.From = &from
+	filter.To = &to
+
+	logs, err := provider.GetLogs(filter)
+	if err != nil {
+		return nil, err
+	}
+	res := []*Event{}
+	for _, log := range logs {
+		evnt, err := decodeEvent(abiEvent, log)
+		if err != nil {
+			// the log matches the signature of the event but
+			// does not follow the abi, skip it
+			continue
+		}
+		res = append(res, evnt)
+	}
+	return res, nil
+}
+
+// WatchLogs sends to the channel the decoded events emitted by the contract as
+// new blocks are created. Events removed during a chain reorganization are sent
+// again with the Removed flag set. The indexed arguments work as in FilterLogs.
+// It returns a function to stop watching, any event blocked on the channel
+// is dropped once it is called.
+func (a *Contract) WatchLogs(event string, ch chan<- *Event, indexedArgs ...interface{}) (func() error, error) {
+	provider, ok := a.provider.(EventProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider does not support logs")
+	}
+	abiEvent, filter, err := a.buildLogFilter(event, indexedArgs)
+	if err != nil {
+		return nil, err
+	}
+	doneCh := make(chan struct{})
+	closeFn, err := provider.WatchLogs(filter, func(log *core.Log) {
+		evnt, err := decodeEvent(abiEvent, log)
+		if err != nil {
+			// the log matches the signature of the event but
+			// does not follow the abi, skip it
+			return
+		}
+		select {
+		case ch <- evnt:
+		case <-doneCh:
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	var closeOnce sync.Once
+	return func() error {
+		closeOnce.Do(func() {
+			close(doneCh)
+		})
+		return closeFn()
+	}, nil
+}
+
+func (a *Contract) buildLogFilter(event string, indexedArgs []interface{}) (*abi.Event, *core.LogFilter, error) {
+	abiEvent, ok := a.abi.Events[event]
+	if !ok {
+		return nil, nil, fmt.Errorf("event %s not found", event)
+	}
+	if abiEvent.Anonymous {
+		return nil, nil, fmt.Errorf("anonymous event %s cannot be filtered", event)
+	}
+	topics, err := buildTopics(abiEvent, indexedArgs)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter := &core.LogFilter{
+		Address: []core.Address{a.addr},
+		Topics:  topics,
+	}
+	return abiEvent, filter, nil
+}
+
+func buildTopics(event *abi.Event, args []interface{}) ([][]*core.Hash, error) {
+	id := event.ID()
+	topics := [][]*core.Hash{{&id}}
+
+	indexed := []*abi.TupleElem{}
+	for _, elem := range event.Inputs.TupleElems() {
+		if elem.Indexed {
+			indexed = append(indexed, elem)
+		}
+	}
+	if len(args) > len(indexed) {
+		return nil, fmt.Errorf("event %s has %d indexed arguments but %d found", event.Name, len(indexed), len(args))
+	}
+	for indx, arg := range args {
+		if arg == nil {
+			topics = append(topics, nil)
+			continue
+		}
+		vals, ok := arg.([]interface{})
+		if !ok {
+			vals = []interface{}{arg}
+		}
+		topic := []*core.Hash{}
+		for _, val := range vals {
+			hash, err := abi.EncodeTopic(indexed[indx].Elem, val)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode indexed argument %d: %v", indx, err)
+			}
+			topic = append(topic, &hash)
+		}
+		topics = append(topics, topic)
+	}
+	return topics, nil
+}
+
+func decodeEvent(event *abi.Event, log *core.Log) (*Event, error) {
+	vals, err := event.ParseLog(log)
+	if err != nil {
+		return nil, err
+	}
+	evnt := &Event{
+		Name:    event.Name,
+		Values:  vals,
+		Removed: log.Removed,
+		Log:     log,
+	}
+	return evnt, nil
+}
+
+func (j *jsonRPCNodeProvider) GetLogs(filter *core.LogFilter) ([]*core.Log, error) {
+	return j.client.GetLogs(filter)
+}
+
+func (j *jsonRPCNodeProvider) WatchLogs(filter *core.LogFilter, callback func(log *core.Log)) (func() error, error) {
+	if j.client.SubscriptionEnabled() {
+		return j.client.SubscribeLogs(filter, callback)
+	}
+	return newLogPoller(j.client, filter, j.pollInterval, callback)
+}
+
+// logPoller watches for logs with the eth_newFilter and eth_getFilterChanges
+// endpoints for transports that do not support subscriptions.
+type logPoller struct {
+	client   *jsonrpc.Eth
+	filter   *core.LogFilter
+	callback func(log *core.Log)
+
+	lock sync.Mutex
+	id   string
+
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+func newLogPoller(client *jsonrpc.Eth, filter *core.LogFilter, interval time.Duration, callback func(log *core.Log)) (func() error, error) {
+	p := &logPoller{
+		client:   client,
+		filter:   filter,
+		callback: callback,
+		closeCh:  make(chan struct{}),
+	}
+	id, err := client.NewFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	p.id = id
+
+	go p.run(interval)
+	return p.close, nil
+}
+
+func (p *logPoller) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.closeCh:
+			return
+		}
+
+		p.lock.Lock()
+		logs, err := p.client.GetFilterChanges(p.id)
+		if err != nil {
+			// the node might have dropped the filter after
+			// some inactivity, install it again.
+			if id, err := p.client.NewFilter(p.filter); err == nil {
+				p.id = id
+			}
+		}
+		p.lock.Unlock()
+
+		for _, log := range logs {
+			select {
+			case <-p.closeCh:
+				return
+			default:
+			}
+			p.callback(log)
+		}
+	}
+}
+
+func (p *logPoller) close() error {
+	closed := false
+	p.closeOnce.Do(func() {
+		close(p.closeCh)
+		closed = true
+	})
+	if !closed {
+		return fmt.Errorf("log poller already closed")
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	_, err := p.client.UninstallFilter(p.id)
+	return err
+}
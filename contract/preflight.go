@@ -0,0 +1,114 @@
+package contract
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/jsonrpc/codec"
+)
+
+// RevertError is the error returned when a contract transaction reverts
+type RevertError struct {
+	// Method is the name of the method that reverted
+	Method string
+
+	// Args are the arguments of the method
+	Args []interface{}
+
+	// Reason is the reason of a revert(string) or require statement
+	Reason string
+
+	// CustomError is the custom error in the abi of the contract
+	CustomError *abi.Error
+
+	// Values are the decoded inputs of the custom error
+	Values map[string]interface{}
+
+	// Data is the raw revert data
+	Data []byte
+
+	// Err is the error returned by the node
+	Err error
+}
+
+// Error implements the error interface
+func (r *RevertError) Error() string {
+	msg := fmt.Sprintf("method %s%v reverted", r.Method, r.Args)
+	switch {
+	case r.Reason != "":
+		return msg + ": " + r.Reason
+	case r.CustomError != nil:
+		return fmt.Sprintf("%s: %s%v", msg, r.CustomError.Name, r.Values)
+	case len(r.Data) != 0:
+		return fmt.Sprintf("%s: 0x%x", msg, r.Data)
+	default:
+		return fmt.Sprintf("%s: %v", msg, r.Err)
+	}
+}
+
+// Unwrap returns the error returned by the node
+func (r *RevertError) Unwrap() error {
+	return r.Err
+}
+
+// preflight simulates the transaction with an eth_call at the pending block
+func (m *methodTxn) preflight() error {
+	opts := &CallOpts{
+		Block: core.Pending,
+		From:  m.contract.key.Address(),
+	}
+	if m.opts != nil {
+		opts.Value = m.opts.Value
+	}
+	_, err := m.contract.provider.Call(m.to, m.input, opts)
+	return err
+}
+
+// decodeRevert converts the error into a RevertError if the
+// node returned a revert error for the method call
+func (m *methodTxn) decodeRevert(err error) error {
+	var obj *codec.ErrorObject
+	if !errors.As(err, &obj) {
+		return err
+	}
+	data := revertData(obj)
+	if data == nil && !strings.Contains(obj.Message, "revert") {
+		return err
+	}
+
+	revertErr := &RevertError{
+		Method: m.method,
+		Args:   m.args,
+		Data:   data,
+		Err:    err,
+	}
+	if reason, err := abi.UnpackRevertError(data); err == nil {
+		revertErr.Reason = reason
+	} else if code, err := abi.UnpackPanicError(data); err == nil {
+		revertErr.Reason = fmt.Sprintf("panic code 0x%x", code)
+	} else if len(data) >= 4 {
+		if customErr := m.contract.abi.GetErrorByID(data[:4]); customErr != nil {
+			if vals, err := customErr.Decode(data); err == nil {
+				revertErr.CustomError = customErr
+				revertErr.Values = vals
+			}
+		}
+	}
+	return revertErr
+}
+
+func revertData(obj *codec.ErrorObject) []byte {
+	str, ok := obj.Data.(string)
+	if !ok || !strings.HasPrefix(str, "0x") {
+		return nil
+	}
+	data, err := hex.DecodeString(str[2:])
+	if err != nil {
+		return nil
+	}
+	return data
+}
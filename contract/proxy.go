@@ -0,0 +1,271 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/core"
+)
+
+// ErrNotProxy is returned when the contract does not follow any of the known proxy patterns
+var ErrNotProxy = fmt.Errorf("contract is not a proxy")
+
+// ProxyType is the pattern used by a proxy contract
+type ProxyType string
+
+const (
+	// ProxyEIP1967 is a proxy with the implementation in the EIP-1967 slot
+	ProxyEIP1967 ProxyType = "eip1967"
+
+	// ProxyBeacon is an EIP-1967 proxy that gets the implementation from a beacon
+	ProxyBeacon ProxyType = "beacon"
+
+	// ProxyEIP1822 is an EIP-1822 universal upgradeable proxy (UUPS)
+	ProxyEIP1822 ProxyType = "eip1822"
+
+	// ProxyEIP1167 is an EIP-1167 minimal proxy (clone)
+	ProxyEIP1167 ProxyType = "eip1167"
+
+	// ProxyGnosisSafe is a Gnosis Safe proxy
+	ProxyGnosisSafe ProxyType = "gnosis-safe"
+)
+
+var (
+	// bytes32(uint256(keccak256('eip1967.proxy.implementation')) - 1)
+	eip1967ImplementationSlot = core.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+
+	// bytes32(uint256(keccak256('eip1967.proxy.beacon')) - 1)
+	eip1967BeaconSlot = core.HexToHash("0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582b35133d50")
+
+	// keccak256('PROXIABLE')
+	eip1822Slot = core.HexToHash("0xc5f16f0fcc639fa48a6947836d9850f504798523bf8c9a3a87d5876cf622bcf7")
+
+	// singleton of the gnosis safe proxy
+	gnosisSafeSingletonSlot = core.Hash{}
+)
+
+var (
+	// implementation() of the beacon
+	beaconImplementationSig = []byte{0x5c, 0x60, 0xda, 0x1b}
+
+	// masterCopy() of the gnosis safe proxy
+	masterCopySig = []byte{0xa6, 0x19, 0x48, 0x6e}
+)
+
+var (
+	eip1167Prefix = []byte{0x36, 0x3d, 0x3d, 0x37, 0x3d, 0x3d, 0x3d, 0x36, 0x3d, 0x73}
+	eip1167Suffix = []byte{0x5a, 0xf4, 0x3d, 0x82, 0x80, 0x3e, 0x90, 0x3d, 0x91, 0x60, 0x2b, 0x57, 0xfd, 0x5b, 0xf3}
+)
+
+// StorageProvider is a Provider that can query the storage of an account
+type StorageProvider interface {
+	GetStorageAt(addr core.Address, slot core.Hash, block core.BlockNumber) (core.Hash, error)
+}
+
+func (j *jsonRPCNodeProvider) GetStorageAt(addr core.Address, slot core.Hash, block core.BlockNumber) (core.Hash, error) {
+	return j.client.GetStorageAt(addr, slot, block)
+}
+
+// Proxy is the result of the proxy detection
+type Proxy struct {
+	// Type is the pattern of the proxy
+	Type ProxyType
+
+	// Implementation is the address of the contract with the logic
+	Implementation core.Address
+
+	// Beacon is the address of the beacon for beacon proxies
+	Beacon core.Address
+}
+
+// DetectProxy checks if the contract is a proxy and returns the address of its
+// implementation. It returns ErrNotProxy if the contract does not follow any of
+// the EIP-1167, EIP-1967, beacon, EIP-1822 or Gnosis Safe proxy patterns.
+func (a *Contract) DetectProxy(block core.BlockNumber) (*Proxy, error) {
+	codeProvider, ok := a.provider.(CodeProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider does not support querying the code")
+	}
+	storageProvider, ok := a.provider.(StorageProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider does not support querying the storage")
+	}
+
+	code, err := codeProvider.GetCode(a.addr, block)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("no code at %s", a.addr)
+	}
+	if impl, ok := parseMinimalProxy(code); ok {
+		return &Proxy{Type: ProxyEIP1167, Implementation: impl}, nil
+	}
+
+	slots := []struct {
+		typ  ProxyType
+		slot core.Hash
+	}{
+		{ProxyEIP1967, eip1967ImplementationSlot},
+		{ProxyBeacon, eip1967BeaconSlot},
+		{ProxyEIP1822, eip1822Slot},
+	}
+	for _, s := range slots {
+		val, err := storageProvider.GetStorageAt(a.addr, s.slot, block)
+		if err != nil {
+			return nil, err
+		}
+		addr, ok := hashToAddress(val[:])
+		if !ok {
+			continue
+		}
+		if s.typ != ProxyBeacon {
+			return &Proxy{Type: s.typ, Implementation: addr}, nil
+		}
+		impl, err := a.callAddress(addr, beaconImplementationSig, block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query the implementation of the beacon %s: %v", addr, err)
+		}
+		return &Proxy{Type: ProxyBeacon, Implementation: impl, Beacon: addr}, nil
+	}
+
+	if impl, ok, err := a.detectGnosisSafe(storageProvider, code, block); err != nil {
+		return nil, err
+	} else if ok {
+		return &Proxy{Type: ProxyGnosisSafe, Implementation: impl}, nil
+	}
+	return nil, ErrNotProxy
+}
+
+// detectGnosisSafe checks if the contract is a gnosis safe proxy. The singleton
+// is stored in the first slot and the runtime code of the proxy answers the
+// masterCopy call itself, the call only confirms the singleton in the storage.
+func (a *Contract) detectGnosisSafe(provider StorageProvider, code []byte, block core.BlockNumber) (core.Address, bool, error) {
+	val, err := provider.GetStorageAt(a.addr, gnosisSafeSingletonSlot, block)
+	if err != nil {
+		return core.Address{}, false, err
+	}
+	singleton, ok := hashToAddress(val[:])
+	if !ok {
+		return core.Address{}, false, nil
+	}
+	if !bytes.Contains(code, masterCopySig) {
+		return core.Address{}, false, nil
+	}
+	impl, err := a.callAddress(a.addr, masterCopySig, block)
+	if err != nil || impl != singleton {
+		return core.Address{}, false, nil
+	}
+	return singleton, true, nil
+}
+
+// callAddress calls a method without inputs that returns an address
+func (a *Contract) callAddress(addr core.Address, sig []byte, block core.BlockNumber) (core.Address, error) {
+	raw, err := a.provider.Call(addr, sig, &CallOpts{Block: block})
+	if err != nil {
+		return core.Address{}, err
+	}
+	if len(raw) != 32 {
+		return core.Address{}, fmt.Errorf("unexpected output length %d", len(raw))
+	}
+	res, ok := hashToAddress(raw)
+	if !ok {
+		return core.Address{}, fmt.Errorf("output is not an address")
+	}
+	return res, nil
+}
+
+// hashToAddress decodes a non empty address stored in a 32 bytes word
+func hashToAddress(b []byte) (core.Address, bool) {
+	var addr core.Address
+	if !bytes.Equal(b[:12], make([]byte, 12)) {
+		return addr, false
+	}
+	copy(addr[:], b[12:])
+	return addr, addr != core.ZeroAddress
+}
+
+func parseMinimalProxy(code []byte) (core.Address, bool) {
+	var addr core.Address
+	if len(code) != len(eip1167Prefix)+20+len(eip1167Suffix) {
+		return addr, false
+	}
+	if !bytes.HasPrefix(code, eip1167Prefix) || !bytes.HasSuffix(code, eip1167Suffix) {
+		return addr, false
+	}
+	copy(addr[:], code[len(eip1167Prefix):])
+	return addr, true
+}
+
+// ABISource returns the abi of a deployed contract. The etherscan
+// client implements this interface with the verified contracts.
+type ABISource interface {
+	GetABI(addr core.Address) (*abi.ABI, error)
+}
+
+// LocalABISource is an ABISource that reads the abi of the contracts from
+// the files in a directory. The files are named after the address of the
+// contract (i.e. 0x5FbDB2315678afecb367f032d93F642f64180aa3.json) and
+// contain either the abi or an artifact with an abi field.
+type LocalABISource struct {
+	dir string
+}
+
+// NewLocalABISource creates a new LocalABISource reading from the directory
+func NewLocalABISource(dir string) *LocalABISource {
+	return &LocalABISource{dir: dir}
+}
+
+// GetABI implements the ABISource interface
+func (l *LocalABISource) GetABI(addr core.Address) (*abi.ABI, error) {
+	names := []string{
+		addr.String(),
+		strings.ToLower(addr.String()),
+	}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(filepath.Join(l.dir, name+".json"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.TrimSpace(data)
+		if bytes.HasPrefix(data, []byte("{")) {
+			var artifact struct {
+				Abi json.RawMessage `json:"abi"`
+			}
+			if err := json.Unmarshal(data, &artifact); err != nil {
+				return nil, err
+			}
+			data = artifact.Abi
+		}
+		return abi.NewABIFromReader(bytes.NewReader(data))
+	}
+	return nil, fmt.Errorf("abi for %s not found", addr)
+}
+
+// NewProxyContract creates a contract bound to the proxy at the address with
+// the abi of its implementation loaded from the source. It returns the proxy
+// detected or ErrNotProxy if the contract is not a proxy.
+func NewProxyContract(addr core.Address, source ABISource, opts ...ContractOption) (*Contract, *Proxy, error) {
+	a := NewContract(addr, nil, opts...)
+
+	proxy, err := a.DetectProxy(core.Latest)
+	if err != nil {
+		return nil, nil, err
+	}
+	implABI, err := source.GetABI(proxy.Implementation)
+	if err != nil {
+		return nil, nil, err
+	}
+	a.abi = implABI
+	return a, proxy, nil
+}
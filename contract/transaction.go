@@ -117,6 +117,10 @@ func (j *jsonrpcTransaction) Do() error {
 	}
 
 	j.txnRaw = txnRaw
+	if j.opts.DryRun {
+		j.hash = core.BytesToHash(core.Keccak256(txnRaw))
+		return nil
+	}
 	hash, err := j.client.SendRawTransaction(j.txnRaw)
 	if err != nil {
 		return err
@@ -125,10 +129,18 @@ func (j *jsonrpcTransaction) Do() error {
 	return nil
 }
 
+// Raw implements the RawTxn interface
+func (j *jsonrpcTransaction) Raw() []byte {
+	return j.txnRaw
+}
+
 func (j *jsonrpcTransaction) Wait() (*core.Receipt, error) {
 	if (j.hash == core.Hash{}) {
 		panic("transaction not executed")
 	}
+	if j.opts.DryRun {
+		return nil, fmt.Errorf("dry run transaction was not sent")
+	}
 
 	for {
 		receipt, err := j.client.GetTransactionReceipt(j.hash)
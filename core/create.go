@@ -0,0 +1,25 @@
+package core
+
+import (
+	"github.com/umbracle/fastrlp"
+)
+
+// CreateAddress returns the address of the contract deployed
+// with the CREATE opcode by the sender at the given nonce
+func CreateAddress(sender Address, nonce uint64) Address {
+	a := fastrlp.DefaultArenaPool.Get()
+
+	v := a.NewArray()
+	v.Set(a.NewBytes(sender[:]))
+	v.Set(a.NewUint(nonce))
+	raw := v.MarshalTo(nil)
+
+	fastrlp.DefaultArenaPool.Put(a)
+	return BytesToAddress(Keccak256(raw)[12:])
+}
+
+// CreateAddress2 returns the address of the contract deployed with
+// the CREATE2 opcode by the sender with the salt and the init code
+func CreateAddress2(sender Address, salt Hash, initCode []byte) Address {
+	return BytesToAddress(Keccak256([]byte{0xff}, sender[:], salt[:], Keccak256(initCode))[12:])
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAddress(t *testing.T) {
+	sender := HexToAddress("0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0")
+
+	cases := []struct {
+		nonce uint64
+		addr  string
+	}{
+		{0, "0xcd234a471b72ba2f1ccf0a70fcaba648a5eecd8d"},
+		{1, "0x343c43a37d37dff08ae8c4a11544c718abb4fcf8"},
+		{2, "0xf778b86fa74e846c4f0a1fbd1335fe81c00a0c91"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, HexToAddress(c.addr), CreateAddress(sender, c.nonce))
+	}
+}
+
+func TestCreateAddress2(t *testing.T) {
+	// test vectors from EIP-1014
+	cases := []struct {
+		sender   string
+		salt     string
+		initCode string
+		addr     string
+	}{
+		{
+			"0x0000000000000000000000000000000000000000",
+			"0x0000000000000000000000000000000000000000000000000000000000000000",
+			"00",
+			"0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38",
+		},
+		{
+			"0xdeadbeef00000000000000000000000000000000",
+			"0x000000000000000000000000feed000000000000000000000000000000000000",
+			"00",
+			"0xD04116cDd17beBE565EB2422F2497E06cC1C9833",
+		},
+		{
+			"0x00000000000000000000000000000000deadbeef",
+			"0x00000000000000000000000000000000000000000000000000000000cafebabe",
+			"deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			"0x1d8bfDC5D46DC4f61D6b6115972536eBE6A8854C",
+		},
+	}
+	for _, c := range cases {
+		initCode, err := hex.DecodeString(c.initCode)
+		assert.NoError(t, err)
+		assert.Equal(t, HexToAddress(c.addr), CreateAddress2(HexToAddress(c.sender), HexToHash(c.salt), initCode))
+	}
+}
@@ -330,9 +330,11 @@ func (l *Log) Copy() *Log {
 type BlockNumber int
 
 const (
-	Latest   BlockNumber = -1
-	Earliest BlockNumber = -2
-	Pending  BlockNumber = -3
+	Latest    BlockNumber = -1
+	Earliest  BlockNumber = -2
+	Pending   BlockNumber = -3
+	Safe      BlockNumber = -4
+	Finalized BlockNumber = -5
 )
 
 func (b BlockNumber) Location() string {
@@ -347,6 +349,10 @@ func (b BlockNumber) String() string {
 		return "earliest"
 	case Pending:
 		return "pending"
+	case Safe:
+		return "safe"
+	case Finalized:
+		return "finalized"
 	}
 	if b < 0 {
 		panic("internal. blocknumber is negative")
@@ -19,7 +19,7 @@ func compactJSON(s string) string {
 }
 
 func TestEncodingJSON_Block(t *testing.T) {
-	for _, c := range readTestsuite(t, "./testsuite/block-*.json") {
+	for _, c := range readTestsuite(t, "../testsuite/block-*.json") {
 		content := []byte(compactJSON(string(c.content)))
 		txn := new(Block)
 
@@ -36,7 +36,7 @@ func TestEncodingJSON_Block(t *testing.T) {
 }
 
 func TestEncodingJSON_Transaction(t *testing.T) {
-	for _, c := range readTestsuite(t, "./testsuite/transaction-*.json") {
+	for _, c := range readTestsuite(t, "../testsuite/transaction-*.json") {
 		content := []byte(compactJSON(string(c.content)))
 		txn := new(Transaction)
 
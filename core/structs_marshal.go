@@ -13,7 +13,13 @@ var defaultArena fastjson.ArenaPool
 // MarshalJSON implements the marshal interface
 func (l *Log) MarshalJSON() ([]byte, error) {
 	a := defaultArena.Get()
+	v := l.marshalJSON(a)
+	res := v.MarshalTo(nil)
+	defaultArena.Put(a)
+	return res, nil
+}
 
+func (l *Log) marshalJSON(a *fastjson.Arena) *fastjson.Value {
 	o := a.NewObject()
 	if l.Removed {
 		o.Set("removed", a.NewTrue())
@@ -33,6 +39,45 @@ func (l *Log) MarshalJSON() ([]byte, error) {
 		vv.SetArrayItem(indx, a.NewString(topic.String()))
 	}
 	o.Set("topics", vv)
+	return o
+}
+
+// MarshalJSON implements the marshal interface
+func (r *Receipt) MarshalJSON() ([]byte, error) {
+	a := defaultArena.Get()
+
+	o := a.NewObject()
+	o.Set("transactionHash", a.NewString(r.TransactionHash.String()))
+	o.Set("transactionIndex", a.NewString(fmt.Sprintf("0x%x", r.TransactionIndex)))
+	o.Set("blockHash", a.NewString(r.BlockHash.String()))
+	o.Set("blockNumber", a.NewString(fmt.Sprintf("0x%x", r.BlockNumber)))
+	o.Set("from", a.NewString(r.From.String()))
+	if r.To != nil {
+		o.Set("to", a.NewString(r.To.String()))
+	} else {
+		o.Set("to", a.NewNull())
+	}
+	if r.ContractAddress != ZeroAddress {
+		o.Set("contractAddress", a.NewString(r.ContractAddress.String()))
+	} else {
+		o.Set("contractAddress", a.NewNull())
+	}
+	o.Set("gasUsed", a.NewString(fmt.Sprintf("0x%x", r.GasUsed)))
+	o.Set("cumulativeGasUsed", a.NewString(fmt.Sprintf("0x%x", r.CumulativeGasUsed)))
+	o.Set("status", a.NewString(fmt.Sprintf("0x%x", r.Status)))
+
+	// the bloom is always encoded with 256 bytes
+	bloom := r.LogsBloom
+	if len(bloom) == 0 {
+		bloom = make([]byte, 256)
+	}
+	o.Set("logsBloom", a.NewString("0x"+hex.EncodeToString(bloom)))
+
+	logs := a.NewArray()
+	for indx, log := range r.Logs {
+		logs.SetArrayItem(indx, log.marshalJSON(a))
+	}
+	o.Set("logs", logs)
 
 	res := o.MarshalTo(nil)
 	defaultArena.Put(a)
@@ -215,6 +260,7 @@ func (l *LogFilter) MarshalJSON() ([]byte, error) {
 		for indx, addr := range l.Address {
 			v.SetArrayItem(indx, a.NewString(addr.String()))
 		}
+		o.Set("address", v)
 	}
 
 	v := a.NewArray()
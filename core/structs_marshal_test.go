@@ -2,6 +2,7 @@ package core
 
 import (
 	"encoding/json"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -93,4 +94,70 @@ func TestLogFilter_MarshalJSON(t *testing.T) {
 			assert.Equal(t, defaultLogFilter, reverseOutput)
 		})
 	}
+
+	t.Run("match multiple addresses", func(t *testing.T) {
+		logFilter := &LogFilter{
+			Address: []Address{HexToAddress("0x123"), HexToAddress("0x456")},
+		}
+
+		output, err := logFilter.MarshalJSON()
+		assert.NoError(t, err)
+
+		reverseOutput := &LogFilter{}
+		assert.NoError(t, json.Unmarshal(output, reverseOutput))
+		assert.Equal(t, logFilter.Address, reverseOutput.Address)
+	})
+}
+
+func TestReceipt_MarshalJSON(t *testing.T) {
+	to := HexToAddress("0x2")
+	receipt := &Receipt{
+		TransactionHash:   HexToHash("0x1"),
+		TransactionIndex:  1,
+		BlockHash:         HexToHash("0x2"),
+		BlockNumber:       10,
+		From:              HexToAddress("0x1"),
+		To:                &to,
+		GasUsed:           21000,
+		CumulativeGasUsed: 42000,
+		LogsBloom:         make([]byte, 256),
+		Status:            1,
+		Logs: []*Log{
+			{
+				BlockNumber: 10,
+				Address:     HexToAddress("0x3"),
+				Topics:      []Hash{HexToHash("0x4")},
+				Data:        []byte{0x1},
+			},
+		},
+	}
+
+	data, err := receipt.MarshalJSON()
+	assert.NoError(t, err)
+
+	receipt2 := new(Receipt)
+	assert.NoError(t, json.Unmarshal(data, receipt2))
+	assert.Equal(t, receipt, receipt2)
+}
+
+func TestTransaction_MarshalJSONEmptyFields(t *testing.T) {
+	// the input, the nonce and the gas are omitted if empty
+	txn := &Transaction{
+		Hash:        HexToHash("0x1"),
+		From:        HexToAddress("0x2"),
+		Value:       big.NewInt(1),
+		BlockHash:   HexToHash("0x3"),
+		BlockNumber: 10,
+	}
+
+	data, err := txn.MarshalJSON()
+	assert.NoError(t, err)
+
+	txn2 := new(Transaction)
+	assert.NoError(t, txn2.UnmarshalJSON(data))
+	assert.Equal(t, txn.Hash, txn2.Hash)
+	assert.Equal(t, txn.Value, txn2.Value)
+	assert.Empty(t, txn2.Input)
+	assert.Zero(t, txn2.Nonce)
+	assert.Zero(t, txn2.Gas)
 }
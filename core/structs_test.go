@@ -1,7 +1,6 @@
 package core
 
 import (
-	_ "embed"
 	"encoding/json"
 	"math/big"
 	"reflect"
@@ -106,12 +105,11 @@ func TestLog_Copy(t *testing.T) {
 	}
 }
 
-//go:embed ../testsuite/receipts.json
-var receiptsFixtures []byte
-
 func TestReceipt_Unmarshal(t *testing.T) {
 	var cases []json.RawMessage
-	assert.NoError(t, json.Unmarshal(receiptsFixtures, &cases))
+	for _, c := range readTestsuite(t, "../testsuite/receipts.json") {
+		assert.NoError(t, json.Unmarshal(c.content, &cases))
+	}
 
 	for _, c := range cases {
 		receipt := &Receipt{}
@@ -87,7 +87,7 @@ func (b *Block) UnmarshalJSON(buf []byte) error {
 			for _, elem := range elems {
 				txn := new(Transaction)
 				if err := txn.unmarshalJSON(elem); err != nil {
-					panic(err)
+					return err
 				}
 				b.Transactions = append(b.Transactions, txn)
 			}
@@ -159,14 +159,21 @@ func (t *Transaction) unmarshalJSON(v *fastjson.Value) error {
 	if t.GasPrice, err = decodeUint(v, "gasPrice"); err != nil {
 		return err
 	}
-	if t.Input, err = decodeBytes(t.Input[:0], v, "input"); err != nil {
-		return err
+	// the input, the nonce and the gas are omitted by MarshalJSON if empty
+	t.Input = t.Input[:0]
+	if v.Exists("input") {
+		if t.Input, err = decodeBytes(t.Input, v, "input"); err != nil {
+			return err
+		}
 	}
 	if t.Value, err = decodeBigInt(t.Value, v, "value"); err != nil {
 		return err
 	}
-	if t.Nonce, err = decodeUint(v, "nonce"); err != nil {
-		return err
+	t.Nonce = 0
+	if v.Exists("nonce") {
+		if t.Nonce, err = decodeUint(v, "nonce"); err != nil {
+			return err
+		}
 	}
 
 	{
@@ -203,8 +210,11 @@ func (t *Transaction) unmarshalJSON(v *fastjson.Value) error {
 		}
 	}
 
-	if t.Gas, err = decodeUint(v, "gas"); err != nil {
-		return err
+	t.Gas = 0
+	if v.Exists("gas") {
+		if t.Gas, err = decodeUint(v, "gas"); err != nil {
+			return err
+		}
 	}
 
 	if typ == TransactionDynamicFee {
@@ -2,21 +2,82 @@ package ens
 
 import (
 	"github.com/deep-nl/ethgo/core"
+	"strings"
 	"testing"
 
+	builtin "github.com/deep-nl/ethgo/builtin/ens"
+	"github.com/deep-nl/ethgo/contract"
+	"github.com/deep-nl/ethgo/jsonrpc"
 	"github.com/deep-nl/ethgo/testutil"
+	"github.com/deep-nl/ethgo/testutil/simulated"
+	"github.com/deep-nl/ethgo/wallet"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// deployENS deploys an ENS registry and a resolver on the chain, registers
+// the name for the address and sets the reverse record of the address
+func deployENS(t *testing.T, client *jsonrpc.Client, name string, addr core.Address) core.Address {
+	key := wallet.KeyFromString(testutil.FromKey)
+	opts := []contract.ContractOption{
+		contract.WithJsonRPC(client.Eth()),
+		contract.WithSender(key),
+	}
+
+	send := func(txn contract.Txn, err error) *core.Receipt {
+		require.NoError(t, err)
+		require.NoError(t, txn.Do())
+
+		receipt, err := txn.Wait()
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), receipt.Status)
+		return receipt
+	}
+
+	registryAddr := send(builtin.DeployENS(client, key.Address(), nil, opts...)).ContractAddress
+	resolverAddr := send(builtin.DeployResolver(client, key.Address(), []interface{}{registryAddr}, opts...)).ContractAddress
+
+	registry := builtin.NewENS(registryAddr, opts...)
+	resolver := builtin.NewResolver(resolverAddr, opts...)
+
+	// register the name with all its parents and point it to the resolver
+	register := func(name string) core.Hash {
+		labels := strings.Split(name, ".")
+		for i := len(labels) - 1; i >= 0; i-- {
+			parent := builtin.NameHash(strings.Join(labels[i+1:], "."))
+			label := core.BytesToHash(core.Keccak256([]byte(labels[i])))
+			send(registry.SetSubnodeOwner(parent, label, key.Address()))
+		}
+		node := builtin.NameHash(name)
+		send(registry.SetResolver(node, resolverAddr))
+		return node
+	}
+
+	send(resolver.SetAddr(register(name), addr))
+
+	reverse := strings.ToLower(strings.TrimPrefix(addr.String(), "0x")) + ".addr.reverse"
+	send(resolver.SetName(register(reverse), name))
+
+	return registryAddr
+}
+
 func TestENS_Resolve(t *testing.T) {
-	ens, err := NewENS(WithAddress(testutil.TestInfuraEndpoint(t)))
+	_, chain := simulated.NewTestServer(t)
+
+	client := jsonrpc.NewClientWithTransport(chain.Transport())
+	defer client.Close()
+
+	target := core.HexToAddress("0xb8c2C29ee19D8307cb7255e1Cd9CbDE883A267d5")
+	registryAddr := deployENS(t, client, "nick.eth", target)
+
+	ens, err := NewENS(WithClient(client), WithResolver(registryAddr))
 	assert.NoError(t, err)
 
 	addr, err := ens.Resolve("nick.eth")
 	assert.NoError(t, err)
-	assert.Equal(t, core.HexToAddress("0xb8c2C29ee19D8307cb7255e1Cd9CbDE883A267d5"), addr)
+	assert.Equal(t, target, addr)
 
-	name, err := ens.ReverseResolve(core.HexToAddress("0xb8c2C29ee19D8307cb7255e1Cd9CbDE883A267d5"))
+	name, err := ens.ReverseResolve(target)
 	assert.NoError(t, err)
 	assert.Equal(t, "nick.eth", name)
 }
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/deep-nl/ethgo/abi"
 	"github.com/deep-nl/ethgo/jsonrpc/codec"
 	"github.com/valyala/fasthttp"
 )
@@ -148,6 +149,23 @@ func (e *Etherscan) GetContractCode(addr core.Address) (*ContractCode, error) {
 	return out[0], nil
 }
 
+// GetABI returns the abi of a verified contract
+func (e *Etherscan) GetABI(addr core.Address) (*abi.ABI, error) {
+	var out string
+	err := e.Query("contract", "getabi", &out, map[string]string{
+		"address": addr.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	res, err := abi.NewABI(out)
+	if err != nil {
+		// etherscan returns the error message as the result
+		return nil, fmt.Errorf("failed to get abi for %s: %s", addr, out)
+	}
+	return res, nil
+}
+
 func (e *Etherscan) GasPrice() (uint64, error) {
 	var out struct {
 		LastBlock string `json:"LastBlock"`
@@ -174,6 +192,10 @@ func (e *Etherscan) GetLogs(filter *core.LogFilter) ([]*core.Log, error) {
 			return "earliest"
 		case core.Pending:
 			return "pending"
+		case core.Safe:
+			return "safe"
+		case core.Finalized:
+			return "finalized"
 		}
 		if b < 0 {
 			panic("internal. blocknumber is negative")
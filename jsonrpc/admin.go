@@ -0,0 +1,94 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+)
+
+// Admin is the admin namespace
+type Admin struct {
+	c *Client
+}
+
+// Admin returns the reference to the admin namespace
+func (c *Client) Admin() *Admin {
+	return c.endpoints.a
+}
+
+// NodeInfo is the information about the running node
+type NodeInfo struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Enode      string    `json:"enode"`
+	ENR        string    `json:"enr"`
+	IP         string    `json:"ip"`
+	Ports      NodePorts `json:"ports"`
+	ListenAddr string    `json:"listenAddr"`
+
+	// Protocols are the details of each of the protocols
+	// run by the node (i.e. eth, snap) indexed by name
+	Protocols map[string]json.RawMessage `json:"protocols"`
+}
+
+// NodePorts are the networking ports of a node
+type NodePorts struct {
+	Discovery int `json:"discovery"`
+	Listener  int `json:"listener"`
+}
+
+// PeerInfo is the information about a peer connected to the node
+type PeerInfo struct {
+	ENR     string      `json:"enr"`
+	Enode   string      `json:"enode"`
+	ID      string      `json:"id"`
+	Name    string      `json:"name"`
+	Caps    []string    `json:"caps"`
+	Network PeerNetwork `json:"network"`
+
+	// Protocols are the details of each of the protocols
+	// run with the peer indexed by name
+	Protocols map[string]json.RawMessage `json:"protocols"`
+}
+
+// PeerNetwork is the information about the connection with a peer
+type PeerNetwork struct {
+	LocalAddress  string `json:"localAddress"`
+	RemoteAddress string `json:"remoteAddress"`
+	Inbound       bool   `json:"inbound"`
+	Trusted       bool   `json:"trusted"`
+	Static        bool   `json:"static"`
+}
+
+// NodeInfo returns the information about the running node
+func (a *Admin) NodeInfo() (*NodeInfo, error) {
+	var out *NodeInfo
+	err := a.c.Call("admin_nodeInfo", &out)
+	return out, err
+}
+
+// Peers returns the peers connected to the node
+func (a *Admin) Peers() ([]*PeerInfo, error) {
+	var out []*PeerInfo
+	err := a.c.Call("admin_peers", &out)
+	return out, err
+}
+
+// AddPeer connects to the peer with the given enode url
+func (a *Admin) AddPeer(enode string) (bool, error) {
+	var out bool
+	err := a.c.Call("admin_addPeer", &out, enode)
+	return out, err
+}
+
+// RemovePeer disconnects from the peer with the given enode url
+func (a *Admin) RemovePeer(enode string) (bool, error) {
+	var out bool
+	err := a.c.Call("admin_removePeer", &out, enode)
+	return out, err
+}
+
+// Datadir returns the absolute path of the data directory of the node
+func (a *Admin) Datadir() (string, error) {
+	var out string
+	err := a.c.Call("admin_datadir", &out)
+	return out, err
+}
@@ -0,0 +1,84 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmin(t *testing.T) {
+	enode := "enode://a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c@52.16.188.185:30303"
+
+	c := newMockClient(t, func(method string, params []json.RawMessage) interface{} {
+		switch method {
+		case "admin_nodeInfo":
+			return json.RawMessage(`{
+				"id": "44826a5d6a55f88a18298bca4773fca5749cdc3a5c9f308aa7d810e9b31123f3",
+				"name": "Geth/v1.10.17-stable/linux-amd64/go1.18",
+				"enode": "` + enode + `",
+				"ip": "52.16.188.185",
+				"ports": {
+					"discovery": 30303,
+					"listener": 30304
+				},
+				"listenAddr": "[::]:30304",
+				"protocols": {
+					"eth": {"network": 1337}
+				}
+			}`)
+
+		case "admin_peers":
+			return json.RawMessage(`[{
+				"enode": "` + enode + `",
+				"id": "a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef2",
+				"name": "Geth/v1.10.17-stable/linux-amd64/go1.18",
+				"caps": ["eth/66", "snap/1"],
+				"network": {
+					"localAddress": "192.168.0.104:51068",
+					"remoteAddress": "52.16.188.185:30303",
+					"inbound": false,
+					"trusted": true,
+					"static": false
+				},
+				"protocols": {}
+			}]`)
+
+		case "admin_addPeer", "admin_removePeer":
+			var url string
+			require.NoError(t, json.Unmarshal(params[0], &url))
+			require.Equal(t, enode, url)
+			return true
+
+		case "admin_datadir":
+			return "/data/geth"
+		}
+		t.Fatalf("unexpected method %s", method)
+		return nil
+	})
+
+	info, err := c.Admin().NodeInfo()
+	require.NoError(t, err)
+	assert.Equal(t, enode, info.Enode)
+	assert.Equal(t, 30304, info.Ports.Listener)
+	assert.Contains(t, info.Protocols, "eth")
+
+	peers, err := c.Admin().Peers()
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	assert.Equal(t, []string{"eth/66", "snap/1"}, peers[0].Caps)
+	assert.True(t, peers[0].Network.Trusted)
+
+	ok, err := c.Admin().AddPeer(enode)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = c.Admin().RemovePeer(enode)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	datadir, err := c.Admin().Datadir()
+	require.NoError(t, err)
+	assert.Equal(t, "/data/geth", datadir)
+}
@@ -15,6 +15,11 @@ type endpoints struct {
 	e *Eth
 	n *Net
 	d *Debug
+	t *Trace
+	p *Txpool
+	a *Admin
+	k *Personal
+	m *Miner
 }
 
 type Config struct {
@@ -37,18 +42,29 @@ func NewClient(addr string, opts ...ConfigOption) (*Client, error) {
 		opt(config)
 	}
 
-	c := &Client{}
-	c.endpoints.w = &Web3{c}
-	c.endpoints.e = &Eth{c}
-	c.endpoints.n = &Net{c}
-	c.endpoints.d = &Debug{c}
-
 	t, err := transport.NewTransport(addr, config.headers)
 	if err != nil {
 		return nil, err
 	}
-	c.transport = t
-	return c, nil
+	return NewClientWithTransport(t), nil
+}
+
+// NewClientWithTransport creates a client that sends the requests
+// with the given transport (i.e. an in-process node)
+func NewClientWithTransport(t transport.Transport) *Client {
+	c := &Client{
+		transport: t,
+	}
+	c.endpoints.w = &Web3{c}
+	c.endpoints.e = &Eth{c}
+	c.endpoints.n = &Net{c}
+	c.endpoints.d = &Debug{c}
+	c.endpoints.t = &Trace{c}
+	c.endpoints.p = &Txpool{c}
+	c.endpoints.a = &Admin{c}
+	c.endpoints.k = &Personal{c}
+	c.endpoints.m = &Miner{c}
+	return c
 }
 
 // Close closes the transport
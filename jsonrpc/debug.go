@@ -1,6 +1,10 @@
 package jsonrpc
 
 import (
+	"encoding/json"
+	"math/big"
+	"time"
+
 	"github.com/deep-nl/ethgo/core"
 )
 
@@ -35,3 +39,305 @@ func (d *Debug) TraceTransaction(hash core.Hash) (*TransactionTrace, error) {
 	err := d.c.Call("debug_traceTransaction", &res, hash)
 	return res, err
 }
+
+const (
+	// CallTracer is the native tracer that returns the call tree (CallFrame)
+	CallTracer = "callTracer"
+
+	// PrestateTracer is the native tracer that returns the accounts touched
+	// by the transaction (PrestateResult) or their diff (PrestateDiff)
+	PrestateTracer = "prestateTracer"
+
+	// FourByteTracer is the native tracer that counts the calls by selector
+	// and size of the calldata
+	FourByteTracer = "4byteTracer"
+)
+
+// TraceConfig is the configuration of the tracers
+type TraceConfig struct {
+	// EnableMemory, DisableStack, DisableStorage and EnableReturnData
+	// configure the struct logger used when no tracer is set
+	EnableMemory     bool
+	DisableStack     bool
+	DisableStorage   bool
+	EnableReturnData bool
+
+	// Tracer is either the name of a native tracer or the code of a js tracer
+	Tracer string
+
+	// TracerConfig is the configuration of the tracer (i.e. CallTracerConfig)
+	TracerConfig interface{}
+
+	// Timeout is the maximum duration of the trace
+	Timeout time.Duration
+
+	// StateOverrides overrides the state of the accounts in TraceCall
+	StateOverrides map[core.Address]*StateOverride
+}
+
+// MarshalJSON implements the Marshal interface.
+func (t *TraceConfig) MarshalJSON() ([]byte, error) {
+	obj := map[string]interface{}{}
+	if t.EnableMemory {
+		obj["enableMemory"] = true
+	}
+	if t.DisableStack {
+		obj["disableStack"] = true
+	}
+	if t.DisableStorage {
+		obj["disableStorage"] = true
+	}
+	if t.EnableReturnData {
+		obj["enableReturnData"] = true
+	}
+	if t.Tracer != "" {
+		obj["tracer"] = t.Tracer
+	}
+	if t.TracerConfig != nil {
+		obj["tracerConfig"] = t.TracerConfig
+	}
+	if t.Timeout != 0 {
+		obj["timeout"] = t.Timeout.String()
+	}
+	if len(t.StateOverrides) != 0 {
+		obj["stateOverrides"] = t.StateOverrides
+	}
+	return json.Marshal(obj)
+}
+
+// CallTracerConfig is the configuration of the CallTracer
+type CallTracerConfig struct {
+	// OnlyTopCall does not trace the inner calls
+	OnlyTopCall bool `json:"onlyTopCall,omitempty"`
+
+	// WithLog includes the logs emitted in each call
+	WithLog bool `json:"withLog,omitempty"`
+}
+
+// PrestateTracerConfig is the configuration of the PrestateTracer
+type PrestateTracerConfig struct {
+	// DiffMode returns the state before and after the transaction
+	DiffMode bool `json:"diffMode,omitempty"`
+}
+
+// StateOverride is the state of an account replaced during a TraceCall
+type StateOverride struct {
+	Balance *big.Int
+	Nonce   *uint64
+	Code    []byte
+
+	// State replaces the whole storage of the account
+	State map[core.Hash]core.Hash
+
+	// StateDiff replaces only the given slots of the storage
+	StateDiff map[core.Hash]core.Hash
+}
+
+// MarshalJSON implements the Marshal interface.
+func (s *StateOverride) MarshalJSON() ([]byte, error) {
+	obj := map[string]interface{}{}
+	if s.Balance != nil {
+		obj["balance"] = (*core.ArgBig)(s.Balance)
+	}
+	if s.Nonce != nil {
+		obj["nonce"] = core.ArgUint64(*s.Nonce)
+	}
+	if s.Code != nil {
+		obj["code"] = core.ArgBytes(s.Code)
+	}
+	if s.State != nil {
+		obj["state"] = s.State
+	}
+	if s.StateDiff != nil {
+		obj["stateDiff"] = s.StateDiff
+	}
+	return json.Marshal(obj)
+}
+
+// CallFrame is a call in the call tree returned by the CallTracer
+type CallFrame struct {
+	Type         string
+	From         core.Address
+	To           core.Address
+	Value        *big.Int
+	Gas          uint64
+	GasUsed      uint64
+	Input        []byte
+	Output       []byte
+	Error        string
+	RevertReason string
+	Calls        []*CallFrame
+	Logs         []*CallLog
+}
+
+// CallLog is a log emitted in a call of the call tree
+type CallLog struct {
+	Address core.Address  `json:"address"`
+	Topics  []core.Hash   `json:"topics"`
+	Data    core.ArgBytes `json:"data"`
+}
+
+// UnmarshalJSON implements the unmarshal interface
+func (c *CallFrame) UnmarshalJSON(data []byte) error {
+	var frame struct {
+		Type         string         `json:"type"`
+		From         core.Address   `json:"from"`
+		To           core.Address   `json:"to"`
+		Value        *core.ArgBig   `json:"value"`
+		Gas          core.ArgUint64 `json:"gas"`
+		GasUsed      core.ArgUint64 `json:"gasUsed"`
+		Input        core.ArgBytes  `json:"input"`
+		Output       core.ArgBytes  `json:"output"`
+		Error        string         `json:"error"`
+		RevertReason string         `json:"revertReason"`
+		Calls        []*CallFrame   `json:"calls"`
+		Logs         []*CallLog     `json:"logs"`
+	}
+	if err := json.Unmarshal(data, &frame); err != nil {
+		return err
+	}
+	c.Type = frame.Type
+	c.From = frame.From
+	c.To = frame.To
+	if frame.Value != nil {
+		c.Value = (*big.Int)(frame.Value)
+	}
+	c.Gas = uint64(frame.Gas)
+	c.GasUsed = uint64(frame.GasUsed)
+	c.Input = frame.Input
+	c.Output = frame.Output
+	c.Error = frame.Error
+	c.RevertReason = frame.RevertReason
+	c.Calls = frame.Calls
+	c.Logs = frame.Logs
+	return nil
+}
+
+// PrestateAccount is the state of an account returned by the PrestateTracer
+type PrestateAccount struct {
+	Balance *big.Int
+	Nonce   uint64
+	Code    []byte
+	Storage map[core.Hash]core.Hash
+}
+
+// UnmarshalJSON implements the unmarshal interface
+func (p *PrestateAccount) UnmarshalJSON(data []byte) error {
+	var account struct {
+		Balance *core.ArgBig            `json:"balance"`
+		Nonce   uint64                  `json:"nonce"`
+		Code    core.ArgBytes           `json:"code"`
+		Storage map[core.Hash]core.Hash `json:"storage"`
+	}
+	if err := json.Unmarshal(data, &account); err != nil {
+		return err
+	}
+	if account.Balance != nil {
+		p.Balance = (*big.Int)(account.Balance)
+	}
+	p.Nonce = account.Nonce
+	p.Code = account.Code
+	p.Storage = account.Storage
+	return nil
+}
+
+// PrestateResult are the accounts touched by a transaction before its execution
+type PrestateResult map[core.Address]*PrestateAccount
+
+// PrestateDiff is the result of the PrestateTracer in diff mode. Post only
+// includes the fields of the accounts that changed and Pre the same fields
+// before the transaction. Accounts created are not included in Pre and
+// accounts deleted are not included in Post.
+type PrestateDiff struct {
+	Pre  PrestateResult `json:"pre"`
+	Post PrestateResult `json:"post"`
+}
+
+// TxTraceResult is the trace of a transaction in a block
+type TxTraceResult struct {
+	TxHash core.Hash       `json:"txHash"`
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+}
+
+// Decode decodes the result of the tracer (i.e. a CallFrame)
+func (t *TxTraceResult) Decode(out interface{}) error {
+	return json.Unmarshal(t.Result, out)
+}
+
+// TraceTransactionWithConfig traces the transaction with the given configuration
+// and decodes the result of the tracer in out
+func (d *Debug) TraceTransactionWithConfig(hash core.Hash, config *TraceConfig, out interface{}) error {
+	return d.c.Call("debug_traceTransaction", out, hash, config)
+}
+
+// TraceCallTree returns the call tree of the transaction using the CallTracer
+func (d *Debug) TraceCallTree(hash core.Hash, config *CallTracerConfig) (*CallFrame, error) {
+	traceConfig := &TraceConfig{
+		Tracer: CallTracer,
+	}
+	if config != nil {
+		traceConfig.TracerConfig = config
+	}
+	var res *CallFrame
+	err := d.TraceTransactionWithConfig(hash, traceConfig, &res)
+	return res, err
+}
+
+// TracePrestate returns the state of the accounts touched by the transaction
+// before its execution using the PrestateTracer
+func (d *Debug) TracePrestate(hash core.Hash) (PrestateResult, error) {
+	var res PrestateResult
+	err := d.TraceTransactionWithConfig(hash, &TraceConfig{Tracer: PrestateTracer}, &res)
+	return res, err
+}
+
+// TraceStateDiff returns the state changes of the transaction using
+// the PrestateTracer in diff mode
+func (d *Debug) TraceStateDiff(hash core.Hash) (*PrestateDiff, error) {
+	config := &TraceConfig{
+		Tracer:       PrestateTracer,
+		TracerConfig: &PrestateTracerConfig{DiffMode: true},
+	}
+	var res *PrestateDiff
+	err := d.TraceTransactionWithConfig(hash, config, &res)
+	return res, err
+}
+
+// Trace4Byte returns the number of calls in the transaction indexed by
+// the selector and the size of the calldata (i.e. 0x27dc297e-128)
+func (d *Debug) Trace4Byte(hash core.Hash) (map[string]int, error) {
+	var res map[string]int
+	err := d.TraceTransactionWithConfig(hash, &TraceConfig{Tracer: FourByteTracer}, &res)
+	return res, err
+}
+
+// TraceCall traces a call on top of the given block and decodes the result of
+// the tracer in out. The state of the accounts can be overridden with the
+// StateOverrides of the configuration.
+func (d *Debug) TraceCall(msg *core.CallMsg, block core.BlockNumberOrHash, config *TraceConfig, out interface{}) error {
+	if config == nil {
+		config = &TraceConfig{}
+	}
+	return d.c.Call("debug_traceCall", out, msg, block.Location(), config)
+}
+
+// TraceBlockByNumber traces all the transactions in the block
+func (d *Debug) TraceBlockByNumber(block core.BlockNumber, config *TraceConfig) ([]*TxTraceResult, error) {
+	if config == nil {
+		config = &TraceConfig{}
+	}
+	var res []*TxTraceResult
+	err := d.c.Call("debug_traceBlockByNumber", &res, block.String(), config)
+	return res, err
+}
+
+// TraceBlockByHash traces all the transactions in the block
+func (d *Debug) TraceBlockByHash(hash core.Hash, config *TraceConfig) ([]*TxTraceResult, error) {
+	if config == nil {
+		config = &TraceConfig{}
+	}
+	var res []*TxTraceResult
+	err := d.c.Call("debug_traceBlockByHash", &res, hash, config)
+	return res, err
+}
@@ -1,15 +1,23 @@
 package jsonrpc
 
 import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/jsonrpc/codec"
 	"github.com/deep-nl/ethgo/testutil"
+	"github.com/deep-nl/ethgo/testutil/simulated"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestDebug_TraceTransaction(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 	c, _ := NewClient(s.HTTPAddr())
 
 	cc := &testutil.Contract{}
@@ -27,3 +35,155 @@ func TestDebug_TraceTransaction(t *testing.T) {
 	assert.Greater(t, trace.Gas, uint64(20000))
 	assert.NotEmpty(t, trace.StructLogs)
 }
+
+// newMockClient returns a client connected to a json-rpc http server
+// that replies to the requests with the result of the handler
+func newMockClient(t *testing.T, handler func(method string, params []json.RawMessage) interface{}) *Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req codec.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var params []json.RawMessage
+		require.NoError(t, json.Unmarshal(req.Params, &params))
+
+		result, err := json.Marshal(handler(req.Method, params))
+		require.NoError(t, err)
+
+		require.NoError(t, json.NewEncoder(w).Encode(&codec.Response{ID: req.ID, Result: result}))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+	return c
+}
+
+func TestDebug_TraceCallTree(t *testing.T) {
+	var config map[string]interface{}
+
+	c := newMockClient(t, func(method string, params []json.RawMessage) interface{} {
+		require.Equal(t, "debug_traceTransaction", method)
+		require.NoError(t, json.Unmarshal(params[1], &config))
+
+		return json.RawMessage(`{
+			"type": "CALL",
+			"from": "0x0000000000000000000000000000000000000001",
+			"to": "0x0000000000000000000000000000000000000002",
+			"value": "0x10",
+			"gas": "0x5208",
+			"gasUsed": "0x100",
+			"input": "0x01020304",
+			"output": "0x",
+			"calls": [
+				{
+					"type": "STATICCALL",
+					"from": "0x0000000000000000000000000000000000000002",
+					"to": "0x0000000000000000000000000000000000000003",
+					"gas": "0x10",
+					"gasUsed": "0x1",
+					"input": "0x",
+					"error": "execution reverted",
+					"revertReason": "not allowed"
+				}
+			]
+		}`)
+	})
+
+	frame, err := c.Debug().TraceCallTree(core.Hash{0x1}, &CallTracerConfig{WithLog: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, CallTracer, config["tracer"])
+	assert.Equal(t, map[string]interface{}{"withLog": true}, config["tracerConfig"])
+
+	assert.Equal(t, "CALL", frame.Type)
+	assert.Equal(t, core.Address{19: 0x2}, frame.To)
+	assert.Equal(t, big.NewInt(16), frame.Value)
+	assert.Equal(t, uint64(21000), frame.Gas)
+	assert.Equal(t, []byte{0x1, 0x2, 0x3, 0x4}, frame.Input)
+	require.Len(t, frame.Calls, 1)
+	assert.Equal(t, "not allowed", frame.Calls[0].RevertReason)
+	assert.Nil(t, frame.Calls[0].Value)
+}
+
+func TestDebug_TraceStateDiff(t *testing.T) {
+	c := newMockClient(t, func(method string, params []json.RawMessage) interface{} {
+		var config map[string]interface{}
+		require.NoError(t, json.Unmarshal(params[1], &config))
+		require.Equal(t, map[string]interface{}{"diffMode": true}, config["tracerConfig"])
+
+		return json.RawMessage(`{
+			"pre": {
+				"0x0000000000000000000000000000000000000001": {"balance": "0x100", "nonce": 1}
+			},
+			"post": {
+				"0x0000000000000000000000000000000000000001": {
+					"balance": "0x50",
+					"nonce": 2,
+					"storage": {
+						"0x0000000000000000000000000000000000000000000000000000000000000001": "0x0000000000000000000000000000000000000000000000000000000000000002"
+					}
+				}
+			}
+		}`)
+	})
+
+	diff, err := c.Debug().TraceStateDiff(core.Hash{0x1})
+	require.NoError(t, err)
+
+	addr := core.Address{19: 0x1}
+	assert.Equal(t, big.NewInt(256), diff.Pre[addr].Balance)
+	assert.Equal(t, uint64(2), diff.Post[addr].Nonce)
+	assert.Equal(t, core.Hash{31: 0x2}, diff.Post[addr].Storage[core.Hash{31: 0x1}])
+}
+
+func TestDebug_TraceCall(t *testing.T) {
+	c := newMockClient(t, func(method string, params []json.RawMessage) interface{} {
+		require.Equal(t, "debug_traceCall", method)
+		require.Equal(t, `"latest"`, string(params[1]))
+
+		var config map[string]interface{}
+		require.NoError(t, json.Unmarshal(params[2], &config))
+		require.Equal(t, "5s", config["timeout"])
+		require.Equal(t, map[string]interface{}{
+			"0x0000000000000000000000000000000000000001": map[string]interface{}{
+				"balance": "0x64",
+				"code":    "0x6080",
+			},
+		}, config["stateOverrides"])
+
+		return map[string]int{"0x27dc297e-128": 1}
+	})
+
+	config := &TraceConfig{
+		Tracer:  FourByteTracer,
+		Timeout: 5 * time.Second,
+		StateOverrides: map[core.Address]*StateOverride{
+			{19: 0x1}: {Balance: big.NewInt(100), Code: []byte{0x60, 0x80}},
+		},
+	}
+	var res map[string]int
+	require.NoError(t, c.Debug().TraceCall(&core.CallMsg{}, core.Latest, config, &res))
+	assert.Equal(t, 1, res["0x27dc297e-128"])
+}
+
+func TestDebug_TraceBlock(t *testing.T) {
+	c := newMockClient(t, func(method string, params []json.RawMessage) interface{} {
+		require.Equal(t, "debug_traceBlockByNumber", method)
+		require.Equal(t, `"0xa"`, string(params[0]))
+
+		return json.RawMessage(`[
+			{"txHash": "0x0100000000000000000000000000000000000000000000000000000000000000", "result": {"type": "CALL", "gasUsed": "0x5"}},
+			{"txHash": "0x0200000000000000000000000000000000000000000000000000000000000000", "error": "execution timeout"}
+		]`)
+	})
+
+	res, err := c.Debug().TraceBlockByNumber(10, &TraceConfig{Tracer: CallTracer})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+
+	var frame *CallFrame
+	require.NoError(t, res[0].Decode(&frame))
+	assert.Equal(t, core.Hash{0x1}, res[0].TxHash)
+	assert.Equal(t, uint64(5), frame.GasUsed)
+	assert.Equal(t, "execution timeout", res[1].Error)
+}
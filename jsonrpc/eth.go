@@ -109,6 +109,14 @@ func (e *Eth) NewBlockFilter() (string, error) {
 	return id, err
 }
 
+// NewPendingTransactionFilter creates a new filter for the transactions added
+// to the pool. The hashes of the transactions are returned by GetFilterChangesBlock.
+func (e *Eth) NewPendingTransactionFilter() (string, error) {
+	var id string
+	err := e.c.Call("eth_newPendingTransactionFilter", &id)
+	return id, err
+}
+
 // UninstallFilter uninstalls a filter
 func (e *Eth) UninstallFilter(id string) (bool, error) {
 	var res bool
@@ -138,6 +146,13 @@ func (e *Eth) GetTransactionReceipt(hash core.Hash) (*core.Receipt, error) {
 	return receipt, err
 }
 
+// GetBlockReceipts returns the receipts of all the transactions of a block.
+func (e *Eth) GetBlockReceipts(block core.BlockNumberOrHash) ([]*core.Receipt, error) {
+	var receipts []*core.Receipt
+	err := e.c.Call("eth_getBlockReceipts", &receipts, block.Location())
+	return receipts, err
+}
+
 // GetNonce returns the nonce of the account
 func (e *Eth) GetNonce(addr core.Address, blockNumber core.BlockNumberOrHash) (uint64, error) {
 	var nonce string
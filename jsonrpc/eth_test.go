@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/deep-nl/ethgo/testutil"
+	"github.com/deep-nl/ethgo/testutil/simulated"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,7 +21,7 @@ var (
 )
 
 func TestEthAccounts(t *testing.T) {
-	testutil.MultiAddr(t, func(s *testutil.TestServer, addr string) {
+	simulated.MultiAddr(t, func(s *testutil.TestServer, addr string) {
 		c, _ := NewClient(addr)
 		defer c.Close()
 
@@ -30,7 +31,7 @@ func TestEthAccounts(t *testing.T) {
 }
 
 func TestEthBlockNumber(t *testing.T) {
-	testutil.MultiAddr(t, func(s *testutil.TestServer, addr string) {
+	simulated.MultiAddr(t, func(s *testutil.TestServer, addr string) {
 		c, _ := NewClient(addr)
 		defer c.Close()
 
@@ -51,7 +52,7 @@ func TestEthBlockNumber(t *testing.T) {
 }
 
 func TestEthGetCode(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	c, _ := NewClient(s.HTTPAddr())
 
@@ -76,7 +77,7 @@ func TestEthGetCode(t *testing.T) {
 }
 
 func TestEthGetBalance(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	c, _ := NewClient(s.HTTPAddr())
 
@@ -86,11 +87,11 @@ func TestEthGetBalance(t *testing.T) {
 
 	balance, err = c.Eth().GetBalance(core.Address{}, core.Latest)
 	assert.NoError(t, err)
-	assert.Equal(t, balance, big.NewInt(0))
+	assert.Equal(t, 0, balance.Sign())
 }
 
 func TestEthGetBlockByNumber(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	c, _ := NewClient(s.HTTPAddr())
 
@@ -109,7 +110,7 @@ func TestEthGetBlockByNumber(t *testing.T) {
 }
 
 func TestEthGetBlockByHash(t *testing.T) {
-	testutil.MultiAddr(t, func(s *testutil.TestServer, addr string) {
+	simulated.MultiAddr(t, func(s *testutil.TestServer, addr string) {
 		c, _ := NewClient(addr)
 		defer c.Close()
 
@@ -126,7 +127,7 @@ func TestEthGetBlockByHash(t *testing.T) {
 }
 
 func TestEthGasPrice(t *testing.T) {
-	testutil.MultiAddr(t, func(s *testutil.TestServer, addr string) {
+	simulated.MultiAddr(t, func(s *testutil.TestServer, addr string) {
 		c, _ := NewClient(addr)
 		defer c.Close()
 
@@ -136,7 +137,7 @@ func TestEthGasPrice(t *testing.T) {
 }
 
 func TestEthSendTransaction(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	c, _ := NewClient(s.HTTPAddr())
 
@@ -146,7 +147,6 @@ func TestEthSendTransaction(t *testing.T) {
 		Gas:      testutil.DefaultGasLimit,
 		To:       &testutil.DummyAddr,
 		Value:    big.NewInt(10),
-		Nonce:    core.Local,
 	}
 	hash, err := c.Eth().SendTransaction(txn)
 	assert.NoError(t, err)
@@ -164,7 +164,7 @@ func TestEthSendTransaction(t *testing.T) {
 }
 
 func TestEth_SendRawTransaction(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	c, _ := NewClient(s.HTTPAddr())
 	//c.SetMaxConnsLimit(0)
@@ -199,7 +199,7 @@ func TestEth_SendRawTransaction(t *testing.T) {
 }
 
 func TestEthEstimateGas(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	c, _ := NewClient(s.HTTPAddr())
 
@@ -209,7 +209,7 @@ func TestEthEstimateGas(t *testing.T) {
 
 	// estimate gas to deploy the contract
 	solcContract, err := cc.Compile()
-	assert.NoError(t, err)
+	require.NoError(t, err)
 
 	input, err := hex.DecodeString(solcContract.Bin)
 	assert.NoError(t, err)
@@ -233,7 +233,7 @@ func TestEthEstimateGas(t *testing.T) {
 }
 
 func TestEthGetLogs(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	c, _ := NewClient(s.HTTPAddr())
 
@@ -272,7 +272,7 @@ func TestEthGetLogs(t *testing.T) {
 }
 
 func TestEthChainID(t *testing.T) {
-	testutil.MultiAddr(t, func(s *testutil.TestServer, addr string) {
+	simulated.MultiAddr(t, func(s *testutil.TestServer, addr string) {
 		c, _ := NewClient(addr)
 		defer c.Close()
 
@@ -283,7 +283,7 @@ func TestEthChainID(t *testing.T) {
 }
 
 func TestEthGetNonce(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	c, _ := NewClient(s.HTTPAddr())
 
@@ -306,7 +306,7 @@ func TestEthGetNonce(t *testing.T) {
 }
 
 func TestEthTransactionsInBlock(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	c, _ := NewClient(s.HTTPAddr())
 
@@ -343,7 +343,7 @@ func TestEthTransactionsInBlock(t *testing.T) {
 }
 
 func TestEthGetStorageAt(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	c, _ := NewClient(s.HTTPAddr())
 
@@ -380,9 +380,13 @@ func TestEthGetStorageAt(t *testing.T) {
 }
 
 func TestEthFeeHistory(t *testing.T) {
-	url := "https://goerli.infura.io/v3/c436c6349f034f7ba79623c7e6fe4014"
-	//c, _ := NewClient(testutil.TestInfuraEndpoint(t))
-	c, _ := NewClient(url)
+	s, _ := simulated.NewTestServer(t)
+
+	c, _ := NewClient(s.HTTPAddr())
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.ProcessBlock())
+	}
 
 	lastBlock, err := c.Eth().BlockNumber()
 	assert.NoError(t, err)
@@ -400,14 +404,15 @@ func TestEthFeeHistory(t *testing.T) {
 // --------------------------------------------------------------------------------------------------------------------
 
 func TestEth_SendRawTransaction_withNewServer(t *testing.T) {
-	s := testutil.NewTestingServer(t)
+	_, chain := simulated.NewTestServer(t)
+	s := testutil.NewTestingServer(t, chain.HTTPAddr(), chain.WSAddr())
 
 	//c := s.HttpClient()
 	//c, _ := NewClient(s.HTTPAddr())
 	//c.SetMaxConnsLimit(0)
 	//toAddr := ethgo.HexToAddress(testutil.ToAddr)
 	txn := &core.Transaction{
-		From:     s.Account(0),
+		From:     chain.Accounts()[0],
 		GasPrice: testutil.DefaultGasPrice,
 		Gas:      testutil.DefaultGasLimit,
 		To:       &testutil.DummyAddr,
@@ -423,9 +428,10 @@ func TestEth_SendRawTransaction_withNewServer(t *testing.T) {
 }
 
 func Test_MaxListenerNum(t *testing.T) {
-	for i := 0; i < 15; i++ {
-		s := testutil.NewServer()
-		s.ProcessBlockRaw()
+	_, chain := simulated.NewTestServer(t)
 
+	for i := 0; i < 15; i++ {
+		s := testutil.NewTestServer(t, chain.HTTPAddr())
+		require.NoError(t, s.ProcessBlockRaw())
 	}
 }
@@ -0,0 +1,107 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/deep-nl/ethgo/core"
+)
+
+// Miner is the namespace of the helpers to control the chain of
+// the development nodes (i.e. anvil, hardhat or ganache).
+type Miner struct {
+	c *Client
+}
+
+// Miner returns the reference to the miner namespace
+func (c *Client) Miner() *Miner {
+	return c.endpoints.m
+}
+
+// Mine mines a new block. If timestamp is not zero it is used as
+// the timestamp of the block.
+func (m *Miner) Mine(timestamp uint64) error {
+	params := []interface{}{}
+	if timestamp != 0 {
+		params = append(params, timestamp)
+	}
+	return m.call("evm_mine", params...)
+}
+
+// IncreaseTime moves forward the time of the next blocks and returns
+// the total time adjustment in seconds
+func (m *Miner) IncreaseTime(duration time.Duration) (uint64, error) {
+	var out json.RawMessage
+	if err := m.c.Call("evm_increaseTime", &out, uint64(duration/time.Second)); err != nil {
+		return 0, err
+	}
+	return parseNumberOrHex(out)
+}
+
+// Snapshot takes a snapshot of the state of the chain and
+// returns its id to revert to it with Revert
+func (m *Miner) Snapshot() (string, error) {
+	var out string
+	err := m.c.Call("evm_snapshot", &out)
+	return out, err
+}
+
+// Revert reverts the state of the chain to the snapshot. The snapshot
+// is removed and it cannot be reverted to again.
+func (m *Miner) Revert(id string) (bool, error) {
+	var out bool
+	err := m.c.Call("evm_revert", &out, id)
+	return out, err
+}
+
+// The hardhat prefix is used for the methods that modify the state
+// since anvil supports them as aliases of its own anvil methods.
+
+// SetBalance sets the balance of the account
+func (m *Miner) SetBalance(addr core.Address, balance *big.Int) error {
+	return m.call("hardhat_setBalance", addr, (*core.ArgBig)(balance))
+}
+
+// SetCode sets the code of the account
+func (m *Miner) SetCode(addr core.Address, code []byte) error {
+	return m.call("hardhat_setCode", addr, encodeToHex(code))
+}
+
+// SetStorageAt sets the value of the storage slot of the account
+func (m *Miner) SetStorageAt(addr core.Address, slot *big.Int, value core.Hash) error {
+	return m.call("hardhat_setStorageAt", addr, (*core.ArgBig)(slot), value)
+}
+
+// ImpersonateAccount allows to send transactions from the account
+// without its private key
+func (m *Miner) ImpersonateAccount(addr core.Address) error {
+	return m.call("hardhat_impersonateAccount", addr)
+}
+
+// StopImpersonatingAccount stops impersonating the account
+func (m *Miner) StopImpersonatingAccount(addr core.Address) error {
+	return m.call("hardhat_stopImpersonatingAccount", addr)
+}
+
+// call calls a method whose result is not relevant since it differs
+// between the nodes (i.e. true or null)
+func (m *Miner) call(method string, params ...interface{}) error {
+	var out interface{}
+	return m.c.Call(method, &out, params...)
+}
+
+// parseNumberOrHex parses a response that some nodes return
+// as a json number and others as an hex string
+func parseNumberOrHex(b json.RawMessage) (uint64, error) {
+	var num uint64
+	if err := json.Unmarshal(b, &num); err == nil {
+		return num, nil
+	}
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return 0, fmt.Errorf("failed to parse number %s", string(b))
+	}
+	return parseUint64orHex(str)
+}
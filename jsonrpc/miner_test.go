@@ -0,0 +1,79 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiner(t *testing.T) {
+	addr := core.Address{0x1}
+
+	calls := map[string][]json.RawMessage{}
+	c := newMockClient(t, func(method string, params []json.RawMessage) interface{} {
+		calls[method] = params
+
+		switch method {
+		case "evm_mine":
+			return "0x0"
+		case "evm_increaseTime":
+			// hardhat returns a number and other nodes an hex string
+			return 3600
+		case "evm_snapshot":
+			return "0x1"
+		case "evm_revert":
+			return true
+		case "hardhat_setBalance", "hardhat_setCode", "hardhat_setStorageAt", "hardhat_impersonateAccount":
+			return nil
+		}
+		t.Fatalf("unexpected method %s", method)
+		return nil
+	})
+
+	require.NoError(t, c.Miner().Mine(0))
+	assert.Empty(t, calls["evm_mine"])
+
+	require.NoError(t, c.Miner().Mine(1000))
+	assert.Equal(t, "1000", string(calls["evm_mine"][0]))
+
+	adjustment, err := c.Miner().IncreaseTime(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3600), adjustment)
+	assert.Equal(t, "3600", string(calls["evm_increaseTime"][0]))
+
+	id, err := c.Miner().Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, "0x1", id)
+
+	ok, err := c.Miner().Revert(id)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, c.Miner().SetBalance(addr, big.NewInt(1000)))
+	assert.Equal(t, `"0x3e8"`, string(calls["hardhat_setBalance"][1]))
+
+	require.NoError(t, c.Miner().SetCode(addr, []byte{0x60, 0x80}))
+	assert.Equal(t, `"0x6080"`, string(calls["hardhat_setCode"][1]))
+
+	require.NoError(t, c.Miner().SetStorageAt(addr, big.NewInt(1), core.Hash{0x1}))
+	assert.Equal(t, `"0x1"`, string(calls["hardhat_setStorageAt"][1]))
+	assert.Equal(t, `"`+core.Hash{0x1}.String()+`"`, string(calls["hardhat_setStorageAt"][2]))
+
+	require.NoError(t, c.Miner().ImpersonateAccount(addr))
+	assert.Equal(t, `"`+addr.String()+`"`, string(calls["hardhat_impersonateAccount"][0]))
+}
+
+func TestParseNumberOrHex(t *testing.T) {
+	for _, c := range []string{`10`, `"0xa"`, `"10"`} {
+		num, err := parseNumberOrHex(json.RawMessage(c))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(10), num)
+	}
+	_, err := parseNumberOrHex(json.RawMessage(`true`))
+	require.Error(t, err)
+}
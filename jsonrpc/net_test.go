@@ -4,11 +4,12 @@ import (
 	"testing"
 
 	"github.com/deep-nl/ethgo/testutil"
+	"github.com/deep-nl/ethgo/testutil/simulated"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestNetVersion(t *testing.T) {
-	testutil.MultiAddr(t, func(s *testutil.TestServer, addr string) {
+	simulated.MultiAddr(t, func(s *testutil.TestServer, addr string) {
 		c, _ := NewClient(addr)
 		defer c.Close()
 
@@ -18,7 +19,7 @@ func TestNetVersion(t *testing.T) {
 }
 
 func TestNetListening(t *testing.T) {
-	testutil.MultiAddr(t, func(s *testutil.TestServer, addr string) {
+	simulated.MultiAddr(t, func(s *testutil.TestServer, addr string) {
 		c, _ := NewClient(addr)
 		defer c.Close()
 
@@ -29,7 +30,7 @@ func TestNetListening(t *testing.T) {
 }
 
 func TestNetPeerCount(t *testing.T) {
-	testutil.MultiAddr(t, func(s *testutil.TestServer, addr string) {
+	simulated.MultiAddr(t, func(s *testutil.TestServer, addr string) {
 		c, _ := NewClient(addr)
 		defer c.Close()
 
@@ -0,0 +1,57 @@
+package jsonrpc
+
+import (
+	"time"
+
+	"github.com/deep-nl/ethgo/core"
+)
+
+// Personal is the personal namespace
+type Personal struct {
+	c *Client
+}
+
+// Personal returns the reference to the personal namespace
+func (c *Client) Personal() *Personal {
+	return c.endpoints.k
+}
+
+// ListAccounts returns the accounts in the keystore of the node
+func (p *Personal) ListAccounts() ([]core.Address, error) {
+	var out []core.Address
+	err := p.c.Call("personal_listAccounts", &out)
+	return out, err
+}
+
+// NewAccount creates a new account in the keystore of the node
+// encrypted with the passphrase
+func (p *Personal) NewAccount(passphrase string) (core.Address, error) {
+	var out core.Address
+	err := p.c.Call("personal_newAccount", &out, passphrase)
+	return out, err
+}
+
+// UnlockAccount unlocks the account in the keystore for the given duration.
+// A zero duration keeps the account unlocked until the node exits.
+func (p *Personal) UnlockAccount(addr core.Address, passphrase string, duration time.Duration) (bool, error) {
+	var out bool
+	err := p.c.Call("personal_unlockAccount", &out, addr, passphrase, uint64(duration/time.Second))
+	return out, err
+}
+
+// Sign signs the data with the account in the keystore using the
+// prefixed message format of eth_sign
+func (p *Personal) Sign(data []byte, addr core.Address, passphrase string) ([]byte, error) {
+	var out string
+	if err := p.c.Call("personal_sign", &out, encodeToHex(data), addr, passphrase); err != nil {
+		return nil, err
+	}
+	return parseHexBytes(out)
+}
+
+// EcRecover returns the address of the account that signed the data with Sign
+func (p *Personal) EcRecover(data []byte, signature []byte) (core.Address, error) {
+	var out core.Address
+	err := p.c.Call("personal_ecRecover", &out, encodeToHex(data), encodeToHex(signature))
+	return out, err
+}
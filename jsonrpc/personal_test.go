@@ -0,0 +1,63 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonal(t *testing.T) {
+	addr := core.Address{0x1}
+	signature := []byte{0x1, 0x2, 0x3}
+
+	c := newMockClient(t, func(method string, params []json.RawMessage) interface{} {
+		switch method {
+		case "personal_listAccounts":
+			return []core.Address{addr}
+
+		case "personal_newAccount":
+			require.Equal(t, `"pass"`, string(params[0]))
+			return addr
+
+		case "personal_unlockAccount":
+			require.Len(t, params, 3)
+			require.Equal(t, `"pass"`, string(params[1]))
+			require.Equal(t, `60`, string(params[2]))
+			return true
+
+		case "personal_sign":
+			require.Equal(t, `"0x6d7367"`, string(params[0]))
+			return "0x010203"
+
+		case "personal_ecRecover":
+			require.Equal(t, `"0x010203"`, string(params[1]))
+			return addr
+		}
+		t.Fatalf("unexpected method %s", method)
+		return nil
+	})
+
+	accounts, err := c.Personal().ListAccounts()
+	require.NoError(t, err)
+	assert.Equal(t, []core.Address{addr}, accounts)
+
+	newAddr, err := c.Personal().NewAccount("pass")
+	require.NoError(t, err)
+	assert.Equal(t, addr, newAddr)
+
+	ok, err := c.Personal().UnlockAccount(addr, "pass", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	sig, err := c.Personal().Sign([]byte("msg"), addr, "pass")
+	require.NoError(t, err)
+	assert.Equal(t, signature, sig)
+
+	signer, err := c.Personal().EcRecover([]byte("msg"), sig)
+	require.NoError(t, err)
+	assert.Equal(t, addr, signer)
+}
@@ -3,6 +3,7 @@ package jsonrpc
 import (
 	"fmt"
 
+	"github.com/deep-nl/ethgo/core"
 	"github.com/deep-nl/ethgo/jsonrpc/transport"
 )
 
@@ -13,11 +14,53 @@ func (c *Client) SubscriptionEnabled() bool {
 }
 
 // Subscribe starts a new subscription
-func (c *Client) Subscribe(method string, callback func(b []byte)) (func() error, error) {
+func (c *Client) Subscribe(method string, callback func(b []byte), params ...interface{}) (func() error, error) {
 	pub, ok := c.transport.(transport.PubSubTransport)
 	if !ok {
 		return nil, fmt.Errorf("transport does not support the subscribe method")
 	}
-	close, err := pub.Subscribe(method, callback)
+	close, err := pub.Subscribe(method, callback, params...)
 	return close, err
 }
+
+// SubscriptionEnabled returns true if the subscription endpoints are enabled
+func (e *Eth) SubscriptionEnabled() bool {
+	return e.c.SubscriptionEnabled()
+}
+
+// SubscribeLogs starts a subscription to the logs that match the filter. Logs
+// removed during a chain reorganization are notified with the Removed flag set.
+func (e *Eth) SubscribeLogs(filter *core.LogFilter, callback func(log *core.Log)) (func() error, error) {
+	return e.c.Subscribe("logs", func(b []byte) {
+		var log core.Log
+		if err := log.UnmarshalJSON(b); err != nil {
+			return
+		}
+		callback(&log)
+	}, filter)
+}
+
+// SubscribePendingTransactions starts a subscription to the transactions added to
+// the pool of the node. If full is set the nodes that support it notify the whole
+// transaction, otherwise the transaction is nil and only the hash is notified.
+func (e *Eth) SubscribePendingTransactions(full bool, callback func(hash core.Hash, txn *core.Transaction)) (func() error, error) {
+	params := []interface{}{}
+	if full {
+		params = append(params, true)
+	}
+	return e.c.Subscribe("newPendingTransactions", func(b []byte) {
+		if len(b) != 0 && b[0] == '"' {
+			var hash core.Hash
+			if err := hash.UnmarshalText(b[1 : len(b)-1]); err != nil {
+				return
+			}
+			callback(hash, nil)
+			return
+		}
+		var txn core.Transaction
+		if err := txn.UnmarshalJSON(b); err != nil {
+			return
+		}
+		callback(txn.Hash, &txn)
+	}, params...)
+}
@@ -7,11 +7,12 @@ import (
 	"time"
 
 	"github.com/deep-nl/ethgo/testutil"
+	"github.com/deep-nl/ethgo/testutil/simulated"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestSubscribeNewHead(t *testing.T) {
-	testutil.MultiAddr(t, func(s *testutil.TestServer, addr string) {
+	simulated.MultiAddr(t, func(s *testutil.TestServer, addr string) {
 		if strings.HasPrefix(addr, "http") {
 			t.Log("wrong url")
 			return
@@ -54,9 +55,6 @@ func TestSubscribeNewHead(t *testing.T) {
 			}
 		}
 
-		// 重新把addr改为http
-		s = testutil.NewTestServer(t, "http://127.0.0.1:8545")
-
 		err = s.ProcessBlockRaw()
 		assert.NoError(t, err)
 
@@ -0,0 +1,518 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/deep-nl/ethgo/core"
+)
+
+// Trace is the trace namespace of Parity/OpenEthereum, also
+// implemented by Erigon and Nethermind
+type Trace struct {
+	c *Client
+}
+
+// Trace returns the reference to the trace namespace
+func (c *Client) Trace() *Trace {
+	return c.endpoints.t
+}
+
+// TraceActionType is the type of action of a trace
+type TraceActionType string
+
+const (
+	ActionCall    TraceActionType = "call"
+	ActionCreate  TraceActionType = "create"
+	ActionSuicide TraceActionType = "suicide"
+	ActionReward  TraceActionType = "reward"
+)
+
+// TraceMode is the type of trace returned by the replay endpoints
+type TraceMode string
+
+const (
+	TraceModeTrace     TraceMode = "trace"
+	TraceModeVMTrace   TraceMode = "vmTrace"
+	TraceModeStateDiff TraceMode = "stateDiff"
+)
+
+// CallAction is the action of a call trace
+type CallAction struct {
+	CallType string
+	From     core.Address
+	To       core.Address
+	Gas      uint64
+	Input    []byte
+	Value    *big.Int
+}
+
+// CreateAction is the action of a create trace
+type CreateAction struct {
+	From           core.Address
+	Gas            uint64
+	Init           []byte
+	Value          *big.Int
+	CreationMethod string
+}
+
+// SuicideAction is the action of a suicide (selfdestruct) trace
+type SuicideAction struct {
+	Address       core.Address
+	RefundAddress core.Address
+	Balance       *big.Int
+}
+
+// RewardAction is the action of a block or uncle reward trace
+type RewardAction struct {
+	Author     core.Address
+	RewardType string
+	Value      *big.Int
+}
+
+// CallResult is the result of a call trace
+type CallResult struct {
+	GasUsed uint64
+	Output  []byte
+}
+
+// CreateResult is the result of a create trace
+type CreateResult struct {
+	Address core.Address
+	Code    []byte
+	GasUsed uint64
+}
+
+// LocalizedTrace is a trace of the trace namespace. Only the action and the
+// result of its type are set. The location fields of the block and the
+// transaction are not set in the traces returned by the replay endpoints.
+type LocalizedTrace struct {
+	Type TraceActionType
+
+	Call    *CallAction
+	Create  *CreateAction
+	Suicide *SuicideAction
+	Reward  *RewardAction
+
+	CallResult   *CallResult
+	CreateResult *CreateResult
+
+	// Error is the error of the execution (i.e. Reverted)
+	Error string
+
+	Subtraces    uint64
+	TraceAddress []uint64
+
+	BlockHash           core.Hash
+	BlockNumber         uint64
+	TransactionHash     *core.Hash
+	TransactionPosition *uint64
+}
+
+// UnmarshalJSON implements the unmarshal interface
+func (l *LocalizedTrace) UnmarshalJSON(data []byte) error {
+	var trace struct {
+		Type                TraceActionType `json:"type"`
+		Action              json.RawMessage `json:"action"`
+		Result              json.RawMessage `json:"result"`
+		Error               string          `json:"error"`
+		Subtraces           uint64          `json:"subtraces"`
+		TraceAddress        []uint64        `json:"traceAddress"`
+		BlockHash           core.Hash       `json:"blockHash"`
+		BlockNumber         uint64          `json:"blockNumber"`
+		TransactionHash     *core.Hash      `json:"transactionHash"`
+		TransactionPosition *uint64         `json:"transactionPosition"`
+	}
+	if err := json.Unmarshal(data, &trace); err != nil {
+		return err
+	}
+	l.Type = trace.Type
+	l.Error = trace.Error
+	l.Subtraces = trace.Subtraces
+	l.TraceAddress = trace.TraceAddress
+	l.BlockHash = trace.BlockHash
+	l.BlockNumber = trace.BlockNumber
+	l.TransactionHash = trace.TransactionHash
+	l.TransactionPosition = trace.TransactionPosition
+
+	hasResult := len(trace.Result) != 0 && string(trace.Result) != "null"
+
+	var action struct {
+		CallType       string         `json:"callType"`
+		From           core.Address   `json:"from"`
+		To             core.Address   `json:"to"`
+		Gas            core.ArgUint64 `json:"gas"`
+		Input          core.ArgBytes  `json:"input"`
+		Init           core.ArgBytes  `json:"init"`
+		Value          *core.ArgBig   `json:"value"`
+		CreationMethod string         `json:"creationMethod"`
+		Address        core.Address   `json:"address"`
+		RefundAddress  core.Address   `json:"refundAddress"`
+		Balance        *core.ArgBig   `json:"balance"`
+		Author         core.Address   `json:"author"`
+		RewardType     string         `json:"rewardType"`
+	}
+	if err := json.Unmarshal(trace.Action, &action); err != nil {
+		return err
+	}
+	var result struct {
+		GasUsed core.ArgUint64 `json:"gasUsed"`
+		Output  core.ArgBytes  `json:"output"`
+		Address core.Address   `json:"address"`
+		Code    core.ArgBytes  `json:"code"`
+	}
+	if hasResult {
+		if err := json.Unmarshal(trace.Result, &result); err != nil {
+			return err
+		}
+	}
+
+	switch trace.Type {
+	case ActionCall:
+		l.Call = &CallAction{
+			CallType: action.CallType,
+			From:     action.From,
+			To:       action.To,
+			Gas:      uint64(action.Gas),
+			Input:    action.Input,
+			Value:    argBig(action.Value),
+		}
+		if hasResult {
+			l.CallResult = &CallResult{
+				GasUsed: uint64(result.GasUsed),
+				Output:  result.Output,
+			}
+		}
+
+	case ActionCreate:
+		l.Create = &CreateAction{
+			From:           action.From,
+			Gas:            uint64(action.Gas),
+			Init:           action.Init,
+			Value:          argBig(action.Value),
+			CreationMethod: action.CreationMethod,
+		}
+		if hasResult {
+			l.CreateResult = &CreateResult{
+				Address: result.Address,
+				Code:    result.Code,
+				GasUsed: uint64(result.GasUsed),
+			}
+		}
+
+	case ActionSuicide:
+		l.Suicide = &SuicideAction{
+			Address:       action.Address,
+			RefundAddress: action.RefundAddress,
+			Balance:       argBig(action.Balance),
+		}
+
+	case ActionReward:
+		l.Reward = &RewardAction{
+			Author:     action.Author,
+			RewardType: action.RewardType,
+			Value:      argBig(action.Value),
+		}
+
+	default:
+		return fmt.Errorf("unknown trace type '%s'", trace.Type)
+	}
+	return nil
+}
+
+func argBig(b *core.ArgBig) *big.Int {
+	if b == nil {
+		return nil
+	}
+	return (*big.Int)(b)
+}
+
+// DiffKind is the kind of change of a field in a state diff
+type DiffKind string
+
+const (
+	// DiffSame is a field that did not change
+	DiffSame DiffKind = "="
+
+	// DiffBorn is a field of an account created
+	DiffBorn DiffKind = "+"
+
+	// DiffDied is a field of an account deleted
+	DiffDied DiffKind = "-"
+
+	// DiffChanged is a field that changed
+	DiffChanged DiffKind = "*"
+)
+
+// Diff is the change of a field in a state diff. From is not set for
+// DiffBorn and To is not set for DiffDied.
+type Diff struct {
+	Kind DiffKind
+	From []byte
+	To   []byte
+}
+
+// FromBig returns the value before the change as a number
+func (d *Diff) FromBig() *big.Int {
+	return new(big.Int).SetBytes(d.From)
+}
+
+// ToBig returns the value after the change as a number
+func (d *Diff) ToBig() *big.Int {
+	return new(big.Int).SetBytes(d.To)
+}
+
+// UnmarshalJSON implements the unmarshal interface
+func (d *Diff) UnmarshalJSON(data []byte) error {
+	var same string
+	if err := json.Unmarshal(data, &same); err == nil {
+		if DiffKind(same) != DiffSame {
+			return fmt.Errorf("unknown diff '%s'", same)
+		}
+		d.Kind = DiffSame
+		return nil
+	}
+
+	var diff map[DiffKind]json.RawMessage
+	if err := json.Unmarshal(data, &diff); err != nil {
+		return err
+	}
+	if len(diff) != 1 {
+		return fmt.Errorf("expected one diff but found %d", len(diff))
+	}
+	for kind, val := range diff {
+		d.Kind = kind
+
+		switch kind {
+		case DiffBorn, DiffDied:
+			var b core.ArgBytes
+			if err := json.Unmarshal(val, &b); err != nil {
+				return err
+			}
+			if kind == DiffBorn {
+				d.To = b
+			} else {
+				d.From = b
+			}
+
+		case DiffChanged:
+			var change struct {
+				From core.ArgBytes `json:"from"`
+				To   core.ArgBytes `json:"to"`
+			}
+			if err := json.Unmarshal(val, &change); err != nil {
+				return err
+			}
+			d.From = change.From
+			d.To = change.To
+
+		default:
+			return fmt.Errorf("unknown diff '%s'", kind)
+		}
+	}
+	return nil
+}
+
+// AccountDiff are the changes of an account in a state diff
+type AccountDiff struct {
+	Balance *Diff               `json:"balance"`
+	Nonce   *Diff               `json:"nonce"`
+	Code    *Diff               `json:"code"`
+	Storage map[core.Hash]*Diff `json:"storage"`
+}
+
+// VMTrace is the trace of the execution of the code of a call
+type VMTrace struct {
+	Code core.ArgBytes  `json:"code"`
+	Ops  []*VMOperation `json:"ops"`
+}
+
+// VMOperation is an instruction executed in a VMTrace
+type VMOperation struct {
+	Pc   uint64 `json:"pc"`
+	Cost uint64 `json:"cost"`
+
+	// Ex is the result of the execution, nil if it failed
+	Ex *VMExecuted `json:"ex"`
+
+	// Sub is the trace of the call or create of the instruction
+	Sub *VMTrace `json:"sub"`
+}
+
+// VMExecuted are the changes made by an instruction
+type VMExecuted struct {
+	Used  uint64
+	Push  []*big.Int
+	Mem   *VMMemoryDiff
+	Store *VMStorageDiff
+}
+
+// VMMemoryDiff is a write to the memory
+type VMMemoryDiff struct {
+	Off  uint64
+	Data []byte
+}
+
+// VMStorageDiff is a write to the storage
+type VMStorageDiff struct {
+	Key *big.Int
+	Val *big.Int
+}
+
+// UnmarshalJSON implements the unmarshal interface
+func (v *VMExecuted) UnmarshalJSON(data []byte) error {
+	var ex struct {
+		Used uint64         `json:"used"`
+		Push []*core.ArgBig `json:"push"`
+		Mem  *struct {
+			Off  uint64        `json:"off"`
+			Data core.ArgBytes `json:"data"`
+		} `json:"mem"`
+		Store *struct {
+			Key *core.ArgBig `json:"key"`
+			Val *core.ArgBig `json:"val"`
+		} `json:"store"`
+	}
+	if err := json.Unmarshal(data, &ex); err != nil {
+		return err
+	}
+	v.Used = ex.Used
+	v.Push = make([]*big.Int, len(ex.Push))
+	for indx, p := range ex.Push {
+		v.Push[indx] = argBig(p)
+	}
+	if ex.Mem != nil {
+		v.Mem = &VMMemoryDiff{Off: ex.Mem.Off, Data: ex.Mem.Data}
+	}
+	if ex.Store != nil {
+		v.Store = &VMStorageDiff{Key: argBig(ex.Store.Key), Val: argBig(ex.Store.Val)}
+	}
+	return nil
+}
+
+// TraceResults is the result of the replay endpoints. Only the
+// fields of the trace modes requested are set.
+type TraceResults struct {
+	Output    core.ArgBytes                 `json:"output"`
+	Trace     []*LocalizedTrace             `json:"trace"`
+	StateDiff map[core.Address]*AccountDiff `json:"stateDiff"`
+	VMTrace   *VMTrace                      `json:"vmTrace"`
+}
+
+// TraceFilter is the filter of the traces for trace_filter
+type TraceFilter struct {
+	FromBlock   *core.BlockNumber
+	ToBlock     *core.BlockNumber
+	FromAddress []core.Address
+	ToAddress   []core.Address
+
+	// After is the number of traces to skip
+	After uint64
+
+	// Count is the maximum number of traces to return
+	Count uint64
+}
+
+// MarshalJSON implements the Marshal interface.
+func (t *TraceFilter) MarshalJSON() ([]byte, error) {
+	obj := map[string]interface{}{}
+	if t.FromBlock != nil {
+		obj["fromBlock"] = t.FromBlock.String()
+	}
+	if t.ToBlock != nil {
+		obj["toBlock"] = t.ToBlock.String()
+	}
+	if len(t.FromAddress) != 0 {
+		obj["fromAddress"] = t.FromAddress
+	}
+	if len(t.ToAddress) != 0 {
+		obj["toAddress"] = t.ToAddress
+	}
+	if t.After != 0 {
+		obj["after"] = t.After
+	}
+	if t.Count != 0 {
+		obj["count"] = t.Count
+	}
+	return json.Marshal(obj)
+}
+
+// Transaction returns the traces of the transaction
+func (t *Trace) Transaction(hash core.Hash) ([]*LocalizedTrace, error) {
+	var res []*LocalizedTrace
+	err := t.c.Call("trace_transaction", &res, hash)
+	return res, err
+}
+
+// Block returns the traces of the transactions and the rewards in the block
+func (t *Trace) Block(block core.BlockNumber) ([]*LocalizedTrace, error) {
+	var res []*LocalizedTrace
+	err := t.c.Call("trace_block", &res, block.String())
+	return res, err
+}
+
+// Filter returns the traces that match the filter
+func (t *Trace) Filter(filter *TraceFilter) ([]*LocalizedTrace, error) {
+	var res []*LocalizedTrace
+	err := t.c.Call("trace_filter", &res, filter)
+	return res, err
+}
+
+// FilterPaged queries the traces that match the filter in pages of the given
+// size and calls the handler with each page. It starts after the traces skipped
+// by the After field of the filter and stops once it reaches the Count field of
+// the filter, if set, or there are no more traces.
+func (t *Trace) FilterPaged(filter *TraceFilter, pageSize uint64, handler func(traces []*LocalizedTrace) error) error {
+	if pageSize == 0 {
+		return fmt.Errorf("page size cannot be zero")
+	}
+	page := *filter
+
+	total := uint64(0)
+	for filter.Count == 0 || total < filter.Count {
+		page.After = filter.After + total
+		page.Count = pageSize
+		if filter.Count != 0 && filter.Count-total < pageSize {
+			page.Count = filter.Count - total
+		}
+
+		traces, err := t.Filter(&page)
+		if err != nil {
+			return err
+		}
+		if len(traces) == 0 {
+			return nil
+		}
+		if err := handler(traces); err != nil {
+			return err
+		}
+		total += uint64(len(traces))
+
+		if uint64(len(traces)) < page.Count {
+			return nil
+		}
+	}
+	return nil
+}
+
+// ReplayTransaction replays the transaction and returns the trace modes requested
+func (t *Trace) ReplayTransaction(hash core.Hash, modes ...TraceMode) (*TraceResults, error) {
+	var res *TraceResults
+	err := t.c.Call("trace_replayTransaction", &res, hash, traceModes(modes))
+	return res, err
+}
+
+// Call executes a call on top of the block and returns the trace modes requested
+func (t *Trace) Call(msg *core.CallMsg, block core.BlockNumber, modes ...TraceMode) (*TraceResults, error) {
+	var res *TraceResults
+	err := t.c.Call("trace_call", &res, msg, traceModes(modes), block.String())
+	return res, err
+}
+
+func traceModes(modes []TraceMode) []TraceMode {
+	if len(modes) == 0 {
+		return []TraceMode{TraceModeTrace}
+	}
+	return modes
+}
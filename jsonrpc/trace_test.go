@@ -0,0 +1,171 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrace_Block(t *testing.T) {
+	c := newMockClient(t, func(method string, params []json.RawMessage) interface{} {
+		require.Equal(t, "trace_block", method)
+		require.Equal(t, `"0x1"`, string(params[0]))
+
+		return json.RawMessage(`[
+			{
+				"action": {"callType": "call", "from": "0x0000000000000000000000000000000000000001", "to": "0x0000000000000000000000000000000000000002", "gas": "0x5208", "input": "0x01", "value": "0xa"},
+				"blockHash": "0x0100000000000000000000000000000000000000000000000000000000000000",
+				"blockNumber": 1,
+				"result": {"gasUsed": "0x10", "output": "0x02"},
+				"subtraces": 1,
+				"traceAddress": [],
+				"transactionHash": "0x0200000000000000000000000000000000000000000000000000000000000000",
+				"transactionPosition": 0,
+				"type": "call"
+			},
+			{
+				"action": {"from": "0x0000000000000000000000000000000000000002", "gas": "0x100", "init": "0x6080", "value": "0x0"},
+				"blockNumber": 1,
+				"error": "Reverted",
+				"result": null,
+				"subtraces": 0,
+				"traceAddress": [0],
+				"type": "create"
+			},
+			{
+				"action": {"address": "0x0000000000000000000000000000000000000003", "refundAddress": "0x0000000000000000000000000000000000000001", "balance": "0x5"},
+				"traceAddress": [1],
+				"type": "suicide"
+			},
+			{
+				"action": {"author": "0x0000000000000000000000000000000000000004", "rewardType": "block", "value": "0x1bc16d674ec80000"},
+				"blockNumber": 1,
+				"traceAddress": [],
+				"type": "reward"
+			}
+		]`)
+	})
+
+	traces, err := c.Trace().Block(1)
+	require.NoError(t, err)
+	require.Len(t, traces, 4)
+
+	call := traces[0]
+	assert.Equal(t, ActionCall, call.Type)
+	assert.Equal(t, "call", call.Call.CallType)
+	assert.Equal(t, uint64(21000), call.Call.Gas)
+	assert.Equal(t, big.NewInt(10), call.Call.Value)
+	assert.Equal(t, []byte{0x2}, call.CallResult.Output)
+	assert.Equal(t, core.Hash{0x2}, *call.TransactionHash)
+
+	create := traces[1]
+	assert.Equal(t, []byte{0x60, 0x80}, create.Create.Init)
+	assert.Nil(t, create.CreateResult)
+	assert.Equal(t, "Reverted", create.Error)
+	assert.Equal(t, []uint64{0}, create.TraceAddress)
+
+	assert.Equal(t, big.NewInt(5), traces[2].Suicide.Balance)
+	assert.Equal(t, "block", traces[3].Reward.RewardType)
+	assert.Nil(t, traces[3].Call)
+}
+
+func TestTrace_ReplayTransaction(t *testing.T) {
+	c := newMockClient(t, func(method string, params []json.RawMessage) interface{} {
+		require.Equal(t, "trace_replayTransaction", method)
+		require.Equal(t, `["stateDiff","vmTrace"]`, string(params[1]))
+
+		return json.RawMessage(`{
+			"output": "0x",
+			"trace": [],
+			"stateDiff": {
+				"0x0000000000000000000000000000000000000001": {
+					"balance": {"*": {"from": "0x10", "to": "0x5"}},
+					"code": "=",
+					"nonce": {"+": "0x1"},
+					"storage": {
+						"0x0000000000000000000000000000000000000000000000000000000000000001": {"-": "0x0000000000000000000000000000000000000000000000000000000000000002"}
+					}
+				}
+			},
+			"vmTrace": {
+				"code": "0x6080",
+				"ops": [
+					{"cost": 3, "pc": 0, "ex": {"mem": null, "push": ["0x80"], "store": null, "used": 100}, "sub": null},
+					{"cost": 20000, "pc": 2, "ex": {"mem": {"off": 64, "data": "0x01"}, "push": [], "store": {"key": "0x0", "val": "0x1"}, "used": 80000}, "sub": {"code": "0x", "ops": []}}
+				]
+			}
+		}`)
+	})
+
+	res, err := c.Trace().ReplayTransaction(core.Hash{0x1}, TraceModeStateDiff, TraceModeVMTrace)
+	require.NoError(t, err)
+
+	diff := res.StateDiff[core.Address{19: 0x1}]
+	assert.Equal(t, DiffChanged, diff.Balance.Kind)
+	assert.Equal(t, big.NewInt(16), diff.Balance.FromBig())
+	assert.Equal(t, big.NewInt(5), diff.Balance.ToBig())
+	assert.Equal(t, DiffSame, diff.Code.Kind)
+	assert.Equal(t, DiffBorn, diff.Nonce.Kind)
+	assert.Equal(t, []byte{0x1}, diff.Nonce.To)
+
+	slot := diff.Storage[core.Hash{31: 0x1}]
+	assert.Equal(t, DiffDied, slot.Kind)
+	assert.Equal(t, big.NewInt(2), slot.FromBig())
+
+	require.Len(t, res.VMTrace.Ops, 2)
+	assert.Equal(t, []*big.Int{big.NewInt(128)}, res.VMTrace.Ops[0].Ex.Push)
+	assert.Nil(t, res.VMTrace.Ops[0].Ex.Store)
+	assert.Equal(t, uint64(64), res.VMTrace.Ops[1].Ex.Mem.Off)
+	assert.Equal(t, big.NewInt(1), res.VMTrace.Ops[1].Ex.Store.Val)
+	assert.NotNil(t, res.VMTrace.Ops[1].Sub)
+}
+
+func TestTrace_FilterPaged(t *testing.T) {
+	pages := []string{}
+
+	c := newMockClient(t, func(method string, params []json.RawMessage) interface{} {
+		require.Equal(t, "trace_filter", method)
+
+		var filter struct {
+			FromAddress []core.Address `json:"fromAddress"`
+			After       uint64         `json:"after"`
+			Count       uint64         `json:"count"`
+		}
+		require.NoError(t, json.Unmarshal(params[0], &filter))
+		require.Len(t, filter.FromAddress, 1)
+		pages = append(pages, fmt.Sprintf("%d-%d", filter.After, filter.Count))
+
+		// there are 5 traces in total
+		res := []json.RawMessage{}
+		for i := filter.After; i < filter.After+filter.Count && i < 5; i++ {
+			res = append(res, json.RawMessage(`{"action": {}, "type": "call"}`))
+		}
+		return res
+	})
+
+	filter := &TraceFilter{
+		FromAddress: []core.Address{{0x1}},
+	}
+
+	num := 0
+	handler := func(traces []*LocalizedTrace) error {
+		num += len(traces)
+		return nil
+	}
+	require.NoError(t, c.Trace().FilterPaged(filter, 2, handler))
+	assert.Equal(t, 5, num)
+	assert.Equal(t, []string{"0-2", "2-2", "4-2"}, pages)
+
+	// limit the number of traces
+	num, pages = 0, pages[:0]
+	filter.After, filter.Count = 1, 3
+
+	require.NoError(t, c.Trace().FilterPaged(filter, 2, handler))
+	assert.Equal(t, 3, num)
+	assert.Equal(t, []string{"1-2", "3-1"}, pages)
+}
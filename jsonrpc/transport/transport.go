@@ -19,8 +19,8 @@ type Transport interface {
 
 // PubSubTransport is a transport that allows subscriptions
 type PubSubTransport interface {
-	// Subscribe starts a subscription to a new event
-	Subscribe(method string, callback func(b []byte)) (func() error, error)
+	// Subscribe starts a subscription to a new event with optional parameters
+	Subscribe(method string, callback func(b []byte), params ...interface{}) (func() error, error)
 }
 
 const (
@@ -0,0 +1,80 @@
+package jsonrpc
+
+import (
+	"github.com/deep-nl/ethgo/core"
+)
+
+// Txpool is the txpool namespace
+type Txpool struct {
+	c *Client
+}
+
+// Txpool returns the reference to the txpool namespace
+func (c *Client) Txpool() *Txpool {
+	return c.endpoints.p
+}
+
+// TxpoolContent are the transactions in the pool indexed by sender and nonce
+type TxpoolContent struct {
+	// Pending are the transactions ready to be included in a block
+	Pending map[core.Address]map[uint64]*core.Transaction `json:"pending"`
+
+	// Queued are the transactions with a gap in the nonce
+	Queued map[core.Address]map[uint64]*core.Transaction `json:"queued"`
+}
+
+// TxpoolContentFrom are the transactions in the pool of a sender indexed by nonce
+type TxpoolContentFrom struct {
+	Pending map[uint64]*core.Transaction `json:"pending"`
+	Queued  map[uint64]*core.Transaction `json:"queued"`
+}
+
+// TxpoolStatus is the number of transactions in the pool
+type TxpoolStatus struct {
+	Pending uint64
+	Queued  uint64
+}
+
+// TxpoolInspect is a summary of the transactions in the pool indexed by sender
+// and nonce (i.e. 0x...: 1 wei + 21000 gas × 1000000000 wei)
+type TxpoolInspect struct {
+	Pending map[core.Address]map[uint64]string `json:"pending"`
+	Queued  map[core.Address]map[uint64]string `json:"queued"`
+}
+
+// Content returns the transactions in the pool
+func (t *Txpool) Content() (*TxpoolContent, error) {
+	var res *TxpoolContent
+	err := t.c.Call("txpool_content", &res)
+	return res, err
+}
+
+// ContentFrom returns the transactions in the pool sent by the address
+func (t *Txpool) ContentFrom(addr core.Address) (*TxpoolContentFrom, error) {
+	var res *TxpoolContentFrom
+	err := t.c.Call("txpool_contentFrom", &res, addr)
+	return res, err
+}
+
+// Status returns the number of transactions in the pool
+func (t *Txpool) Status() (*TxpoolStatus, error) {
+	var out struct {
+		Pending core.ArgUint64 `json:"pending"`
+		Queued  core.ArgUint64 `json:"queued"`
+	}
+	if err := t.c.Call("txpool_status", &out); err != nil {
+		return nil, err
+	}
+	res := &TxpoolStatus{
+		Pending: uint64(out.Pending),
+		Queued:  uint64(out.Queued),
+	}
+	return res, nil
+}
+
+// Inspect returns a summary of the transactions in the pool
+func (t *Txpool) Inspect() (*TxpoolInspect, error) {
+	var res *TxpoolInspect
+	err := t.c.Call("txpool_inspect", &res)
+	return res, err
+}
@@ -0,0 +1,104 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxpool_Content(t *testing.T) {
+	from := core.Address{0x1}
+	to := core.Address{0x2}
+
+	txn := &core.Transaction{
+		Hash:     core.Hash{0x3},
+		From:     from,
+		To:       &to,
+		Nonce:    5,
+		Gas:      21000,
+		GasPrice: 1,
+		Value:    big.NewInt(10),
+		Input:    []byte{0x1},
+		V:        []byte{0x1},
+		R:        []byte{0x1},
+		S:        []byte{0x1},
+	}
+
+	c := newMockClient(t, func(method string, params []json.RawMessage) interface{} {
+		switch method {
+		case "txpool_content":
+			return map[string]interface{}{
+				"pending": map[string]interface{}{
+					from.String(): map[string]interface{}{
+						"5": txn,
+					},
+				},
+				"queued": map[string]interface{}{},
+			}
+
+		case "txpool_contentFrom":
+			var addr core.Address
+			require.NoError(t, json.Unmarshal(params[0], &addr))
+			require.Equal(t, from, addr)
+
+			return map[string]interface{}{
+				"pending": map[string]interface{}{},
+				"queued": map[string]interface{}{
+					"5": txn,
+				},
+			}
+		}
+		t.Fatalf("unexpected method %s", method)
+		return nil
+	})
+
+	content, err := c.Txpool().Content()
+	require.NoError(t, err)
+	assert.Empty(t, content.Queued)
+	assert.Equal(t, txn.Hash, content.Pending[from][5].Hash)
+	assert.Equal(t, to, *content.Pending[from][5].To)
+
+	contentFrom, err := c.Txpool().ContentFrom(from)
+	require.NoError(t, err)
+	assert.Empty(t, contentFrom.Pending)
+	assert.Equal(t, uint64(5), contentFrom.Queued[5].Nonce)
+}
+
+func TestTxpool_StatusInspect(t *testing.T) {
+	from := core.Address{0x1}
+
+	c := newMockClient(t, func(method string, params []json.RawMessage) interface{} {
+		switch method {
+		case "txpool_status":
+			return map[string]interface{}{
+				"pending": "0xa",
+				"queued":  "0x1",
+			}
+
+		case "txpool_inspect":
+			return map[string]interface{}{
+				"pending": map[string]interface{}{
+					from.String(): map[string]interface{}{
+						"0": "0x0000000000000000000000000000000000000002: 1 wei + 21000 gas × 1 wei",
+					},
+				},
+				"queued": map[string]interface{}{},
+			}
+		}
+		t.Fatalf("unexpected method %s", method)
+		return nil
+	})
+
+	status, err := c.Txpool().Status()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), status.Pending)
+	assert.Equal(t, uint64(1), status.Queued)
+
+	inspect, err := c.Txpool().Inspect()
+	require.NoError(t, err)
+	assert.Equal(t, "0x0000000000000000000000000000000000000002: 1 wei + 21000 gas × 1 wei", inspect.Pending[from][0])
+}
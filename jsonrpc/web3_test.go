@@ -4,12 +4,13 @@ import (
 	"testing"
 
 	"github.com/deep-nl/ethgo/testutil"
+	"github.com/deep-nl/ethgo/testutil/simulated"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/sha3"
 )
 
 func TestWeb3ClientVersion(t *testing.T) {
-	testutil.MultiAddr(t, func(s *testutil.TestServer, addr string) {
+	simulated.MultiAddr(t, func(s *testutil.TestServer, addr string) {
 		c, _ := NewClient(addr)
 		defer c.Close()
 
@@ -19,7 +20,7 @@ func TestWeb3ClientVersion(t *testing.T) {
 }
 
 func TestWeb3Sha3(t *testing.T) {
-	testutil.MultiAddr(t, func(s *testutil.TestServer, addr string) {
+	simulated.MultiAddr(t, func(s *testutil.TestServer, addr string) {
 		c, _ := NewClient(addr)
 		defer c.Close()
 
@@ -0,0 +1,269 @@
+package mempool
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/jsonrpc"
+)
+
+// Transaction is a pending transaction with its calldata decoded
+type Transaction struct {
+	*core.Transaction
+
+	// Method is the method of the abi called, nil if there is no
+	// abi or the selector is not found
+	Method *abi.Method
+
+	// Args are the decoded inputs of the method
+	Args map[string]interface{}
+}
+
+type Config struct {
+	To           []core.Address
+	From         []core.Address
+	Selectors    [][]byte
+	ABI          *abi.ABI
+	HashOnly     bool
+	PollInterval time.Duration
+}
+
+type Option func(*Config)
+
+// WithTo only notifies the transactions sent to any of the addresses
+func WithTo(addrs ...core.Address) Option {
+	return func(c *Config) {
+		c.To = append(c.To, addrs...)
+	}
+}
+
+// WithFrom only notifies the transactions sent by any of the addresses
+func WithFrom(addrs ...core.Address) Option {
+	return func(c *Config) {
+		c.From = append(c.From, addrs...)
+	}
+}
+
+// WithSelectors only notifies the transactions that call any of the selectors
+func WithSelectors(selectors ...[]byte) Option {
+	return func(c *Config) {
+		c.Selectors = append(c.Selectors, selectors...)
+	}
+}
+
+// WithABI decodes the calldata of the transactions with the methods of the abi
+func WithABI(abi *abi.ABI) Option {
+	return func(c *Config) {
+		c.ABI = abi
+	}
+}
+
+// WithHashOnly subscribes only to the hashes of the pending transactions and
+// fetches them, for nodes that do not support the full transaction mode
+func WithHashOnly() Option {
+	return func(c *Config) {
+		c.HashOnly = true
+	}
+}
+
+// WithPollInterval sets the interval to poll for pending transactions when
+// the json-rpc transport does not support subscriptions
+func WithPollInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.PollInterval = interval
+	}
+}
+
+// Watcher notifies the transactions added to the pool of the node
+type Watcher struct {
+	config *Config
+	client *jsonrpc.Eth
+}
+
+// NewWatcher creates a new Watcher
+func NewWatcher(client *jsonrpc.Eth, opts ...Option) *Watcher {
+	config := &Config{
+		PollInterval: time.Second,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	w := &Watcher{
+		config: config,
+		client: client,
+	}
+	return w
+}
+
+// Watch sends to the channel the pending transactions that match the filters.
+// It uses the newPendingTransactions subscription if the transport supports it
+// and polls a pending transaction filter otherwise. It returns a function to
+// stop watching, any transaction blocked on the channel is dropped once it is called.
+func (w *Watcher) Watch(ch chan<- *Transaction) (func() error, error) {
+	doneCh := make(chan struct{})
+	handler := func(hash core.Hash, txn *core.Transaction) {
+		if txn = w.fetch(hash, txn); txn == nil {
+			return
+		}
+		if res, ok := w.process(txn); ok {
+			select {
+			case ch <- res:
+			case <-doneCh:
+			}
+		}
+	}
+
+	closeFn, err := w.watch(handler)
+	if err != nil {
+		return nil, err
+	}
+	var closeOnce sync.Once
+	return func() error {
+		closeOnce.Do(func() {
+			close(doneCh)
+		})
+		return closeFn()
+	}, nil
+}
+
+func (w *Watcher) watch(handler func(hash core.Hash, txn *core.Transaction)) (func() error, error) {
+	if !w.client.SubscriptionEnabled() {
+		return w.poll(handler)
+	}
+	if !w.config.HashOnly {
+		if closeFn, err := w.client.SubscribePendingTransactions(true, handler); err == nil {
+			return closeFn, nil
+		}
+		// the node does not support the full transaction mode
+	}
+	return w.client.SubscribePendingTransactions(false, handler)
+}
+
+// fetch returns the transaction of the hash if it was not notified by the node
+func (w *Watcher) fetch(hash core.Hash, txn *core.Transaction) *core.Transaction {
+	if txn != nil {
+		return txn
+	}
+	txn, err := w.client.GetTransactionByHash(hash)
+	if err != nil || txn == nil {
+		// the transaction has been dropped from the pool
+		return nil
+	}
+	return txn
+}
+
+// process filters and decodes the transaction
+func (w *Watcher) process(txn *core.Transaction) (*Transaction, bool) {
+	if len(w.config.To) != 0 {
+		if txn.To == nil || !containsAddr(w.config.To, *txn.To) {
+			return nil, false
+		}
+	}
+	if len(w.config.From) != 0 && !containsAddr(w.config.From, txn.From) {
+		return nil, false
+	}
+	if len(w.config.Selectors) != 0 {
+		found := false
+		for _, selector := range w.config.Selectors {
+			if len(txn.Input) >= 4 && bytes.Equal(txn.Input[:4], selector) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+
+	res := &Transaction{
+		Transaction: txn,
+	}
+	if w.config.ABI != nil && len(txn.Input) >= 4 {
+		for _, method := range w.config.ABI.Methods {
+			if !bytes.Equal(method.ID(), txn.Input[:4]) {
+				continue
+			}
+			if args, err := abi.Decode(method.Inputs, txn.Input[4:]); err == nil {
+				res.Method = method
+				res.Args = args.(map[string]interface{})
+			}
+			break
+		}
+	}
+	return res, true
+}
+
+func containsAddr(addrs []core.Address, addr core.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// poll watches for pending transactions with the eth_newPendingTransactionFilter
+// and eth_getFilterChanges endpoints
+func (w *Watcher) poll(handler func(hash core.Hash, txn *core.Transaction)) (func() error, error) {
+	id, err := w.client.NewPendingTransactionFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	closeCh := make(chan struct{})
+	doneCh := make(chan struct{})
+
+	go func() {
+		defer close(doneCh)
+
+		ticker := time.NewTicker(w.config.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-closeCh:
+				return
+			}
+
+			hashes, err := w.client.GetFilterChangesBlock(id)
+			if err != nil {
+				// the node might have dropped the filter after
+				// some inactivity, install it again.
+				if newID, err := w.client.NewPendingTransactionFilter(); err == nil {
+					id = newID
+				}
+				continue
+			}
+			for _, hash := range hashes {
+				select {
+				case <-closeCh:
+					return
+				default:
+				}
+				handler(hash, nil)
+			}
+		}
+	}()
+
+	var once sync.Once
+	closeFn := func() error {
+		closed := false
+		once.Do(func() {
+			close(closeCh)
+			closed = true
+		})
+		if !closed {
+			return fmt.Errorf("watcher already closed")
+		}
+		<-doneCh
+
+		_, err := w.client.UninstallFilter(id)
+		return err
+	}
+	return closeFn, nil
+}
@@ -0,0 +1,251 @@
+package mempool
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/jsonrpc"
+	"github.com/deep-nl/ethgo/jsonrpc/codec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockClient(t *testing.T, handler func(method string, params []json.RawMessage) interface{}) *jsonrpc.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req codec.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var params []json.RawMessage
+		require.NoError(t, json.Unmarshal(req.Params, &params))
+
+		result, err := json.Marshal(handler(req.Method, params))
+		require.NoError(t, err)
+
+		require.NoError(t, json.NewEncoder(w).Encode(&codec.Response{ID: req.ID, Result: result}))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := jsonrpc.NewClient(srv.URL)
+	require.NoError(t, err)
+	return c
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	token := abi.MustNewABI(`[{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}]`)
+	tokenAddr := core.Address{0x1}
+	otherAddr := core.Address{0x2}
+
+	input, err := token.GetMethod("transfer").Encode(map[string]interface{}{
+		"to":     otherAddr,
+		"amount": big.NewInt(100),
+	})
+	require.NoError(t, err)
+
+	newTxn := func(hash core.Hash, to core.Address, input []byte) *core.Transaction {
+		return &core.Transaction{
+			Hash:     hash,
+			To:       &to,
+			Input:    input,
+			Nonce:    1,
+			Gas:      21000,
+			GasPrice: 1,
+			Value:    big.NewInt(0),
+			V:        []byte{0x1},
+			R:        []byte{0x1},
+			S:        []byte{0x1},
+		}
+	}
+
+	txns := map[core.Hash]*core.Transaction{
+		// transfer to the token
+		{0x1}: newTxn(core.Hash{0x1}, tokenAddr, input),
+		// transaction to another address
+		{0x2}: newTxn(core.Hash{0x2}, otherAddr, input),
+		// unknown selector to the token
+		{0x3}: newTxn(core.Hash{0x3}, tokenAddr, []byte{0x1, 0x2, 0x3, 0x4}),
+	}
+
+	var lock sync.Mutex
+	polled, uninstalled := false, false
+
+	c := newMockClient(t, func(method string, params []json.RawMessage) interface{} {
+		lock.Lock()
+		defer lock.Unlock()
+
+		switch method {
+		case "eth_newPendingTransactionFilter":
+			return "0x1"
+
+		case "eth_getFilterChanges":
+			if polled {
+				return []core.Hash{}
+			}
+			polled = true
+			// the hash 0x4 has been dropped from the pool
+			return []core.Hash{{0x1}, {0x2}, {0x3}, {0x4}}
+
+		case "eth_getTransactionByHash":
+			var hash core.Hash
+			require.NoError(t, json.Unmarshal(params[0], &hash))
+			if txn, ok := txns[hash]; ok {
+				return txn
+			}
+			return nil
+
+		case "eth_uninstallFilter":
+			uninstalled = true
+			return true
+		}
+		t.Fatalf("unexpected method %s", method)
+		return nil
+	})
+
+	w := NewWatcher(c.Eth(), WithTo(tokenAddr), WithABI(token), WithPollInterval(10*time.Millisecond))
+
+	ch := make(chan *Transaction, 10)
+	closeFn, err := w.Watch(ch)
+	require.NoError(t, err)
+
+	recv := func() *Transaction {
+		select {
+		case txn := <-ch:
+			return txn
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+		return nil
+	}
+
+	txn := recv()
+	assert.Equal(t, core.Hash{0x1}, txn.Hash)
+	assert.Equal(t, "transfer", txn.Method.Name)
+	assert.Equal(t, otherAddr, txn.Args["to"])
+	assert.Equal(t, big.NewInt(100), txn.Args["amount"])
+
+	txn = recv()
+	assert.Equal(t, core.Hash{0x3}, txn.Hash)
+	assert.Nil(t, txn.Method)
+
+	require.NoError(t, closeFn())
+	assert.True(t, uninstalled)
+	assert.Empty(t, ch)
+}
+
+func TestWatcher_CloseBlocked(t *testing.T) {
+	fetched := make(chan struct{}, 1)
+
+	c := newMockClient(t, func(method string, params []json.RawMessage) interface{} {
+		switch method {
+		case "eth_newPendingTransactionFilter":
+			return "0x1"
+
+		case "eth_getFilterChanges":
+			return []core.Hash{{0x1}}
+
+		case "eth_getTransactionByHash":
+			select {
+			case fetched <- struct{}{}:
+			default:
+			}
+			return &core.Transaction{
+				Hash:     core.Hash{0x1},
+				Gas:      21000,
+				GasPrice: 1,
+				Value:    big.NewInt(0),
+				V:        []byte{0x1},
+				R:        []byte{0x1},
+				S:        []byte{0x1},
+			}
+
+		case "eth_uninstallFilter":
+			return true
+		}
+		t.Fatalf("unexpected method %s", method)
+		return nil
+	})
+
+	w := NewWatcher(c.Eth(), WithPollInterval(10*time.Millisecond))
+
+	// nobody reads from the channel
+	closeFn, err := w.Watch(make(chan *Transaction))
+	require.NoError(t, err)
+
+	select {
+	case <-fetched:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+
+	doneCh := make(chan error)
+	go func() {
+		doneCh <- closeFn()
+	}()
+	select {
+	case err := <-doneCh:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("close blocked on the channel")
+	}
+	require.Error(t, closeFn())
+}
+
+func TestWatcher_Filter(t *testing.T) {
+	selector := []byte{0x1, 0x2, 0x3, 0x4}
+	to := core.Address{0x1}
+
+	cases := []struct {
+		opts  []Option
+		txn   *core.Transaction
+		match bool
+	}{
+		{
+			nil,
+			&core.Transaction{},
+			true,
+		},
+		{
+			[]Option{WithTo(to)},
+			// contract creation
+			&core.Transaction{},
+			false,
+		},
+		{
+			[]Option{WithTo(to)},
+			&core.Transaction{To: &to},
+			true,
+		},
+		{
+			[]Option{WithFrom(core.Address{0x2})},
+			&core.Transaction{From: core.Address{0x3}},
+			false,
+		},
+		{
+			[]Option{WithFrom(core.Address{0x2}, core.Address{0x3})},
+			&core.Transaction{From: core.Address{0x3}},
+			true,
+		},
+		{
+			[]Option{WithSelectors(selector)},
+			&core.Transaction{Input: []byte{0x1, 0x2}},
+			false,
+		},
+		{
+			[]Option{WithSelectors(selector)},
+			&core.Transaction{Input: []byte{0x1, 0x2, 0x3, 0x4, 0x5}},
+			true,
+		},
+	}
+
+	for _, c := range cases {
+		w := NewWatcher(nil, c.opts...)
+		_, match := w.process(c.txn)
+		assert.Equal(t, c.match, match)
+	}
+}
@@ -0,0 +1,294 @@
+package multicall
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/contract"
+	"github.com/deep-nl/ethgo/core"
+)
+
+// Multicall3Address is the address of the Multicall3 contract. It is deployed
+// at the same address on most of the chains (https://www.multicall3.com).
+var Multicall3Address = core.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+var multicall3ABI = abi.MustNewABI(`[
+	{
+		"type": "function",
+		"name": "aggregate3",
+		"stateMutability": "payable",
+		"inputs": [
+			{
+				"name": "calls",
+				"type": "tuple[]",
+				"components": [
+					{"name": "target", "type": "address"},
+					{"name": "allowFailure", "type": "bool"},
+					{"name": "callData", "type": "bytes"}
+				]
+			}
+		],
+		"outputs": [
+			{
+				"name": "returnData",
+				"type": "tuple[]",
+				"components": [
+					{"name": "success", "type": "bool"},
+					{"name": "returnData", "type": "bytes"}
+				]
+			}
+		]
+	}
+]`)
+
+// Call is a contract call to aggregate
+type Call struct {
+	// Contract is the contract to call
+	Contract *contract.Contract
+
+	// Method is the name of the method in the abi of the contract
+	Method string
+
+	// Args are the inputs of the method
+	Args []interface{}
+
+	// AllowFailure does not revert the whole batch if the call fails
+	AllowFailure bool
+
+	// Gas is an estimation of the gas used by the call to split the batches
+	Gas uint64
+}
+
+// Result is the result of an aggregated call
+type Result struct {
+	// Success is true if the call did not fail
+	Success bool
+
+	// Output is the decoded output of the call
+	Output map[string]interface{}
+
+	// Err is the error of the call if it failed
+	Err error
+}
+
+type Config struct {
+	Addr            core.Address
+	ContractOpts    []contract.ContractOption
+	MaxCalldataSize int
+	MaxGas          uint64
+}
+
+type Option func(*Config)
+
+// WithAddress sets the address of the Multicall3 contract
+func WithAddress(addr core.Address) Option {
+	return func(c *Config) {
+		c.Addr = addr
+	}
+}
+
+// WithContractOptions sets the options to make the calls to the Multicall3 contract
+func WithContractOptions(opts ...contract.ContractOption) Option {
+	return func(c *Config) {
+		c.ContractOpts = append(c.ContractOpts, opts...)
+	}
+}
+
+// WithMaxCalldataSize sets the maximum size of the calldata of a single batch
+func WithMaxCalldataSize(size int) Option {
+	return func(c *Config) {
+		c.MaxCalldataSize = size
+	}
+}
+
+// WithMaxGas sets the maximum sum of the gas of the calls of a single batch
+func WithMaxGas(gas uint64) Option {
+	return func(c *Config) {
+		c.MaxGas = gas
+	}
+}
+
+// Multicall aggregates contract calls into Multicall3 aggregate3 calls
+type Multicall struct {
+	config *Config
+	c      *contract.Contract
+
+	lock        sync.Mutex
+	unsupported bool
+}
+
+// NewMulticall creates a new Multicall
+func NewMulticall(opts ...Option) *Multicall {
+	config := &Config{
+		Addr:            Multicall3Address,
+		MaxCalldataSize: 100 * 1024,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	m := &Multicall{
+		config: config,
+		c:      contract.NewContract(config.Addr, multicall3ABI, config.ContractOpts...),
+	}
+	return m
+}
+
+// Aggregate makes the calls at the given block and returns the results in the same
+// order. The calls are split in batches by calldata size and gas. If there is no
+// Multicall3 contract deployed at the block it makes each call individually.
+func (m *Multicall) Aggregate(calls []*Call, block core.BlockNumber) ([]*Result, error) {
+	inputs := make([][]byte, len(calls))
+	methods := make([]*abi.Method, len(calls))
+	for indx, call := range calls {
+		method := call.Contract.GetABI().GetMethod(call.Method)
+		if method == nil {
+			return nil, fmt.Errorf("method %s not found", call.Method)
+		}
+		input, err := method.Encode(call.Args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode call %d: %v", indx, err)
+		}
+		inputs[indx] = input
+		methods[indx] = method
+	}
+
+	if !m.isSupported() {
+		return m.aggregateFallback(calls, block)
+	}
+
+	res := make([]*Result, 0, len(calls))
+	for _, batch := range m.batches(calls, inputs) {
+		results, err := m.aggregate3(calls[batch.from:batch.to], inputs[batch.from:batch.to], methods[batch.from:batch.to], block)
+		if err != nil {
+			if errors.Is(err, abi.ErrEmptyResponse) {
+				// there is no multicall contract deployed at the block
+				m.checkDeployed()
+				return m.aggregateFallback(calls, block)
+			}
+			return nil, err
+		}
+		res = append(res, results...)
+	}
+	return res, nil
+}
+
+type batch struct {
+	from, to int
+}
+
+func (m *Multicall) batches(calls []*Call, inputs [][]byte) []batch {
+	res := []batch{}
+
+	from, size, gas := 0, 0, uint64(0)
+	for indx, input := range inputs {
+		// each call in the batch uses at least 4 words for the address,
+		// the allowFailure flag, the offset and the length of the calldata
+		callSize := 128 + (len(input)+31)/32*32
+
+		exceeds := size+callSize > m.config.MaxCalldataSize
+		if m.config.MaxGas != 0 && gas+calls[indx].Gas > m.config.MaxGas {
+			exceeds = true
+		}
+		if exceeds && indx != from {
+			res = append(res, batch{from, indx})
+			from, size, gas = indx, 0, 0
+		}
+		size += callSize
+		gas += calls[indx].Gas
+	}
+	if from != len(inputs) {
+		res = append(res, batch{from, len(inputs)})
+	}
+	return res
+}
+
+func (m *Multicall) aggregate3(calls []*Call, inputs [][]byte, methods []*abi.Method, block core.BlockNumber) ([]*Result, error) {
+	args := []map[string]interface{}{}
+	for indx, call := range calls {
+		args = append(args, map[string]interface{}{
+			"target":       call.Contract.Addr(),
+			"allowFailure": call.AllowFailure,
+			"callData":     inputs[indx],
+		})
+	}
+
+	out, err := m.c.Call("aggregate3", block, args)
+	if err != nil {
+		return nil, err
+	}
+	returnData, ok := out["returnData"].([]map[string]interface{})
+	if !ok || len(returnData) != len(calls) {
+		return nil, fmt.Errorf("unexpected aggregate3 output")
+	}
+
+	res := make([]*Result, len(calls))
+	for indx, data := range returnData {
+		success, _ := data["success"].(bool)
+		raw, _ := data["returnData"].([]byte)
+
+		result := &Result{}
+		if success {
+			result.Output, result.Err = methods[indx].Decode(raw)
+			result.Success = result.Err == nil
+		} else {
+			result.Err = revertError(raw)
+		}
+		res[indx] = result
+	}
+	return res, nil
+}
+
+func (m *Multicall) aggregateFallback(calls []*Call, block core.BlockNumber) ([]*Result, error) {
+	res := make([]*Result, len(calls))
+	for indx, call := range calls {
+		output, err := call.Contract.Call(call.Method, block, call.Args...)
+		if err != nil && !call.AllowFailure {
+			return nil, fmt.Errorf("call %d failed: %v", indx, err)
+		}
+		res[indx] = &Result{
+			Success: err == nil,
+			Output:  output,
+			Err:     err,
+		}
+	}
+	return res, nil
+}
+
+func (m *Multicall) isSupported() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return !m.unsupported
+}
+
+// checkDeployed marks the Multicall3 contract as unsupported if it has no code
+// in the latest block. An empty response for an old block or from a node that
+// is not synced does not disable the multicall for the next calls.
+func (m *Multicall) checkDeployed() {
+	provider, ok := m.c.Provider().(contract.CodeProvider)
+	if !ok {
+		return
+	}
+	code, err := provider.GetCode(m.config.Addr, core.Latest)
+	if err != nil || len(code) != 0 {
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.unsupported = true
+}
+
+func revertError(raw []byte) error {
+	if len(raw) == 0 {
+		return fmt.Errorf("execution reverted")
+	}
+	reason, err := abi.UnpackRevertError(raw)
+	if err != nil {
+		return fmt.Errorf("execution reverted: 0x%x", raw)
+	}
+	return fmt.Errorf("execution reverted: %s", reason)
+}
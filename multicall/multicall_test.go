@@ -0,0 +1,207 @@
+package multicall
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/contract"
+	"github.com/deep-nl/ethgo/core"
+	"github.com/stretchr/testify/require"
+)
+
+var erc20ABI = abi.MustNewABI(`[
+	{"type": "function", "name": "balanceOf", "stateMutability": "view", "inputs": [{"name": "owner", "type": "address"}], "outputs": [{"name": "balance", "type": "uint256"}]},
+	{"type": "function", "name": "decimals", "stateMutability": "view", "inputs": [], "outputs": [{"name": "", "type": "uint8"}]}
+]`)
+
+// mockProvider is a contract provider that serves the balanceOf method
+// for any address and optionally the Multicall3 aggregate3 method
+type mockProvider struct {
+	multicall bool
+	calls     int
+	batches   int
+
+	// empty is the number of aggregate3 calls with an empty response
+	empty int
+}
+
+func (m *mockProvider) Call(addr core.Address, input []byte, opts *contract.CallOpts) ([]byte, error) {
+	if addr == Multicall3Address {
+		if !m.multicall {
+			return []byte{}, nil
+		}
+		if m.empty > 0 {
+			m.empty--
+			return []byte{}, nil
+		}
+		return m.aggregate3(input)
+	}
+	m.calls++
+	res, ok := m.call(addr, input)
+	if !ok {
+		return nil, fmt.Errorf("execution reverted")
+	}
+	return res, nil
+}
+
+func (m *mockProvider) call(addr core.Address, input []byte) ([]byte, bool) {
+	method := erc20ABI.GetMethod("balanceOf")
+	if string(input[:4]) != string(method.ID()) {
+		return nil, false
+	}
+	args, err := method.Inputs.Decode(input[4:])
+	if err != nil {
+		return nil, false
+	}
+	owner := args.(map[string]interface{})["owner"].(core.Address)
+	res, _ := method.Outputs.Encode(map[string]interface{}{
+		"balance": new(big.Int).SetBytes(owner[:]),
+	})
+	return res, true
+}
+
+func (m *mockProvider) aggregate3(input []byte) ([]byte, error) {
+	m.batches++
+
+	method := multicall3ABI.GetMethod("aggregate3")
+	args, err := method.Inputs.Decode(input[4:])
+	if err != nil {
+		return nil, err
+	}
+	calls := args.(map[string]interface{})["calls"].([]map[string]interface{})
+
+	res := []map[string]interface{}{}
+	for _, call := range calls {
+		m.calls++
+		data, ok := m.call(call["target"].(core.Address), call["callData"].([]byte))
+		if !ok && !call["allowFailure"].(bool) {
+			return nil, fmt.Errorf("execution reverted")
+		}
+		res = append(res, map[string]interface{}{
+			"success":    ok,
+			"returnData": data,
+		})
+	}
+	return method.Outputs.Encode(map[string]interface{}{
+		"returnData": res,
+	})
+}
+
+func (m *mockProvider) GetCode(addr core.Address, block core.BlockNumber) ([]byte, error) {
+	if addr == Multicall3Address && m.multicall {
+		return []byte{0x1}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockProvider) Txn(core.Address, core.Key, []byte) (contract.Txn, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func newCalls(provider contract.Provider, num int) []*Call {
+	calls := []*Call{}
+	for i := 0; i < num; i++ {
+		token := contract.NewContract(core.Address{byte(i)}, erc20ABI, contract.WithProvider(provider))
+		calls = append(calls, &Call{
+			Contract: token,
+			Method:   "balanceOf",
+			Args:     []interface{}{core.Address{0x1, byte(i)}},
+			Gas:      100,
+		})
+	}
+	return calls
+}
+
+func TestMulticall_Aggregate(t *testing.T) {
+	provider := &mockProvider{multicall: true}
+	calls := newCalls(provider, 10)
+
+	// the last call fails but it is allowed to fail
+	calls = append(calls, &Call{
+		Contract:     calls[0].Contract,
+		Method:       "decimals",
+		AllowFailure: true,
+	})
+
+	m := NewMulticall(WithContractOptions(contract.WithProvider(provider)))
+	res, err := m.Aggregate(calls, core.Latest)
+	require.NoError(t, err)
+	require.Len(t, res, 11)
+	require.Equal(t, 1, provider.batches)
+
+	for i := 0; i < 10; i++ {
+		owner := core.Address{0x1, byte(i)}
+		require.True(t, res[i].Success)
+		require.Equal(t, new(big.Int).SetBytes(owner[:]), res[i].Output["balance"])
+	}
+	require.False(t, res[10].Success)
+	require.Error(t, res[10].Err)
+
+	// the call is not allowed to fail
+	calls[10].AllowFailure = false
+	_, err = m.Aggregate(calls, core.Latest)
+	require.Error(t, err)
+}
+
+func TestMulticall_Batches(t *testing.T) {
+	provider := &mockProvider{multicall: true}
+	calls := newCalls(provider, 10)
+
+	// each balanceOf call uses 128 + 64 bytes
+	m := NewMulticall(WithContractOptions(contract.WithProvider(provider)), WithMaxCalldataSize(192*3))
+	res, err := m.Aggregate(calls, core.Latest)
+	require.NoError(t, err)
+	require.Len(t, res, 10)
+	require.Equal(t, 4, provider.batches)
+
+	provider.batches = 0
+	m = NewMulticall(WithContractOptions(contract.WithProvider(provider)), WithMaxGas(500))
+	res, err = m.Aggregate(calls, core.Latest)
+	require.NoError(t, err)
+	require.Len(t, res, 10)
+	require.Equal(t, 2, provider.batches)
+
+	for i := 0; i < 10; i++ {
+		require.True(t, res[i].Success)
+	}
+}
+
+func TestMulticall_Fallback(t *testing.T) {
+	provider := &mockProvider{multicall: false}
+	calls := newCalls(provider, 5)
+
+	m := NewMulticall(WithContractOptions(contract.WithProvider(provider)))
+	res, err := m.Aggregate(calls, core.Latest)
+	require.NoError(t, err)
+	require.Len(t, res, 5)
+	require.Equal(t, 5, provider.calls)
+	require.True(t, m.unsupported)
+
+	for i := 0; i < 5; i++ {
+		owner := core.Address{0x1, byte(i)}
+		require.True(t, res[i].Success)
+		require.Equal(t, new(big.Int).SetBytes(owner[:]), res[i].Output["balance"])
+	}
+}
+
+func TestMulticall_FallbackTransient(t *testing.T) {
+	provider := &mockProvider{multicall: true, empty: 1}
+	calls := newCalls(provider, 5)
+
+	// the node returns an empty response but the contract is deployed
+	m := NewMulticall(WithContractOptions(contract.WithProvider(provider)))
+	res, err := m.Aggregate(calls, core.Latest)
+	require.NoError(t, err)
+	require.Len(t, res, 5)
+	require.Equal(t, 5, provider.calls)
+	require.False(t, m.unsupported)
+
+	// the next calls use the multicall contract
+	provider.calls, provider.batches = 0, 0
+	res, err = m.Aggregate(calls, core.Latest)
+	require.NoError(t, err)
+	require.Len(t, res, 5)
+	require.Equal(t, 1, provider.batches)
+}
@@ -0,0 +1,306 @@
+package sourcemap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/deep-nl/ethgo/compiler"
+	"github.com/deep-nl/ethgo/jsonrpc"
+)
+
+// Entry is the source range of an instruction in a source map
+type Entry struct {
+	// Start and Length are the byte range in the source file
+	Start  int
+	Length int
+
+	// File is the index of the source file, -1 if the instruction
+	// is not related to any source file
+	File int
+
+	// Jump is i for jumps into a function, o for returns from a
+	// function and - for regular jumps
+	Jump string
+
+	// ModifierDepth is the depth of the modifier for the instruction
+	ModifierDepth int
+}
+
+// Parse parses the compressed source map of the solidity compiler.
+// Each entry has the s:l:f:j:m format and the empty fields take the value
+// of the previous entry.
+func Parse(srcmap string) ([]*Entry, error) {
+	res := []*Entry{}
+	if srcmap == "" {
+		return res, nil
+	}
+
+	prev := Entry{File: -1, Jump: "-"}
+	for indx, item := range strings.Split(srcmap, ";") {
+		entry := prev
+
+		for field, val := range strings.Split(item, ":") {
+			if val == "" {
+				continue
+			}
+			if field == 3 {
+				entry.Jump = val
+				continue
+			}
+			num, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, fmt.Errorf("incorrect field %d in entry %d: %v", field, indx, err)
+			}
+			switch field {
+			case 0:
+				entry.Start = num
+			case 1:
+				entry.Length = num
+			case 2:
+				entry.File = num
+			case 4:
+				entry.ModifierDepth = num
+			default:
+				return nil, fmt.Errorf("too many fields in entry %d", indx)
+			}
+		}
+
+		res = append(res, &entry)
+		prev = entry
+	}
+	return res, nil
+}
+
+// instructionIndexes maps the program counters of the bytecode
+// to the index of their instructions
+func instructionIndexes(code []byte) map[uint64]int {
+	res := map[uint64]int{}
+	indx := 0
+	for pc := 0; pc < len(code); pc++ {
+		res[uint64(pc)] = indx
+		indx++
+
+		// skip the data of the PUSH1-PUSH32 instructions
+		if op := code[pc]; op >= 0x60 && op <= 0x7f {
+			pc += int(op - 0x5f)
+		}
+	}
+	return res
+}
+
+// Location is a position in a source file
+type Location struct {
+	File string
+
+	// Line and Column start at 1
+	Line   int
+	Column int
+
+	// Start and Length are the byte range in the source file
+	Start  int
+	Length int
+}
+
+// String implements the stringer interface
+func (l *Location) String() string {
+	return fmt.Sprintf("%s:%d:%d", l.File, l.Line, l.Column)
+}
+
+// Mapper maps the program counters of the runtime bytecode
+// of a contract to the location in the source files
+type Mapper struct {
+	indexes map[uint64]int
+	entries []*Entry
+	files   []string
+	lines   map[string][]int
+}
+
+// NewMapper creates a Mapper from the runtime bytecode and source map of
+// the artifact. The files are the source files indexed as in the source
+// map and the sources are their contents.
+func NewMapper(artifact *compiler.Artifact, files []string, sources map[string][]byte) (*Mapper, error) {
+	code, err := decodeUnlinked(artifact.BinRuntime)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := Parse(artifact.SrcMapRuntime)
+	if err != nil {
+		return nil, err
+	}
+	m := &Mapper{
+		indexes: instructionIndexes(code),
+		entries: entries,
+		files:   files,
+		lines:   map[string][]int{},
+	}
+	for name, source := range sources {
+		// offsets where each line starts
+		lines := []int{0}
+		for i, b := range source {
+			if b == '\n' {
+				lines = append(lines, i+1)
+			}
+		}
+		m.lines[name] = lines
+	}
+	return m, nil
+}
+
+// NewMapperFromOutput creates a Mapper for the contract (i.e. path/to/file.sol:Name)
+// of the compiler output. The source files are read from the paths used for
+// the compilation unless they are included in the sources.
+func NewMapperFromOutput(o *compiler.Output, contract string, sources map[string][]byte) (*Mapper, error) {
+	artifact, ok := o.Contracts[contract]
+	if !ok {
+		return nil, fmt.Errorf("contract %s not found", contract)
+	}
+	files := o.SourceList
+	if len(files) == 0 {
+		files = make([]string, len(o.Sources))
+		for name, source := range o.Sources {
+			indx, ok := fileIndex(source)
+			if !ok || indx >= len(files) {
+				return nil, fmt.Errorf("source list not found")
+			}
+			files[indx] = name
+		}
+	}
+
+	contents := map[string][]byte{}
+	for _, name := range files {
+		if content, ok := sources[name]; ok {
+			contents[name] = content
+			continue
+		}
+		content, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read source %s: %v", name, err)
+		}
+		contents[name] = content
+	}
+	return NewMapper(artifact, files, contents)
+}
+
+// fileIndex returns the index of the file from the range of the source unit
+func fileIndex(s *compiler.Source) (int, bool) {
+	src, ok := s.AST["src"].(string)
+	if !ok {
+		return 0, false
+	}
+	parts := strings.Split(src, ":")
+	indx, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return 0, false
+	}
+	return indx, true
+}
+
+// decodeUnlinked decodes the hex bytecode replacing the library placeholders
+func decodeUnlinked(bin string) ([]byte, error) {
+	refs, err := compiler.LinkReferences(bin)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		bin = strings.Replace(bin, ref.Placeholder, strings.Repeat("0", len(ref.Placeholder)), -1)
+	}
+	return hex.DecodeString(strings.TrimPrefix(bin, "0x"))
+}
+
+// Location returns the location in the source files of the instruction at
+// the program counter. It returns false if the instruction is not related
+// to any source file (i.e. code generated by the compiler).
+func (m *Mapper) Location(pc uint64) (*Location, bool) {
+	indx, ok := m.indexes[pc]
+	if !ok || indx >= len(m.entries) {
+		return nil, false
+	}
+	entry := m.entries[indx]
+	if entry.File < 0 || entry.File >= len(m.files) {
+		return nil, false
+	}
+
+	file := m.files[entry.File]
+	loc := &Location{
+		File:   file,
+		Start:  entry.Start,
+		Length: entry.Length,
+	}
+	if lines, ok := m.lines[file]; ok {
+		line := sort.Search(len(lines), func(i int) bool {
+			return lines[i] > entry.Start
+		})
+		loc.Line = line
+		loc.Column = entry.Start - lines[line-1] + 1
+	}
+	return loc, true
+}
+
+// RevertLocation returns the location of the instruction that reverted in the
+// struct logs of a transaction trace. The depth is the depth of the calls to
+// the contract in the trace, 1 if the transaction calls the contract directly.
+func (m *Mapper) RevertLocation(logs []*jsonrpc.StructLogs, depth int) (*Location, bool) {
+	for i := len(logs) - 1; i >= 0; i-- {
+		log := logs[i]
+		if log.Depth != depth {
+			continue
+		}
+		if log.Op != "REVERT" && log.Op != "INVALID" {
+			// the last instruction of the contract did not fail
+			return nil, false
+		}
+		return m.Location(uint64(log.Pc))
+	}
+	return nil, false
+}
+
+// LineGas is the gas spent in a line of a source file
+type LineGas struct {
+	File string
+	Line int
+	Gas  uint64
+}
+
+// GasProfile adds up the gas cost of the instructions of the contract in the
+// struct logs of a transaction trace by source line and returns the lines
+// sorted by gas. The depth is the same as in RevertLocation. The instructions
+// that are not related to any source file are not included and the gas cost
+// of the calls includes the gas sent to the callee.
+func (m *Mapper) GasProfile(logs []*jsonrpc.StructLogs, depth int) []*LineGas {
+	lines := map[Location]*LineGas{}
+	for _, log := range logs {
+		if log.Depth != depth {
+			continue
+		}
+		loc, ok := m.Location(uint64(log.Pc))
+		if !ok {
+			continue
+		}
+		key := Location{File: loc.File, Line: loc.Line}
+		line, ok := lines[key]
+		if !ok {
+			line = &LineGas{File: loc.File, Line: loc.Line}
+			lines[key] = line
+		}
+		line.Gas += uint64(log.GasCost)
+	}
+
+	res := make([]*LineGas, 0, len(lines))
+	for _, line := range lines {
+		res = append(res, line)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Gas != res[j].Gas {
+			return res[i].Gas > res[j].Gas
+		}
+		if res[i].File != res[j].File {
+			return res[i].File < res[j].File
+		}
+		return res[i].Line < res[j].Line
+	})
+	return res
+}
@@ -0,0 +1,84 @@
+package sourcemap
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deep-nl/ethgo/compiler"
+	"github.com/deep-nl/ethgo/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	entries, err := Parse("1:2:1;:9;2:1:2;;3::-1:o:1")
+	require.NoError(t, err)
+
+	expected := []*Entry{
+		{Start: 1, Length: 2, File: 1, Jump: "-"},
+		{Start: 1, Length: 9, File: 1, Jump: "-"},
+		{Start: 2, Length: 1, File: 2, Jump: "-"},
+		{Start: 2, Length: 1, File: 2, Jump: "-"},
+		{Start: 3, Length: 1, File: -1, Jump: "o", ModifierDepth: 1},
+	}
+	assert.Equal(t, expected, entries)
+
+	_, err = Parse("1:a")
+	require.Error(t, err)
+}
+
+func TestMapper(t *testing.T) {
+	source := "contract A {\n  function f() public {\n    uint a = 1;\n    revert();\n  }\n}\n"
+
+	start := strings.Index(source, "uint a")
+	revert := strings.Index(source, "revert")
+
+	// PUSH1 0x80, PUSH1 0x40, MSTORE, PUSH1 0x01, POP, REVERT
+	art := &compiler.Artifact{
+		BinRuntime:    "6080604052600150fd",
+		SrcMapRuntime: fmt.Sprintf("0:70:0:-:0;;;%d:10;;%d:8;0:0:-1", start, revert),
+	}
+
+	m, err := NewMapper(art, []string{"A.sol"}, map[string][]byte{"A.sol": []byte(source)})
+	require.NoError(t, err)
+
+	loc, ok := m.Location(0)
+	require.True(t, ok)
+	assert.Equal(t, "A.sol:1:1", loc.String())
+
+	// pc 7 is the POP, the second instruction of line 3
+	loc, ok = m.Location(7)
+	require.True(t, ok)
+	assert.Equal(t, "A.sol:3:5", loc.String())
+
+	// the data of a push is not an instruction
+	_, ok = m.Location(1)
+	require.False(t, ok)
+
+	logs := []*jsonrpc.StructLogs{
+		{Depth: 1, Pc: 0, Op: "PUSH1", GasCost: 3},
+		{Depth: 1, Pc: 2, Op: "PUSH1", GasCost: 3},
+		{Depth: 1, Pc: 4, Op: "MSTORE", GasCost: 12},
+		{Depth: 1, Pc: 5, Op: "PUSH1", GasCost: 3},
+		{Depth: 2, Pc: 0, Op: "PUSH1", GasCost: 100},
+		{Depth: 1, Pc: 7, Op: "POP", GasCost: 2},
+		{Depth: 1, Pc: 8, Op: "REVERT", GasCost: 0},
+	}
+
+	loc, ok = m.RevertLocation(logs, 1)
+	require.True(t, ok)
+	assert.Equal(t, "A.sol:4:5", loc.String())
+	assert.Equal(t, "revert()", source[loc.Start:loc.Start+loc.Length])
+
+	_, ok = m.RevertLocation(logs[:6], 1)
+	require.False(t, ok)
+
+	profile := m.GasProfile(logs, 1)
+	expected := []*LineGas{
+		{File: "A.sol", Line: 1, Gas: 18},
+		{File: "A.sol", Line: 3, Gas: 5},
+		{File: "A.sol", Line: 4, Gas: 0},
+	}
+	assert.Equal(t, expected, profile)
+}
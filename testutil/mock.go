@@ -25,6 +25,7 @@ type MockClient struct {
 	blockNum map[uint64]core.Hash
 	blocks   map[core.Hash]*core.Block
 	logs     map[core.Hash][]*core.Log
+	tags     map[core.BlockNumber]uint64
 	chainID  *big.Int
 }
 
@@ -32,7 +33,21 @@ func (m *MockClient) SetChainID(id *big.Int) {
 	m.chainID = id
 }
 
+// SetBlockTag sets the number of the block returned for a tag (i.e. core.Finalized)
+func (m *MockClient) SetBlockTag(tag core.BlockNumber, num uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.tags == nil {
+		m.tags = map[core.BlockNumber]uint64{}
+	}
+	m.tags[tag] = num
+}
+
 func (d *MockClient) ChainID() (*big.Int, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
 	if d.chainID == nil {
 		d.chainID = big.NewInt(1337)
 	}
@@ -166,6 +181,12 @@ func (d *MockClient) GetBlockByNumber(i core.BlockNumber, full bool) (*core.Bloc
 				return &core.Block{Number: 0}, nil
 			}
 			return d.blockByNumberLock(d.num)
+		case core.Safe, core.Finalized:
+			num, ok := d.tags[i]
+			if !ok {
+				return nil, fmt.Errorf("%s block not found", i)
+			}
+			return d.blockByNumberLock(num)
 		default:
 			return nil, fmt.Errorf("getBlockByNumber query not supported")
 		}
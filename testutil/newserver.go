@@ -190,6 +190,13 @@ func (t *Server) SendRawTxn(fromKey string, txn *core.Transaction) (*core.Receip
 	if txn.Gas == 0 {
 		txn.Gas = DefaultGasLimit
 	}
+	if txn.Nonce == 0 {
+		var nonce core.ArgUint64
+		if err := t.httpClient.call("eth_getTransactionCount", &nonce, txn.From, "pending"); err != nil {
+			return nil, err
+		}
+		txn.Nonce = uint64(nonce)
+	}
 	if txn.ChainID != nil {
 		signer = wallet.NewEIP155Signer(txn.ChainID.Uint64())
 	} else {
@@ -60,10 +60,13 @@ type ServerConfigCallback func(c *TestServerConfig)
 // TestServer is a Geth test server
 type TestServer struct {
 	addr     string
+	wsAddr   string
 	accounts []core.Address
 	client   *ethClient
 }
 
+// NewTestServer connects to the node of the first address. The second
+// address, if any, is the websocket endpoint of the node.
 func NewTestServer(t *testing.T, addrs ...string) *TestServer {
 	var addr string
 	if len(addrs) != 0 {
@@ -77,6 +80,9 @@ func NewTestServer(t *testing.T, addrs ...string) *TestServer {
 	server := &TestServer{
 		addr: addr,
 	}
+	if len(addrs) > 1 {
+		server.wsAddr = addrs[1]
+	}
 
 	server.client = &ethClient{addr}
 	if strings.HasPrefix(addr, "ws") {
@@ -103,6 +109,9 @@ func (t *TestServer) IPCPath() string {
 
 // WSAddr returns the websocket endpoint
 func (t *TestServer) WSAddr() string {
+	if t.wsAddr != "" {
+		return t.wsAddr
+	}
 	return fmt.Sprintf("ws://localhost:8545")
 }
 
@@ -210,6 +219,13 @@ func (t *TestServer) SendRawTxn(txn *core.Transaction) (*core.Receipt, error) {
 	if txn.Gas == 0 {
 		txn.Gas = DefaultGasLimit
 	}
+	if txn.Nonce == 0 {
+		nonce, err := t.pendingNonce(txn.From)
+		if err != nil {
+			return nil, err
+		}
+		txn.Nonce = nonce
+	}
 	key := wallet.KeyFromString(FromKey)
 
 	signer := wallet.NewEIP155Signer(core.Local)
@@ -230,6 +246,15 @@ func (t *TestServer) SendRawTxn(txn *core.Transaction) (*core.Receipt, error) {
 	return t.WaitForReceipt(hash)
 }
 
+// pendingNonce returns the nonce of the next transaction of the account
+func (t *TestServer) pendingNonce(addr core.Address) (uint64, error) {
+	var nonce core.ArgUint64
+	if err := t.client.call("eth_getTransactionCount", &nonce, addr, "pending"); err != nil {
+		return 0, err
+	}
+	return uint64(nonce), nil
+}
+
 // WaitForReceipt waits for the receipt
 func (t *TestServer) WaitForReceipt(hash core.Hash) (*core.Receipt, error) {
 	var receipt *core.Receipt
@@ -278,9 +303,10 @@ func (t *TestServer) DeployContract(c *Contract) (*compiler.Artifact, core.Addre
 // Simple jsonrpc client to avoid cycle dependencies
 
 type jsonRPCRequest struct {
-	ID     int             `json:"id"`
-	Method string          `json:"method"`
-	Params json.RawMessage `json:"params"`
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
 }
 
 type jsonRPCResponse struct {
@@ -308,7 +334,8 @@ func (e *ethClient) call(method string, out interface{}, params ...interface{})
 
 	var err error
 	jsonReq := &jsonRPCRequest{
-		Method: method,
+		JSONRPC: "2.0",
+		Method:  method,
 	}
 	if len(params) > 0 {
 		jsonReq.Params, err = json.Marshal(params)
@@ -0,0 +1,436 @@
+package simulated
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/jsonrpc/transport"
+	"github.com/deep-nl/ethgo/testutil"
+	"github.com/deep-nl/ethgo/wallet"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	gethcore "github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/catalyst"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	// register the native tracers (i.e. callTracer, prestateTracer)
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
+)
+
+// DefaultBalance is the balance of the accounts funded at genesis
+var DefaultBalance = new(big.Int).Mul(big.NewInt(1e18), big.NewInt(1e9))
+
+// Config is the configuration of the simulated chain
+type Config struct {
+	// Keys are the accounts funded at genesis. The node signs
+	// the transactions sent with eth_sendTransaction with them.
+	Keys []*wallet.Key
+
+	// Balance is the genesis balance of each account
+	Balance *big.Int
+
+	// GasLimit is the gas limit of the blocks
+	GasLimit uint64
+
+	// Period mines a block periodically instead of a block
+	// for each transaction sent to the node
+	Period time.Duration
+}
+
+type Option func(*Config)
+
+// WithKeys funds the accounts of the keys at genesis
+func WithKeys(keys ...*wallet.Key) Option {
+	return func(c *Config) {
+		c.Keys = append(c.Keys, keys...)
+	}
+}
+
+// WithBalance sets the genesis balance of the accounts
+func WithBalance(balance *big.Int) Option {
+	return func(c *Config) {
+		c.Balance = balance
+	}
+}
+
+// WithGasLimit sets the gas limit of the blocks
+func WithGasLimit(gasLimit uint64) Option {
+	return func(c *Config) {
+		c.GasLimit = gasLimit
+	}
+}
+
+// WithPeriod mines blocks periodically
+func WithPeriod(period time.Duration) Option {
+	return func(c *Config) {
+		c.Period = period
+	}
+}
+
+// Chain is an in-process chain that executes the transactions with the evm
+// of go-ethereum and mines a block as soon as a transaction is received. It is
+// reachable both with a transport and with local http and websocket endpoints.
+type Chain struct {
+	config  *Config
+	tmpDir  string
+	stack   *node.Node
+	backend *eth.Ethereum
+	beacon  *catalyst.SimulatedBeacon
+
+	// lock serializes the block production
+	lock sync.Mutex
+
+	// snapshots are the heads of the chain saved with Snapshot
+	snapshots []common.Hash
+
+	closeCh   chan struct{}
+	doneCh    chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+var disableLogsOnce sync.Once
+
+// New starts a new simulated chain
+func New(opts ...Option) (*Chain, error) {
+	disableLogsOnce.Do(func() {
+		// the node logs every block and transaction
+		log.SetDefault(log.NewLogger(log.DiscardHandler()))
+	})
+
+	config := &Config{
+		Balance:  DefaultBalance,
+		GasLimit: ethconfig.Defaults.Miner.GasCeil,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if len(config.Keys) == 0 {
+		config.Keys = []*wallet.Key{wallet.KeyFromString(testutil.FromKey)}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "ethgo-simulated-")
+	if err != nil {
+		return nil, err
+	}
+	s := &Chain{
+		config:  config,
+		tmpDir:  tmpDir,
+		closeCh: make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+	if err := s.start(); err != nil {
+		if s.stack != nil {
+			s.stack.Close()
+		}
+		os.RemoveAll(tmpDir)
+		return nil, err
+	}
+	go s.run()
+
+	return s, nil
+}
+
+func (s *Chain) start() error {
+	var err error
+
+	nodeConf := node.DefaultConfig
+	nodeConf.DataDir = ""
+	nodeConf.P2P = p2p.Config{NoDiscovery: true}
+
+	// http and websocket share the same random port
+	nodeConf.HTTPHost = "127.0.0.1"
+	nodeConf.HTTPPort = 0
+	nodeConf.HTTPModules = []string{"eth", "net", "web3", "debug", "txpool", "evm"}
+	nodeConf.WSHost = nodeConf.HTTPHost
+	nodeConf.WSPort = nodeConf.HTTPPort
+	nodeConf.WSModules = nodeConf.HTTPModules
+
+	if s.stack, err = node.New(&nodeConf); err != nil {
+		return err
+	}
+
+	// the accounts are unlocked in a keystore to sign the
+	// transactions sent with eth_sendTransaction
+	ks := keystore.NewKeyStore(s.tmpDir, keystore.LightScryptN, keystore.LightScryptP)
+	alloc := types.GenesisAlloc{}
+	for _, key := range s.config.Keys {
+		priv, err := key.MarshallPrivateKey()
+		if err != nil {
+			return err
+		}
+		ecdsaKey, err := crypto.ToECDSA(priv)
+		if err != nil {
+			return err
+		}
+		account, err := ks.ImportECDSA(ecdsaKey, "")
+		if err != nil {
+			return err
+		}
+		if err := ks.Unlock(account, ""); err != nil {
+			return err
+		}
+		alloc[account.Address] = types.Account{Balance: s.config.Balance}
+	}
+	s.stack.AccountManager().AddBackend(ks)
+
+	chainConfig := *params.AllDevChainProtocolChanges
+	chainConfig.ChainID = new(big.Int).SetUint64(core.Local)
+
+	ethConf := ethconfig.Defaults
+	ethConf.Genesis = &gethcore.Genesis{
+		Config:   &chainConfig,
+		GasLimit: s.config.GasLimit,
+		Alloc:    alloc,
+	}
+	ethConf.SyncMode = downloader.FullSync
+	ethConf.Miner.GasCeil = s.config.GasLimit
+
+	if s.backend, err = eth.New(s.stack, &ethConf); err != nil {
+		return err
+	}
+
+	filterSystem := filters.NewFilterSystem(s.backend.APIBackend, filters.Config{})
+	s.stack.RegisterAPIs([]rpc.API{
+		{
+			Namespace: "eth",
+			Service:   filters.NewFilterAPI(filterSystem),
+		},
+		{
+			Namespace: "evm",
+			Service:   &evmAPI{s},
+		},
+	})
+	s.stack.RegisterAPIs(tracers.APIs(s.backend.APIBackend))
+
+	if err := s.stack.Start(); err != nil {
+		return err
+	}
+	if s.beacon, err = catalyst.NewSimulatedBeacon(0, s.backend); err != nil {
+		return err
+	}
+
+	// the node reports that the transaction indexing is in progress (and fails
+	// to return the receipts of the pending transactions) until the first block
+	// is indexed. Mine it and wait for the indexer.
+	s.Mine()
+	for i := 0; ; i++ {
+		progress, err := s.backend.BlockChain().TxIndexProgress()
+		if err == nil && progress.Done() {
+			break
+		}
+		if i == 100 {
+			return fmt.Errorf("timeout waiting for the transaction indexer")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil
+}
+
+// run mines the pending transactions
+func (s *Chain) run() {
+	defer close(s.doneCh)
+
+	var txCh chan gethcore.NewTxsEvent
+	var tickCh <-chan time.Time
+
+	if s.config.Period == 0 {
+		txCh = make(chan gethcore.NewTxsEvent, 16)
+		sub := s.backend.TxPool().SubscribeTransactions(txCh, true)
+		defer sub.Unsubscribe()
+	} else {
+		ticker := time.NewTicker(s.config.Period)
+		defer ticker.Stop()
+
+		tickCh = ticker.C
+	}
+
+	for {
+		select {
+		case <-txCh:
+			s.minePending()
+		case <-tickCh:
+			s.Mine()
+		case <-s.closeCh:
+			return
+		}
+	}
+}
+
+// minePending mines a new block if there are pending transactions, they
+// might have been mined already with a previous event or dropped by Revert
+func (s *Chain) minePending() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if pending, _ := s.backend.TxPool().Stats(); pending != 0 {
+		s.beacon.Commit()
+	}
+}
+
+// Mine mines a new block with the pending transactions and returns its hash
+func (s *Chain) Mine() core.Hash {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return core.Hash(s.beacon.Commit())
+}
+
+// Snapshot saves the head of the chain to revert to it later with Revert
+func (s *Chain) Snapshot() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.snapshots = append(s.snapshots, s.backend.BlockChain().CurrentBlock().Hash())
+	return len(s.snapshots) - 1
+}
+
+// Revert rewinds the chain to the snapshot. The blocks mined after the snapshot
+// are dropped and the next block is mined on top of the snapshot, which can be
+// used to simulate a reorg.
+func (s *Chain) Revert(id int) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if id < 0 || id >= len(s.snapshots) {
+		return fmt.Errorf("snapshot %d not found", id)
+	}
+	if err := s.beacon.Fork(s.snapshots[id]); err != nil {
+		return err
+	}
+	// the transactions of the dropped blocks are added back
+	// to the pool after the reorg, discard them
+	if err := s.backend.TxPool().Sync(); err != nil {
+		return err
+	}
+	s.backend.TxPool().Clear()
+
+	s.snapshots = s.snapshots[:id]
+	return nil
+}
+
+// Accounts returns the accounts funded at genesis
+func (s *Chain) Accounts() []core.Address {
+	accounts := []core.Address{}
+	for _, key := range s.config.Keys {
+		accounts = append(accounts, key.Address())
+	}
+	return accounts
+}
+
+// HTTPAddr returns the http endpoint
+func (s *Chain) HTTPAddr() string {
+	return s.stack.HTTPEndpoint()
+}
+
+// WSAddr returns the websocket endpoint
+func (s *Chain) WSAddr() string {
+	return s.stack.WSEndpoint()
+}
+
+// Transport returns a transport that sends the requests in-process
+func (s *Chain) Transport() transport.Transport {
+	return &simulatedTransport{client: s.stack.Attach()}
+}
+
+// Close stops the chain. It is safe to call it more than once.
+func (s *Chain) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closeCh)
+		<-s.doneCh
+
+		s.closeErr = s.stack.Close()
+		os.RemoveAll(s.tmpDir)
+	})
+	return s.closeErr
+}
+
+// evmAPI are the evm_* development methods of the simulated chain
+type evmAPI struct {
+	s *Chain
+}
+
+// Mine mines a new block
+func (e *evmAPI) Mine() string {
+	e.s.Mine()
+	return "0x0"
+}
+
+// Snapshot saves the head of the chain
+func (e *evmAPI) Snapshot() string {
+	return fmt.Sprintf("0x%x", e.s.Snapshot())
+}
+
+// Revert rewinds the chain to a snapshot
+func (e *evmAPI) Revert(id string) (bool, error) {
+	var num uint64
+	if _, err := fmt.Sscanf(id, "0x%x", &num); err != nil {
+		return false, err
+	}
+	if err := e.s.Revert(int(num)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// simulatedTransport is the transport of the in-process rpc client
+type simulatedTransport struct {
+	client *rpc.Client
+}
+
+// Call implements the transport interface
+func (s *simulatedTransport) Call(method string, out interface{}, params ...interface{}) error {
+	return s.client.Call(out, method, params...)
+}
+
+// Subscribe implements the PubSubTransport interface
+func (s *simulatedTransport) Subscribe(method string, callback func(b []byte), params ...interface{}) (func() error, error) {
+	ch := make(chan json.RawMessage)
+	sub, err := s.client.Subscribe(context.Background(), "eth", ch, append([]interface{}{method}, params...)...)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			select {
+			case b := <-ch:
+				callback(b)
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+
+	cancel := func() error {
+		sub.Unsubscribe()
+		return nil
+	}
+	return cancel, nil
+}
+
+// SetMaxConnsPerHost implements the transport interface
+func (s *simulatedTransport) SetMaxConnsPerHost(count int) {
+}
+
+// Close implements the transport interface
+func (s *simulatedTransport) Close() error {
+	s.client.Close()
+	return nil
+}
@@ -0,0 +1,116 @@
+package simulated
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/jsonrpc"
+	"github.com/deep-nl/ethgo/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulated_Transport(t *testing.T) {
+	s, chain := NewTestServer(t)
+
+	c := jsonrpc.NewClientWithTransport(chain.Transport())
+	defer c.Close()
+
+	chainID, err := c.Eth().ChainID()
+	require.NoError(t, err)
+	assert.Equal(t, core.Local, chainID.Uint64())
+
+	balance, err := c.Eth().GetBalance(s.Account(0), core.Latest)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultBalance, balance)
+
+	// contract that returns 42 on any call
+	code := []byte{
+		0x60, 0x0a, 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, 0x0a, 0x60, 0x00, 0xf3,
+		0x60, 0x2a, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3,
+	}
+	receipt, err := s.SendTxn(&core.Transaction{
+		Input: code,
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), receipt.Status)
+
+	res, err := s.Call(&core.CallMsg{
+		To: &receipt.ContractAddress,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "0x000000000000000000000000000000000000000000000000000000000000002a", res)
+}
+
+func TestSimulated_Subscribe(t *testing.T) {
+	s, chain := NewTestServer(t)
+
+	c := jsonrpc.NewClientWithTransport(chain.Transport())
+	defer c.Close()
+
+	require.True(t, c.SubscriptionEnabled())
+
+	headCh := make(chan []byte, 1)
+	cancel, err := c.Subscribe("newHeads", func(b []byte) {
+		headCh <- b
+	})
+	require.NoError(t, err)
+	defer cancel()
+
+	require.NoError(t, s.ProcessBlock())
+
+	select {
+	case b := <-headCh:
+		var block core.Block
+		require.NoError(t, block.UnmarshalJSON(b))
+		assert.NotZero(t, block.Number)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+func TestSimulated_SnapshotRevert(t *testing.T) {
+	s, _ := NewTestServer(t)
+
+	c, err := jsonrpc.NewClient(s.HTTPAddr())
+	require.NoError(t, err)
+
+	num, err := c.Eth().BlockNumber()
+	require.NoError(t, err)
+
+	id, err := c.Miner().Snapshot()
+	require.NoError(t, err)
+
+	require.NoError(t, c.Miner().Mine(0))
+	_, err = s.Transfer(testutil.DummyAddr, big.NewInt(1))
+	require.NoError(t, err)
+
+	newNum, err := c.Eth().BlockNumber()
+	require.NoError(t, err)
+	assert.Equal(t, num+2, newNum)
+
+	ok, err := c.Miner().Revert(id)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	newNum, err = c.Eth().BlockNumber()
+	require.NoError(t, err)
+	assert.Equal(t, num, newNum)
+
+	balance, err := c.Eth().GetBalance(testutil.DummyAddr, core.Latest)
+	require.NoError(t, err)
+	assert.Equal(t, 0, balance.Sign())
+
+	// the snapshot is removed after the revert
+	_, err = c.Miner().Revert(id)
+	require.Error(t, err)
+}
+
+func TestSimulated_CloseTwice(t *testing.T) {
+	_, chain := NewTestServer(t)
+
+	require.NoError(t, chain.Close())
+	require.NoError(t, chain.Close())
+}
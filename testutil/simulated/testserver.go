@@ -0,0 +1,32 @@
+package simulated
+
+import (
+	"testing"
+
+	"github.com/deep-nl/ethgo/testutil"
+)
+
+// NewTestServer starts a simulated chain that is closed at the end
+// of the test and returns a test server connected to it
+func NewTestServer(t *testing.T, opts ...Option) (*testutil.TestServer, *Chain) {
+	c, err := New(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		c.Close()
+	})
+	return testutil.NewTestServer(t, c.HTTPAddr(), c.WSAddr()), c
+}
+
+// MultiAddr starts a simulated chain and runs the callback with its
+// http and websocket endpoints
+func MultiAddr(t *testing.T, c func(s *testutil.TestServer, addr string), opts ...Option) {
+	s, chain := NewTestServer(t, opts...)
+
+	// http addr
+	c(s, chain.HTTPAddr())
+
+	// ws addr
+	c(s, chain.WSAddr())
+}
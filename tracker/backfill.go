@@ -0,0 +1,294 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/deep-nl/ethgo/core"
+)
+
+// SyncProgress is the progress of the historical sync
+type SyncProgress struct {
+	// From and To are the range of blocks of the sync
+	From uint64
+	To   uint64
+
+	// Current is the last block committed to the store
+	Current uint64
+
+	// BlocksPerSecond is the average sync speed
+	BlocksPerSecond float64
+
+	// ETA is the estimated time to finish the sync
+	ETA time.Duration
+}
+
+// defaultBatchTargetLogs is the number of logs per eth_getLogs range the
+// batchSizer aims for, half of the 10000 results limit of most providers
+const defaultBatchTargetLogs = 5000
+
+// batchSizer adapts the size of the eth_getLogs ranges to the responses of the
+// provider with a multiplicative decrease and an additive increase. The size is
+// also reduced when a range returns more logs than the target.
+type batchSizer struct {
+	lock   sync.Mutex
+	size   uint64
+	max    uint64
+	target uint64
+}
+
+func newBatchSizer(max uint64) *batchSizer {
+	if max == 0 {
+		max = 1
+	}
+	return &batchSizer{size: max, max: max, target: defaultBatchTargetLogs}
+}
+
+func (b *batchSizer) get() uint64 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.size
+}
+
+func (b *batchSizer) decrease() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.size > 1 {
+		b.size = b.size / 2
+	}
+}
+
+func (b *batchSizer) increase() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.size < b.max {
+		additiveFactor := uint64(float64(b.max) * 0.10)
+		if additiveFactor == 0 {
+			additiveFactor = 1
+		}
+		b.size = min(b.max, b.size+additiveFactor)
+	}
+}
+
+// adapt updates the size with the number of logs returned by a range of blocks.
+// If there are more logs than the target, the size is reduced to the number of
+// blocks that would return the target at the same density.
+func (b *batchSizer) adapt(blocks, logs uint64) {
+	if logs <= b.target {
+		b.increase()
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	size := blocks * b.target / logs
+	if size == 0 {
+		size = 1
+	}
+	b.size = min(b.size, size)
+}
+
+type batchResult struct {
+	from  uint64
+	to    uint64
+	logs  []*core.Log
+	block *core.Block
+}
+
+// syncBatch syncs the logs between from and to (inclusive) with a pool of
+// workers. The ranges are committed to the store in order and the last block
+// is stored after each range so that an interrupted sync can be resumed.
+func (t *Tracker) syncBatch(ctx context.Context, from, to uint64) error {
+	parentCtx := ctx
+	ctx, cancelFn := context.WithCancel(ctx)
+	defer cancelFn()
+
+	workers := t.config.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	sizer := newBatchSizer(t.config.BatchSize)
+
+	rangeCh := make(chan [2]uint64)
+	resCh := make(chan *batchResult)
+	errCh := make(chan error, 1)
+
+	// slots limits the number of ranges that are either in
+	// flight or waiting to be committed
+	slots := make(chan struct{}, 2*workers)
+
+	go func() {
+		defer close(rangeCh)
+
+		for i := from; ; {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			dst := to
+			if size := sizer.get(); to-i > size {
+				dst = i + size
+			}
+			select {
+			case rangeCh <- [2]uint64{i, dst}:
+			case <-ctx.Done():
+				return
+			}
+			if dst == to {
+				return
+			}
+			i = dst + 1
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for r := range rangeCh {
+				res, err := t.fetchBatch(sizer, r[0], r[1])
+				if err != nil {
+					select {
+					case errCh <- err:
+					default:
+					}
+					cancelFn()
+					return
+				}
+				select {
+				case resCh <- res:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(resCh)
+	}()
+
+	start := time.Now()
+	pending := map[uint64]*batchResult{}
+	next := from
+
+	for res := range resCh {
+		pending[res.from] = res
+
+		for {
+			res, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+
+			if err := t.commitBatch(res); err != nil {
+				return err
+			}
+			t.emitProgress(start, from, to, res.to)
+
+			<-slots
+			next = res.to + 1
+		}
+	}
+
+	select {
+	case err := <-errCh:
+		return err
+	default:
+	}
+	if err := parentCtx.Err(); err != nil {
+		return err
+	}
+	if next <= to {
+		return fmt.Errorf("sync stopped at block %d before %d", next, to)
+	}
+	return nil
+}
+
+// fetchBatch queries the logs of the range and the block at the end of it
+func (t *Tracker) fetchBatch(sizer *batchSizer, from, to uint64) (*batchResult, error) {
+	logs, err := t.fetchLogs(sizer, from, to)
+	if err != nil {
+		return nil, err
+	}
+	block, err := t.provider.GetBlockByNumber(core.BlockNumber(to), false)
+	if err != nil {
+		return nil, err
+	}
+	res := &batchResult{
+		from:  from,
+		to:    to,
+		logs:  logs,
+		block: block,
+	}
+	return res, nil
+}
+
+// fetchLogs queries the logs of the range and splits it in
+// two if the provider returns too many results
+func (t *Tracker) fetchLogs(sizer *batchSizer, from, to uint64) ([]*core.Log, error) {
+	query := t.config.Filter.getFilterSearch()
+	query.SetFromUint64(from)
+	query.SetToUint64(to)
+
+	logs, err := t.provider.GetLogs(query)
+	if err == nil {
+		sizer.adapt(to-from+1, uint64(len(logs)))
+		return logs, nil
+	}
+	if !tooMuchDataRequestedError(err) || from == to {
+		return nil, err
+	}
+
+	sizer.decrease()
+
+	mid := from + (to-from)/2
+	left, err := t.fetchLogs(sizer, from, mid)
+	if err != nil {
+		return nil, err
+	}
+	right, err := t.fetchLogs(sizer, mid+1, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+func (t *Tracker) commitBatch(res *batchResult) error {
+	// add logs to the store
+	if err := t.entry.StoreLogs(res.logs); err != nil {
+		return err
+	}
+	t.emitLogs(EventAdd, res.logs)
+
+	// update the last block entry
+	return t.storeLastBlock(res.block)
+}
+
+func (t *Tracker) emitProgress(start time.Time, from, to, current uint64) {
+	if t.SyncCh == nil {
+		return
+	}
+
+	progress := &SyncProgress{
+		From:    from,
+		To:      to,
+		Current: current,
+	}
+	if elapsed := time.Since(start).Seconds(); elapsed > 0 {
+		progress.BlocksPerSecond = float64(current-from+1) / elapsed
+		progress.ETA = time.Duration(float64(to-current) / progress.BlocksPerSecond * float64(time.Second))
+	}
+
+	select {
+	case t.SyncCh <- progress:
+	default:
+	}
+}
@@ -0,0 +1,138 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/testutil"
+	"github.com/deep-nl/ethgo/tracker/store/inmem"
+	"github.com/stretchr/testify/require"
+)
+
+// failingMockClient fails the range queries that include the block
+// 'failAt' while 'fail' is set
+type failingMockClient struct {
+	*testutil.MockClient
+	failAt uint64
+	fail   int32
+}
+
+func (f *failingMockClient) GetLogs(filter *core.LogFilter) ([]*core.Log, error) {
+	if filter.BlockHash == nil && atomic.LoadInt32(&f.fail) == 1 {
+		failAt := atomic.LoadUint64(&f.failAt)
+		if uint64(*filter.From) <= failAt && failAt <= uint64(*filter.To) {
+			return nil, fmt.Errorf("failed")
+		}
+	}
+	return f.MockClient.GetLogs(filter)
+}
+
+func TestBatchSizer(t *testing.T) {
+	b := newBatchSizer(100)
+	b.decrease()
+	b.decrease()
+	require.Equal(t, uint64(25), b.get())
+
+	b.increase()
+	require.Equal(t, uint64(35), b.get())
+
+	for i := 0; i < 10; i++ {
+		b.increase()
+	}
+	require.Equal(t, uint64(100), b.get())
+
+	// ranges with more logs than the target reduce the size
+	b.target = 50
+	b.adapt(100, 200)
+	require.Equal(t, uint64(25), b.get())
+
+	// the size is not increased by a denser range
+	b.adapt(10, 100)
+	require.Equal(t, uint64(5), b.get())
+	b.adapt(5, 10)
+	require.Equal(t, uint64(15), b.get())
+}
+
+func TestParallelBackfill(t *testing.T) {
+	l := testutil.MockList{}
+	l.Create(0, 500, func(b *testutil.MockBlock) {
+		for i := 0; i < b.GetNum()%3; i++ {
+			b.Log(fmt.Sprintf("0x%x", b.GetNum()%256))
+		}
+	})
+
+	mm := &mockClientWithLimit{
+		limit: 7,
+	}
+	mm.AddScenario(l)
+
+	tt, err := NewTracker(mm,
+		WithBatchSize(20),
+		WithWorkers(4),
+		WithFilter(&FilterConfig{Async: true}),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, tt.syncBatch(context.Background(), 0, 499))
+	require.True(t, testutil.CompareLogs(l.GetLogs(), tt.entry.(*inmem.Entry).Logs()))
+
+	last, err := tt.GetLastBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(499), last.Number)
+
+	progress := <-tt.SyncCh
+	require.Equal(t, uint64(0), progress.From)
+	require.Equal(t, uint64(499), progress.To)
+}
+
+func TestParallelBackfill_Resume(t *testing.T) {
+	l := testutil.MockList{}
+	l.Create(0, 300, func(b *testutil.MockBlock) {
+		b.Log("0x1")
+	})
+
+	m := &testutil.MockClient{}
+	m.AddScenario(l)
+
+	fm := &failingMockClient{
+		MockClient: m,
+		failAt:     150,
+		fail:       1,
+	}
+
+	store := inmem.NewInmemStore()
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	tt0, err := NewTracker(fm,
+		WithBatchSize(10),
+		WithWorkers(8),
+		WithStore(store),
+		WithFilter(&FilterConfig{Async: true}),
+	)
+	require.NoError(t, err)
+	require.Error(t, tt0.BatchSync(ctx))
+
+	// only the ranges before the failure are committed
+	last, err := tt0.GetLastBlock()
+	require.NoError(t, err)
+	require.Less(t, last.Number, uint64(150))
+	require.True(t, testutil.CompareLogs(l.GetLogs()[:last.Number+1], tt0.entry.(*inmem.Entry).Logs()))
+
+	// resume from the checkpoint, the committed ranges fail if they are queried again
+	atomic.StoreUint64(&fm.failAt, last.Number)
+
+	tt1, err := NewTracker(fm,
+		WithBatchSize(10),
+		WithWorkers(8),
+		WithStore(store),
+		WithFilter(&FilterConfig{Async: true}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, tt1.BatchSync(ctx))
+	require.True(t, testutil.CompareLogs(l.GetLogs(), tt1.entry.(*inmem.Entry).Logs()))
+}
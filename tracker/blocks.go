@@ -0,0 +1,365 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/tracker/store"
+)
+
+const defaultReceiptsBatchSize = 20
+
+// IndexedBlock is a block with its full transactions and their receipts
+type IndexedBlock struct {
+	Block *core.Block `json:"block"`
+
+	// Receipts are the receipts of the transactions of the block in the same order
+	Receipts []*core.Receipt `json:"receipts"`
+}
+
+// Transfers returns the transactions of the block that transfer ether
+func (b *IndexedBlock) Transfers() []*core.Transaction {
+	res := []*core.Transaction{}
+	for _, txn := range b.Block.Transactions {
+		if txn.Value != nil && txn.Value.Sign() > 0 {
+			res = append(res, txn)
+		}
+	}
+	return res
+}
+
+// Failed returns the transactions of the block that were reverted
+func (b *IndexedBlock) Failed() []*core.Transaction {
+	res := []*core.Transaction{}
+	for indx, txn := range b.Block.Transactions {
+		if b.Receipts[indx].Status == 0 {
+			res = append(res, txn)
+		}
+	}
+	return res
+}
+
+// BlockStore persists the blocks indexed by the tracker
+type BlockStore interface {
+	// StoreBlock stores a block, it replaces any block with the same number
+	StoreBlock(b *IndexedBlock) error
+
+	// GetBlock returns the block with the number or nil if there is none
+	GetBlock(num uint64) (*IndexedBlock, error)
+
+	// RemoveBlocks removes the blocks starting at the number
+	// and returns them in ascending order
+	RemoveBlocks(num uint64) ([]*IndexedBlock, error)
+
+	// PruneBlocks removes the blocks before the number
+	PruneBlocks(num uint64) error
+}
+
+// kvBlockStore is a BlockStore over the values of the tracker store. Each
+// block is stored in its own key with the range of stored blocks.
+type kvBlockStore struct {
+	lock  sync.Mutex
+	store store.Store
+	hash  string
+}
+
+func newKVBlockStore(s store.Store, hash string) *kvBlockStore {
+	return &kvBlockStore{
+		store: s,
+		hash:  hash,
+	}
+}
+
+func (k *kvBlockStore) key(num uint64) string {
+	return dbBlock + "_" + k.hash + "_" + strconv.FormatUint(num, 10)
+}
+
+// getRange returns the first and last numbers of the stored blocks
+func (k *kvBlockStore) getRange() (uint64, uint64, bool, error) {
+	first, err := k.store.Get(dbBlocksFirst + "_" + k.hash)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	last, err := k.store.Get(dbBlocksLast + "_" + k.hash)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if first == "" || last == "" {
+		return 0, 0, false, nil
+	}
+	firstNum, err := strconv.ParseUint(first, 10, 64)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	lastNum, err := strconv.ParseUint(last, 10, 64)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return firstNum, lastNum, true, nil
+}
+
+func (k *kvBlockStore) setRange(first, last uint64) error {
+	if err := k.store.Set(dbBlocksFirst+"_"+k.hash, strconv.FormatUint(first, 10)); err != nil {
+		return err
+	}
+	return k.store.Set(dbBlocksLast+"_"+k.hash, strconv.FormatUint(last, 10))
+}
+
+func (k *kvBlockStore) clearRange() error {
+	if err := k.store.Delete(dbBlocksFirst + "_" + k.hash); err != nil {
+		return err
+	}
+	return k.store.Delete(dbBlocksLast + "_" + k.hash)
+}
+
+// StoreBlock implements the BlockStore interface
+func (k *kvBlockStore) StoreBlock(b *IndexedBlock) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	block := b.Block
+	if block.Difficulty == nil {
+		// the block might be shared with other trackers
+		block = block.Copy()
+		block.Difficulty = big.NewInt(0)
+	}
+	buf, err := json.Marshal(&IndexedBlock{Block: block, Receipts: b.Receipts})
+	if err != nil {
+		return err
+	}
+	if err := k.store.Set(k.key(block.Number), string(buf)); err != nil {
+		return err
+	}
+
+	first, last, ok, err := k.getRange()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		first, last = block.Number, block.Number
+	}
+	return k.setRange(min(first, block.Number), max(last, block.Number))
+}
+
+// GetBlock implements the BlockStore interface
+func (k *kvBlockStore) GetBlock(num uint64) (*IndexedBlock, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	return k.getBlock(num)
+}
+
+func (k *kvBlockStore) getBlock(num uint64) (*IndexedBlock, error) {
+	buf, err := k.store.Get(k.key(num))
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) == 0 {
+		return nil, nil
+	}
+	b := &IndexedBlock{}
+	if err := json.Unmarshal([]byte(buf), b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// RemoveBlocks implements the BlockStore interface
+func (k *kvBlockStore) RemoveBlocks(num uint64) ([]*IndexedBlock, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	first, last, ok, err := k.getRange()
+	if err != nil {
+		return nil, err
+	}
+	if !ok || num > last {
+		return nil, nil
+	}
+
+	res := []*IndexedBlock{}
+	for i := max(first, num); i <= last; i++ {
+		b, err := k.getBlock(i)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			continue
+		}
+		if err := k.store.Delete(k.key(i)); err != nil {
+			return nil, err
+		}
+		res = append(res, b)
+	}
+
+	if num <= first {
+		return res, k.clearRange()
+	}
+	return res, k.setRange(first, num-1)
+}
+
+// PruneBlocks implements the BlockStore interface
+func (k *kvBlockStore) PruneBlocks(num uint64) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	first, last, ok, err := k.getRange()
+	if err != nil {
+		return err
+	}
+	if !ok || num <= first {
+		return nil
+	}
+
+	for i := first; i < num && i <= last; i++ {
+		if err := k.store.Delete(k.key(i)); err != nil {
+			return err
+		}
+	}
+
+	if num > last {
+		return k.clearRange()
+	}
+	return k.setRange(num, last)
+}
+
+// blockReceiptsProvider is a provider with the eth_getBlockReceipts endpoint
+type blockReceiptsProvider interface {
+	GetBlockReceipts(block core.BlockNumberOrHash) ([]*core.Receipt, error)
+}
+
+// receiptProvider is a provider with the eth_getTransactionReceipt endpoint
+type receiptProvider interface {
+	GetTransactionReceipt(hash core.Hash) (*core.Receipt, error)
+}
+
+func hasReceipts(provider Provider) bool {
+	if _, ok := provider.(blockReceiptsProvider); ok {
+		return true
+	}
+	_, ok := provider.(receiptProvider)
+	return ok
+}
+
+// indexBlocks fetches the full transactions and the receipts of the
+// blocks and stores them in the block store
+func (t *Tracker) indexBlocks(blocks []*core.Block) ([]*IndexedBlock, error) {
+	res := []*IndexedBlock{}
+	for _, block := range blocks {
+		// We need to do a retry to let unsynced nodes get the block
+		var b *IndexedBlock
+		var err error
+
+		for i := 0; i < 5; i++ {
+			b, err = t.getBlock(block.Hash)
+			if err == nil {
+				break
+			}
+			time.Sleep(500 * time.Millisecond)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := t.blockStore.StoreBlock(b); err != nil {
+			return nil, err
+		}
+		res = append(res, b)
+	}
+	return res, nil
+}
+
+// removeBlocks removes the indexed blocks starting at the number
+func (t *Tracker) removeBlocks(num uint64) ([]*IndexedBlock, error) {
+	if t.blockStore == nil {
+		return nil, nil
+	}
+	return t.blockStore.RemoveBlocks(num)
+}
+
+func (t *Tracker) fetchBlock(hash core.Hash) (*IndexedBlock, error) {
+	block, err := t.provider.GetBlockByHash(hash, true)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", hash)
+	}
+	receipts, err := t.getReceipts(block)
+	if err != nil {
+		return nil, err
+	}
+	return &IndexedBlock{Block: block, Receipts: receipts}, nil
+}
+
+// getReceipts returns the receipts of the transactions of the block. It uses
+// eth_getBlockReceipts if the provider has it, otherwise the receipts are
+// queried concurrently in batches of ReceiptsBatchSize.
+func (t *Tracker) getReceipts(block *core.Block) ([]*core.Receipt, error) {
+	if len(block.Transactions) == 0 {
+		return []*core.Receipt{}, nil
+	}
+
+	if provider, ok := t.provider.(blockReceiptsProvider); ok {
+		receipts, err := provider.GetBlockReceipts(block.Hash)
+		if err == nil {
+			return receipts, checkReceipts(block, receipts)
+		}
+		if _, ok := t.provider.(receiptProvider); !ok {
+			return nil, err
+		}
+		t.logger.Printf("[DEBUG]: failed to get the receipts of block %d, query them by transaction: %v", block.Number, err)
+	}
+	provider := t.provider.(receiptProvider)
+
+	batchSize := t.config.ReceiptsBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultReceiptsBatchSize
+	}
+
+	txns := block.Transactions
+	receipts := make([]*core.Receipt, len(txns))
+	errs := make([]error, len(txns))
+
+	for i := 0; i < len(txns); i += batchSize {
+		end := i + batchSize
+		if end > len(txns) {
+			end = len(txns)
+		}
+
+		var wg sync.WaitGroup
+		for j := i; j < end; j++ {
+			wg.Add(1)
+			go func(j int) {
+				defer wg.Done()
+				receipts[j], errs[j] = provider.GetTransactionReceipt(txns[j].Hash)
+			}(j)
+		}
+		wg.Wait()
+
+		for _, err := range errs[i:end] {
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return receipts, checkReceipts(block, receipts)
+}
+
+// checkReceipts checks that the receipts match the transactions of the block
+func checkReceipts(block *core.Block, receipts []*core.Receipt) error {
+	if len(receipts) != len(block.Transactions) {
+		return fmt.Errorf("block %d has %d transactions but %d receipts", block.Number, len(block.Transactions), len(receipts))
+	}
+	for indx, txn := range block.Transactions {
+		if receipts[indx] == nil || receipts[indx].TransactionHash != txn.Hash {
+			return fmt.Errorf("receipt of transaction %s not found", txn.Hash)
+		}
+	}
+	return nil
+}
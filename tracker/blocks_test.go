@@ -0,0 +1,200 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// receiptsMockClient adds a transfer and a failed transaction to each block
+type receiptsMockClient struct {
+	*testutil.MockClient
+	calls int32
+}
+
+func mockTxnHash(block core.Hash, i int) core.Hash {
+	// the hashes of the mock blocks start with zeros
+	hash := block
+	hash[0] = byte(i + 1)
+	return hash
+}
+
+func (m *receiptsMockClient) GetBlockByHash(hash core.Hash, full bool) (*core.Block, error) {
+	b, err := m.MockClient.GetBlockByHash(hash, full)
+	if err != nil || !full {
+		return b, err
+	}
+	b = b.Copy()
+	for i := 0; i < 2; i++ {
+		b.Transactions = append(b.Transactions, &core.Transaction{
+			Hash:        mockTxnHash(b.Hash, i),
+			BlockHash:   b.Hash,
+			BlockNumber: b.Number,
+			TxnIndex:    uint64(i),
+			Value:       big.NewInt(int64(1 - i)),
+		})
+	}
+	return b, nil
+}
+
+func (m *receiptsMockClient) GetTransactionReceipt(hash core.Hash) (*core.Receipt, error) {
+	atomic.AddInt32(&m.calls, 1)
+
+	blockHash := hash
+	blockHash[0] = 0
+	b, err := m.MockClient.GetBlockByHash(blockHash, false)
+	if err != nil {
+		return nil, err
+	}
+	i := uint64(hash[0] - 1)
+	return &core.Receipt{
+		TransactionHash:  hash,
+		TransactionIndex: i,
+		BlockHash:        b.Hash,
+		BlockNumber:      b.Number,
+		Status:           1 - i,
+	}, nil
+}
+
+func TestTrackerBlockIndexing(t *testing.T) {
+	m, l := newHandlerClient()
+	client := &receiptsMockClient{MockClient: m}
+
+	// the provider must have the receipts
+	_, err := NewTracker(m, WithBlockIndexing())
+	require.Error(t, err)
+
+	tt, err := NewTracker(client,
+		testConfig(),
+		WithFilter(&FilterConfig{Async: true}),
+		WithBlockIndexing(),
+		WithReceiptsBatchSize(1),
+		WithRetention(KeepBlocks(5)),
+	)
+	require.NoError(t, err)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, tt.Sync(ctx))
+
+	// only the blocks of the backlog are indexed
+	b, err := tt.blockStore.GetBlock(39)
+	require.NoError(t, err)
+	require.Nil(t, b)
+
+	for i := uint64(40); i < 50; i++ {
+		b, err := tt.blockStore.GetBlock(i)
+		require.NoError(t, err)
+		require.NotNil(t, b)
+		require.Equal(t, l[i].Hash(), b.Block.Hash)
+		require.Len(t, b.Receipts, 2)
+
+		require.Len(t, b.Transfers(), 1)
+		require.Equal(t, mockTxnHash(b.Block.Hash, 0), b.Transfers()[0].Hash)
+		require.Len(t, b.Failed(), 1)
+		require.Equal(t, mockTxnHash(b.Block.Hash, 1), b.Failed()[0].Hash)
+	}
+	require.Equal(t, int32(20), atomic.LoadInt32(&client.calls))
+
+	// the blocks are stored as json
+	raw, err := tt.store.Get(tt.blockStore.(*kvBlockStore).key(40))
+	require.NoError(t, err)
+	require.True(t, json.Valid([]byte(raw)))
+
+	// reorg of the last two blocks
+	fork := testutil.MockList{}
+	fork.Create(48, 50, func(b *testutil.MockBlock) {
+		b.Extra("b")
+	})
+	m.AddScenario(fork)
+
+	evnt, err := tt.doFilter(fork.ToBlocks(), l.ToBlocks()[48:])
+	require.NoError(t, err)
+
+	require.Len(t, evnt.RemovedBlocks, 2)
+	require.Len(t, evnt.AddedBlocks, 2)
+	for i := 0; i < 2; i++ {
+		require.Equal(t, l[48+i].Hash(), evnt.RemovedBlocks[i].Block.Hash)
+		require.Equal(t, fork[i].Hash(), evnt.AddedBlocks[i].Block.Hash)
+	}
+
+	b, err = tt.blockStore.GetBlock(49)
+	require.NoError(t, err)
+	require.Equal(t, fork[1].Hash(), b.Block.Hash)
+	require.Equal(t, fork[1].Hash(), b.Receipts[1].BlockHash)
+
+	// the blocks of the backlog are not pruned with the retention policies
+	require.NoError(t, tt.Compact())
+
+	b, err = tt.blockStore.GetBlock(40)
+	require.NoError(t, err)
+	require.NotNil(t, b)
+
+	// the blocks before the backlog are pruned
+	require.NoError(t, tt.blockStore.StoreBlock(&IndexedBlock{Block: l[39].Block(), Receipts: []*core.Receipt{}}))
+	require.NoError(t, tt.Compact())
+
+	b, err = tt.blockStore.GetBlock(39)
+	require.NoError(t, err)
+	require.Nil(t, b)
+}
+
+func TestTrackerBlockIndexing_Failure(t *testing.T) {
+	m, l := newHandlerClient()
+	client := &receiptsMockClient{MockClient: m}
+
+	tt, err := NewTracker(client,
+		testConfig(),
+		WithFilter(&FilterConfig{Async: true}),
+		WithBlockIndexing(),
+	)
+	require.NoError(t, err)
+
+	// the new blocks are processed by the test
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, tt.BatchSync(ctx))
+
+	last, err := tt.entry.LastIndex()
+	require.NoError(t, err)
+
+	next := testutil.MockList{}
+	next.Create(50, 51, func(b *testutil.MockBlock) {
+		b.Log("0x50")
+	})
+	m.AddScenario(next)
+
+	// the block cannot be indexed
+	tt.getBlock = func(core.Hash) (*IndexedBlock, error) {
+		return nil, fmt.Errorf("failed")
+	}
+	_, err = tt.doFilter(next.ToBlocks(), nil)
+	require.Error(t, err)
+
+	// the logs and the last block are not stored
+	indx, err := tt.entry.LastIndex()
+	require.NoError(t, err)
+	require.Equal(t, last, indx)
+
+	block, err := tt.GetLastBlock()
+	require.NoError(t, err)
+	require.Equal(t, l[49].Hash(), block.Hash)
+
+	// the block is processed again
+	tt.getBlock = tt.fetchBlock
+
+	evnt, err := tt.doFilter(next.ToBlocks(), nil)
+	require.NoError(t, err)
+	require.Len(t, evnt.Added, 1)
+
+	indx, err = tt.entry.LastIndex()
+	require.NoError(t, err)
+	require.Equal(t, last+1, indx)
+}
@@ -0,0 +1,148 @@
+package tracker
+
+import (
+	"reflect"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/core"
+	"github.com/mitchellh/mapstructure"
+)
+
+// DecodedEvent is a log decoded with one of the events of the filter
+type DecodedEvent struct {
+	// Name is the name of the event
+	Name string
+
+	// Args are the decoded arguments of the event
+	Args map[string]interface{}
+
+	// Value is the pointer to the struct registered for the event
+	// in FilterConfig.Types with the arguments decoded, nil otherwise
+	Value interface{}
+
+	// Log is the raw log
+	Log *core.Log
+
+	// Err is set if the log matches the id of the event but it cannot
+	// be decoded with it (i.e. an ERC721 Transfer decoded as an ERC20
+	// Transfer). Args and Value might not be set then.
+	Err error
+}
+
+// events returns the events of the filter
+func (f *FilterConfig) events() []*abi.Event {
+	events := []*abi.Event{}
+	events = append(events, f.Events...)
+	if f.ABI != nil {
+		for _, evnt := range f.ABI.Events {
+			events = append(events, evnt)
+		}
+	}
+	return events
+}
+
+// buildTopics filters the logs with the ids of the events
+// if the filter does not include any topic
+func (f *FilterConfig) buildTopics() {
+	if len(f.Topics) != 0 {
+		return
+	}
+	events := f.events()
+	if len(events) == 0 {
+		return
+	}
+
+	ids := []*core.Hash{}
+	seen := map[core.Hash]struct{}{}
+	for _, evnt := range events {
+		if evnt.Anonymous {
+			// anonymous events do not include the id in the topics
+			return
+		}
+		id := evnt.ID()
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			ids = append(ids, &id)
+		}
+	}
+	f.Topics = [][]*core.Hash{ids}
+}
+
+// eventDecoder decodes the logs with the events of a filter
+type eventDecoder struct {
+	events map[core.Hash]*abi.Event
+	types  map[string]reflect.Type
+}
+
+func newEventDecoder(f *FilterConfig) *eventDecoder {
+	events := f.events()
+	if len(events) == 0 {
+		return nil
+	}
+
+	d := &eventDecoder{
+		events: map[core.Hash]*abi.Event{},
+		types:  map[string]reflect.Type{},
+	}
+	for _, evnt := range events {
+		if !evnt.Anonymous {
+			d.events[evnt.ID()] = evnt
+		}
+	}
+	for name, obj := range f.Types {
+		typ := reflect.TypeOf(obj)
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		d.types[name] = typ
+	}
+	return d
+}
+
+// decode decodes the logs, the logs that do not match any event are skipped
+// and the logs that fail to decode are returned with the error
+func (d *eventDecoder) decode(logs []*core.Log) []*DecodedEvent {
+	res := []*DecodedEvent{}
+	for _, log := range logs {
+		if len(log.Topics) == 0 {
+			continue
+		}
+		evnt, ok := d.events[log.Topics[0]]
+		if !ok {
+			continue
+		}
+		decoded := &DecodedEvent{
+			Name: evnt.Name,
+			Log:  log,
+		}
+		res = append(res, decoded)
+
+		args, err := evnt.ParseLog(log)
+		if err != nil {
+			decoded.Err = err
+			continue
+		}
+		decoded.Args = args
+
+		if typ, ok := d.types[evnt.Name]; ok {
+			val := reflect.New(typ).Interface()
+
+			dc := &mapstructure.DecoderConfig{
+				Result:           val,
+				WeaklyTypedInput: true,
+				TagName:          "abi",
+			}
+			ms, err := mapstructure.NewDecoder(dc)
+			if err != nil {
+				decoded.Err = err
+				continue
+			}
+			if err := ms.Decode(args); err != nil {
+				decoded.Err = err
+				continue
+			}
+			decoded.Value = val
+		}
+	}
+	return res
+}
@@ -0,0 +1,145 @@
+package tracker
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/deep-nl/ethgo/abi"
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	transferEvent = abi.MustNewEvent("event Transfer(address indexed from, address indexed to, uint256 value)")
+	approvalEvent = abi.MustNewEvent("event Approval(address indexed owner, address indexed spender, uint256 value)")
+)
+
+type transfer struct {
+	From  core.Address
+	To    core.Address
+	Value *big.Int
+}
+
+func newTransferLog(t *testing.T, b *testutil.MockBlock, value int64) *core.Log {
+	data, err := abi.Encode(map[string]interface{}{"value": big.NewInt(value)}, abi.MustNewType("tuple(uint256 value)"))
+	require.NoError(t, err)
+
+	from, to := core.Address{0x1}, core.Address{0x2}
+	log := &core.Log{
+		Topics: []core.Hash{
+			transferEvent.ID(),
+			core.BytesToHash(from[:]),
+			core.BytesToHash(to[:]),
+		},
+		Data:        data,
+		BlockNumber: uint64(b.GetNum()),
+		BlockHash:   b.Hash(),
+	}
+	return log
+}
+
+func TestFilterConfig_BuildTopics(t *testing.T) {
+	f := &FilterConfig{
+		Events: []*abi.Event{transferEvent},
+		ABI: &abi.ABI{
+			Events: map[string]*abi.Event{
+				"Transfer": transferEvent,
+				"Approval": approvalEvent,
+			},
+		},
+	}
+	f.buildTopics()
+	require.Len(t, f.Topics, 1)
+	require.Len(t, f.Topics[0], 2)
+
+	// topics provided by the user are not replaced
+	f = &FilterConfig{
+		Events: []*abi.Event{transferEvent},
+		Topics: [][]*core.Hash{nil},
+	}
+	f.buildTopics()
+	require.Equal(t, [][]*core.Hash{nil}, f.Topics)
+}
+
+func TestTrackerDecodedEvents(t *testing.T) {
+	l := testutil.MockList{}
+	l.Create(0, 20, func(b *testutil.MockBlock) {})
+
+	m := &testutil.MockClient{}
+	m.AddScenario(l)
+
+	// an erc721 transfer has the same id as the erc20 transfer
+	// but the token id is indexed
+	nft := newTransferLog(t, l[5], 0)
+	nft.Topics = append(nft.Topics, core.Hash{0x1})
+	nft.Data = nil
+
+	// one transfer, one nft transfer and one unknown log
+	logs := []*core.Log{
+		nft,
+		newTransferLog(t, l[5], 100),
+		{BlockNumber: 6, BlockHash: l[6].Hash()},
+	}
+	m.AddLogs(logs)
+
+	tt, err := NewTracker(m,
+		testConfig(),
+		WithFilter(&FilterConfig{
+			Events: []*abi.Event{transferEvent},
+			Types: map[string]interface{}{
+				"Transfer": transfer{},
+			},
+		}),
+	)
+	require.NoError(t, err)
+
+	go func() {
+		if err := tt.Sync(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+
+	var decoded []*DecodedEvent
+	for {
+		select {
+		case evnt := <-tt.EventCh:
+			decoded = append(decoded, evnt.AddedEvents...)
+		case <-tt.DoneCh:
+			goto EXIT
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout to sync")
+		}
+	}
+EXIT:
+	require.Len(t, decoded, 2)
+
+	// the nft transfer does not stop the decoding of the other logs
+	require.Equal(t, "Transfer", decoded[0].Name)
+	require.Error(t, decoded[0].Err)
+	require.Equal(t, logs[0], decoded[0].Log)
+	require.Nil(t, decoded[0].Value)
+
+	require.Equal(t, "Transfer", decoded[1].Name)
+	require.NoError(t, decoded[1].Err)
+	require.Equal(t, core.Address{0x2}, decoded[1].Args["to"])
+	require.Equal(t, logs[1], decoded[1].Log)
+
+	obj, ok := decoded[1].Value.(*transfer)
+	require.True(t, ok)
+	require.Equal(t, core.Address{0x1}, obj.From)
+	require.Equal(t, int64(100), obj.Value.Int64())
+
+	// removed logs are decoded too
+	go tt.emitLogs(EventDel, logs[1:])
+
+	select {
+	case evnt := <-tt.EventCh:
+		require.Len(t, evnt.RemovedEvents, 1)
+		require.Equal(t, "Transfer", evnt.RemovedEvents[0].Name)
+	case <-time.After(2 * time.Second):
+		t.Fatal("event expected")
+	}
+}
@@ -0,0 +1,456 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/deep-nl/ethgo/core"
+)
+
+const (
+	defaultRetryInterval    = 1 * time.Second
+	defaultHandlerBatchSize = 100
+)
+
+// HandlerFunc processes the events of the tracker. If it returns an error
+// the same event is delivered again after the retry interval.
+type HandlerFunc func(ctx context.Context, evnt *Event) error
+
+type HandlerOption func(*Handler)
+
+// WithHandlerName sets the name used to persist the cursor of the handler.
+// By default, the handlers are named after the order of registration.
+func WithHandlerName(name string) HandlerOption {
+	return func(h *Handler) {
+		h.name = name
+	}
+}
+
+// WithRetryInterval sets the time to wait before an event is delivered
+// again after the handler fails
+func WithRetryInterval(d time.Duration) HandlerOption {
+	return func(h *Handler) {
+		h.retryInterval = d
+	}
+}
+
+// WithHandlerBatchSize sets the maximum number of logs in each event
+func WithHandlerBatchSize(n uint64) HandlerOption {
+	return func(h *Handler) {
+		h.batchSize = n
+	}
+}
+
+// Handler delivers the logs of the tracker to a HandlerFunc with at-least-once
+// semantics. The handler keeps a cursor in the store with the index of the
+// next log to deliver, which is only moved after the handler succeeds. The
+// logs removed in a reorg after they are delivered are stored in pages of
+// the batch size until the handler processes them as EventDel events.
+type Handler struct {
+	name          string
+	fn            HandlerFunc
+	tracker       *Tracker
+	retryInterval time.Duration
+	batchSize     uint64
+	notifyCh      chan struct{}
+
+	// inflight is the end index of the logs being delivered and gen is
+	// increased every time the cursor is moved by a reorg or a replay.
+	// Both are guarded by the handlers lock of the tracker.
+	inflight uint64
+	gen      uint64
+}
+
+// OnEvent registers a handler for the events of the tracker. The handlers start
+// to deliver the stored logs when the tracker syncs. The events are still sent
+// to EventCh, set FilterConfig.Async if it is not read.
+func (t *Tracker) OnEvent(fn HandlerFunc, opts ...HandlerOption) *Handler {
+	t.handlersLock.Lock()
+	defer t.handlersLock.Unlock()
+
+	h := &Handler{
+		name:          strconv.Itoa(len(t.handlers)),
+		fn:            fn,
+		tracker:       t,
+		retryInterval: defaultRetryInterval,
+		batchSize:     defaultHandlerBatchSize,
+		notifyCh:      make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	t.handlers = append(t.handlers, h)
+
+	if t.handlersCtx != nil {
+		go h.run(t.handlersCtx)
+	}
+	return h
+}
+
+func (t *Tracker) startHandlers(ctx context.Context) {
+	t.handlersLock.Lock()
+	defer t.handlersLock.Unlock()
+
+	if t.handlersCtx != nil {
+		return
+	}
+	t.handlersCtx = ctx
+	for _, h := range t.handlers {
+		go h.run(ctx)
+	}
+}
+
+// notifyHandlers wakes up the handlers
+func (t *Tracker) notifyHandlers() {
+	t.handlersLock.Lock()
+	defer t.handlersLock.Unlock()
+
+	for _, h := range t.handlers {
+		h.notify()
+	}
+}
+
+// rewindHandlers moves back the cursor of the handlers that are ahead of
+// index. It must be called with the handlers lock held and before the logs
+// between index and last are removed from the entry. The logs in remove are
+// sorted from the last index backwards.
+func (t *Tracker) rewindHandlers(index, last uint64, remove []*core.Log) error {
+	for _, h := range t.handlers {
+		cursor, err := h.cursor()
+		if err != nil {
+			return err
+		}
+		upto := cursor
+		if h.inflight > upto {
+			upto = h.inflight
+		}
+		if upto <= index {
+			continue
+		}
+
+		removed := []*core.Log{}
+		for i := len(remove) - 1; i >= 0; i-- {
+			if indx := last - 1 - uint64(i); indx < upto {
+				removed = append(removed, remove[i])
+			}
+		}
+		if err := h.addRemoved(removed); err != nil {
+			return err
+		}
+		if err := h.setCursor(index); err != nil {
+			return err
+		}
+		h.inflight = 0
+		h.gen++
+	}
+	return nil
+}
+
+// Name returns the name of the handler
+func (h *Handler) Name() string {
+	return h.name
+}
+
+// Cursor returns the index of the next log to deliver
+func (h *Handler) Cursor() (uint64, error) {
+	h.tracker.handlersLock.Lock()
+	defer h.tracker.handlersLock.Unlock()
+
+	return h.cursor()
+}
+
+// Replay delivers again the logs starting at index
+func (h *Handler) Replay(index uint64) error {
+	h.tracker.handlersLock.Lock()
+	defer h.tracker.handlersLock.Unlock()
+
+	last, err := h.tracker.entry.LastIndex()
+	if err != nil {
+		return err
+	}
+	if index > last {
+		return fmt.Errorf("index %d is higher than the number of logs %d", index, last)
+	}
+	first, err := h.tracker.entry.FirstIndex()
+	if err != nil {
+		return err
+	}
+	if index < first {
+		return fmt.Errorf("index %d was pruned, the first log is %d", index, first)
+	}
+	if err := h.setCursor(index); err != nil {
+		return err
+	}
+	h.inflight = 0
+	h.gen++
+	h.notify()
+	return nil
+}
+
+func (h *Handler) notify() {
+	select {
+	case h.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (h *Handler) key(prefix string) string {
+	return prefix + "_" + h.tracker.config.Filter.Hash + "_" + h.name
+}
+
+func (h *Handler) cursor() (uint64, error) {
+	buf, err := h.tracker.store.Get(h.key(dbHandler))
+	if err != nil {
+		return 0, err
+	}
+	if buf == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(buf, 10, 64)
+}
+
+func (h *Handler) setCursor(index uint64) error {
+	return h.tracker.store.Set(h.key(dbHandler), strconv.FormatUint(index, 10))
+}
+
+// removedPages is the range of pages of removed logs pending to deliver
+type removedPages struct {
+	First uint64 `json:"first"`
+	Next  uint64 `json:"next"`
+}
+
+func (h *Handler) pageKey(page uint64) string {
+	return h.key(dbRemoved) + "_" + strconv.FormatUint(page, 10)
+}
+
+func (h *Handler) removedPages() (*removedPages, error) {
+	buf, err := h.tracker.store.Get(h.key(dbRemoved))
+	if err != nil {
+		return nil, err
+	}
+	pages := &removedPages{}
+	if buf == "" {
+		return pages, nil
+	}
+	if err := json.Unmarshal([]byte(buf), pages); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+func (h *Handler) setRemovedPages(pages *removedPages) error {
+	if pages.First == pages.Next {
+		return h.tracker.store.Delete(h.key(dbRemoved))
+	}
+	raw, err := json.Marshal(pages)
+	if err != nil {
+		return err
+	}
+	return h.tracker.store.Set(h.key(dbRemoved), string(raw))
+}
+
+// addRemoved stores the logs after the pending removed logs
+func (h *Handler) addRemoved(logs []*core.Log) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	pages, err := h.removedPages()
+	if err != nil {
+		return err
+	}
+	size := int(h.batchSize)
+	if size == 0 {
+		size = len(logs)
+	}
+	for i := 0; i < len(logs); i += size {
+		end := i + size
+		if end > len(logs) {
+			end = len(logs)
+		}
+		raw, err := json.Marshal(logs[i:end])
+		if err != nil {
+			return err
+		}
+		if err := h.tracker.store.Set(h.pageKey(pages.Next), string(raw)); err != nil {
+			return err
+		}
+		pages.Next++
+	}
+	return h.setRemovedPages(pages)
+}
+
+// removed returns the first page of the pending removed logs
+func (h *Handler) removed() ([]*core.Log, error) {
+	pages, err := h.removedPages()
+	if err != nil {
+		return nil, err
+	}
+	if pages.First == pages.Next {
+		return nil, nil
+	}
+	buf, err := h.tracker.store.Get(h.pageKey(pages.First))
+	if err != nil {
+		return nil, err
+	}
+	var logs []*core.Log
+	if err := json.Unmarshal([]byte(buf), &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// popRemoved deletes the first page of the pending removed logs
+func (h *Handler) popRemoved() error {
+	pages, err := h.removedPages()
+	if err != nil {
+		return err
+	}
+	if pages.First == pages.Next {
+		return nil
+	}
+	if err := h.tracker.store.Delete(h.pageKey(pages.First)); err != nil {
+		return err
+	}
+	pages.First++
+	return h.setRemovedPages(pages)
+}
+
+func (h *Handler) run(ctx context.Context) {
+	for {
+		if err := h.deliver(ctx); err != nil {
+			return
+		}
+		select {
+		case <-h.notifyCh:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deliver sends events to the handler until it reaches the last log
+func (h *Handler) deliver(ctx context.Context) error {
+	for {
+		evnt, end, gen, err := h.next()
+		if err != nil {
+			h.tracker.logger.Printf("[ERR]: handler %s failed to read logs: %v", h.name, err)
+			if err := h.wait(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+		if evnt == nil {
+			return nil
+		}
+
+		for {
+			err := h.fn(ctx, evnt)
+			if err == nil {
+				break
+			}
+			h.tracker.logger.Printf("[ERR]: handler %s failed: %v", h.name, err)
+			if err := h.wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		if err := h.commit(evnt, end, gen); err != nil {
+			h.tracker.logger.Printf("[ERR]: handler %s failed to store the cursor: %v", h.name, err)
+		}
+	}
+}
+
+func (h *Handler) wait(ctx context.Context) error {
+	select {
+	case <-time.After(h.retryInterval):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// next returns the next event to deliver, the removed logs go first
+func (h *Handler) next() (*Event, uint64, uint64, error) {
+	t := h.tracker
+
+	t.handlersLock.Lock()
+	defer t.handlersLock.Unlock()
+
+	removed, err := h.removed()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if len(removed) != 0 {
+		evnt := &Event{
+			Type:    EventDel,
+			Removed: removed,
+		}
+		return h.decode(evnt), 0, h.gen, nil
+	}
+
+	cursor, err := h.cursor()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	last, err := t.entry.LastIndex()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	first, err := t.entry.FirstIndex()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if cursor < first {
+		// the logs before the cursor were pruned
+		cursor = first
+	}
+	if cursor >= last {
+		return nil, 0, 0, nil
+	}
+	end := last
+	if h.batchSize != 0 && end-cursor > h.batchSize {
+		end = cursor + h.batchSize
+	}
+
+	logs := []*core.Log{}
+	for i := cursor; i < end; i++ {
+		var log core.Log
+		if err := t.entry.GetLog(i, &log); err != nil {
+			return nil, 0, 0, err
+		}
+		logs = append(logs, &log)
+	}
+	h.inflight = end
+
+	evnt := &Event{
+		Type:  EventAdd,
+		Added: logs,
+	}
+	return h.decode(evnt), end, h.gen, nil
+}
+
+func (h *Handler) decode(evnt *Event) *Event {
+	if h.tracker.decoder != nil {
+		h.tracker.decodeEvent(evnt)
+	}
+	return evnt
+}
+
+// commit moves the cursor after the event is processed unless
+// it was moved by a reorg or a replay in the meantime
+func (h *Handler) commit(evnt *Event, end, gen uint64) error {
+	h.tracker.handlersLock.Lock()
+	defer h.tracker.handlersLock.Unlock()
+
+	if gen != h.gen {
+		return nil
+	}
+	h.inflight = 0
+	if evnt.Type == EventDel {
+		return h.popRemoved()
+	}
+	return h.setCursor(end)
+}
@@ -0,0 +1,184 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/testutil"
+	"github.com/deep-nl/ethgo/tracker/store/inmem"
+	"github.com/stretchr/testify/require"
+)
+
+// handlerRecorder records the events delivered to a handler
+type handlerRecorder struct {
+	lock    sync.Mutex
+	added   []*core.Log
+	removed []*core.Log
+	fail    func() error
+}
+
+func (h *handlerRecorder) handle(ctx context.Context, evnt *Event) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.fail != nil {
+		if err := h.fail(); err != nil {
+			return err
+		}
+	}
+	h.added = append(h.added, evnt.Added...)
+	h.removed = append(h.removed, evnt.Removed...)
+	return nil
+}
+
+func (h *handlerRecorder) numAdded() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return len(h.added)
+}
+
+func (h *handlerRecorder) numRemoved() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return len(h.removed)
+}
+
+func newHandlerClient() (*testutil.MockClient, testutil.MockList) {
+	l := testutil.MockList{}
+	l.Create(0, 50, func(b *testutil.MockBlock) {
+		b.Log(fmt.Sprintf("0x%x", b.GetNum()))
+	})
+
+	m := &testutil.MockClient{}
+	m.AddScenario(l)
+	return m, l
+}
+
+func TestHandler_Deliver(t *testing.T) {
+	m, l := newHandlerClient()
+
+	tt, err := NewTracker(m, testConfig(), WithFilter(&FilterConfig{Async: true}))
+	require.NoError(t, err)
+
+	// the handler fails a few times before it succeeds
+	count := 0
+	rec := &handlerRecorder{
+		fail: func() error {
+			if count++; count < 3 {
+				return fmt.Errorf("failed")
+			}
+			return nil
+		},
+	}
+	h := tt.OnEvent(rec.handle, WithRetryInterval(10*time.Millisecond), WithHandlerBatchSize(7))
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	require.NoError(t, tt.Sync(ctx))
+	require.Eventually(t, func() bool {
+		return rec.numAdded() == 50
+	}, 2*time.Second, 10*time.Millisecond)
+	require.True(t, testutil.CompareLogs(l.GetLogs(), rec.added))
+
+	cursor, err := h.Cursor()
+	require.NoError(t, err)
+	require.Equal(t, uint64(50), cursor)
+
+	// replay the last logs
+	require.NoError(t, h.Replay(40))
+	require.Eventually(t, func() bool {
+		return rec.numAdded() == 60
+	}, 2*time.Second, 10*time.Millisecond)
+	require.True(t, testutil.CompareLogs(l.GetLogs()[40:], rec.added[50:]))
+
+	require.Error(t, h.Replay(51))
+}
+
+func TestHandler_Restart(t *testing.T) {
+	m, l := newHandlerClient()
+	store := inmem.NewInmemStore()
+
+	tt0, err := NewTracker(m, testConfig(), WithStore(store), WithFilter(&FilterConfig{Async: true}))
+	require.NoError(t, err)
+
+	// the first handler stops after the first batch
+	count := 0
+	rec0 := &handlerRecorder{
+		fail: func() error {
+			if count++; count > 1 {
+				return fmt.Errorf("failed")
+			}
+			return nil
+		},
+	}
+	tt0.OnEvent(rec0.handle, WithHandlerName("a"), WithRetryInterval(10*time.Millisecond), WithHandlerBatchSize(10))
+
+	ctx0, cancelFn0 := context.WithCancel(context.Background())
+	require.NoError(t, tt0.Sync(ctx0))
+	require.Eventually(t, func() bool {
+		return rec0.numAdded() == 10
+	}, 2*time.Second, 10*time.Millisecond)
+	cancelFn0()
+
+	// the handler resumes from its cursor
+	tt1, err := NewTracker(m, testConfig(), WithStore(store), WithFilter(&FilterConfig{Async: true}))
+	require.NoError(t, err)
+
+	rec1 := &handlerRecorder{}
+	tt1.OnEvent(rec1.handle, WithHandlerName("a"))
+
+	ctx1, cancelFn1 := context.WithCancel(context.Background())
+	defer cancelFn1()
+
+	require.NoError(t, tt1.Sync(ctx1))
+	require.Eventually(t, func() bool {
+		return rec1.numAdded() == 40
+	}, 2*time.Second, 10*time.Millisecond)
+	require.True(t, testutil.CompareLogs(l.GetLogs()[10:], rec1.added))
+}
+
+func TestHandler_Reorg(t *testing.T) {
+	m, l := newHandlerClient()
+
+	tt, err := NewTracker(m, testConfig(), WithFilter(&FilterConfig{Async: true}))
+	require.NoError(t, err)
+
+	// the removed logs are delivered in batches too
+	rec := &handlerRecorder{}
+	tt.OnEvent(func(ctx context.Context, evnt *Event) error {
+		if len(evnt.Removed) > 2 {
+			return fmt.Errorf("batch too large")
+		}
+		return rec.handle(ctx, evnt)
+	}, WithHandlerBatchSize(2))
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	require.NoError(t, tt.Sync(ctx))
+	require.Eventually(t, func() bool {
+		return rec.numAdded() == 50
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// remove the logs of the last 5 blocks
+	logs, err := tt.removeLogs(45, nil)
+	require.NoError(t, err)
+	require.Len(t, logs, 5)
+	tt.emitLogs(EventDel, logs)
+
+	require.Eventually(t, func() bool {
+		return rec.numRemoved() == 5
+	}, 2*time.Second, 10*time.Millisecond)
+	require.True(t, testutil.CompareLogs(l.GetLogs()[45:], rec.removed))
+
+	// the pages are deleted once delivered
+	require.Eventually(t, func() bool {
+		buf, err := tt.store.Get(tt.handlers[0].key(dbRemoved))
+		return err == nil && buf == ""
+	}, 2*time.Second, 10*time.Millisecond)
+}
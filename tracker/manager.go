@@ -0,0 +1,526 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"sync"
+
+	"github.com/deep-nl/ethgo/blocktracker"
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/tracker/store"
+)
+
+// Manager tracks multiple filters with a single block tracker. The logs of
+// the new blocks are queried once per block with a filter that merges all the
+// registered filters and then dispatched to the store entry and the event
+// channel of each filter.
+type Manager struct {
+	logger       *log.Logger
+	provider     Provider
+	config       *Config
+	store        store.Store
+	blockTracker *blocktracker.BlockTracker
+
+	// lock serializes the processing of the head among the filters
+	lock    sync.Mutex
+	ctx     context.Context
+	filters map[string]*managedFilter
+
+	// cache of the logs of the recent blocks for the merged filter
+	cacheLock sync.Mutex
+	cacheGen  uint64
+	cache     map[core.Hash][]*core.Log
+	query     *core.LogFilter
+
+	// cache of the indexed blocks shared by all the filters
+	blocksLock sync.Mutex
+	blocks     map[core.Hash]*IndexedBlock
+
+	BlockCh chan *blocktracker.BlockEvent
+}
+
+type managedFilter struct {
+	tracker  *Tracker
+	cancelFn context.CancelFunc
+	live     bool
+}
+
+// NewManager creates a new tracker manager. The Filter option is ignored,
+// filters are registered with AddFilter.
+func NewManager(provider Provider, opts ...ConfigOption) *Manager {
+	config := DefaultConfig()
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	blockTracker := config.BlockTracker
+	if blockTracker == nil {
+		blockTracker = blocktracker.NewBlockTracker(provider)
+	}
+
+	m := &Manager{
+		logger:       log.New(ioutil.Discard, "", log.LstdFlags),
+		provider:     provider,
+		config:       config,
+		store:        config.Store,
+		blockTracker: blockTracker,
+		filters:      map[string]*managedFilter{},
+		cache:        map[core.Hash][]*core.Log{},
+		blocks:       map[core.Hash]*IndexedBlock{},
+		BlockCh:      make(chan *blocktracker.BlockEvent, 1),
+	}
+	return m
+}
+
+// SetLogger sets the logger of the manager
+func (m *Manager) SetLogger(logger *log.Logger) {
+	m.logger = logger
+}
+
+// AddFilter registers a new filter and returns the tracker that holds its
+// store entry and channels. If the manager is running, the backfill of the
+// filter starts right away, otherwise it starts with the manager.
+func (m *Manager) AddFilter(filter *FilterConfig) (*Tracker, error) {
+	if filter == nil {
+		filter = &FilterConfig{}
+	}
+	filter.buildTopics()
+	if filter.Hash == "" {
+		filter.buildHash()
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.filters[filter.Hash]; ok {
+		return nil, fmt.Errorf("filter %s already tracked", filter.Hash)
+	}
+
+	opts := []ConfigOption{
+		WithBatchSize(m.config.BatchSize),
+		WithWorkers(m.config.Workers),
+		WithBlockTracker(m.blockTracker),
+		WithEtherscan(m.config.EtherscanAPIKey),
+		WithStore(m.store),
+		WithFilter(filter),
+		WithFinality(m.config.Finality),
+		WithRetention(m.config.Retention...),
+		WithCompactInterval(m.config.CompactInterval),
+	}
+	if m.config.IndexBlocks {
+		// the blocks are fetched once and stored for each filter
+		opts = append(opts, WithBlockIndexing(), WithReceiptsBatchSize(m.config.ReceiptsBatchSize))
+	}
+
+	t, err := NewTracker(m.provider, opts...)
+	if err != nil {
+		return nil, err
+	}
+	t.getLogs = m.getLogs(filter)
+	t.getBlock = m.getBlock(t)
+
+	f := &managedFilter{
+		tracker: t,
+	}
+	m.filters[filter.Hash] = f
+	m.resetCacheLocked()
+
+	if m.ctx != nil {
+		m.startFilterLocked(f)
+	}
+	return t, nil
+}
+
+// RemoveFilter stops tracking the filter. The logs already
+// stored for the filter are kept in the store.
+func (m *Manager) RemoveFilter(hash string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	f, ok := m.filters[hash]
+	if !ok {
+		return fmt.Errorf("filter %s not found", hash)
+	}
+	if f.cancelFn != nil {
+		f.cancelFn()
+	}
+	delete(m.filters, hash)
+	m.resetCacheLocked()
+	return nil
+}
+
+// Filters returns the hashes of the registered filters
+func (m *Manager) Filters() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	res := []string{}
+	for hash := range m.filters {
+		res = append(res, hash)
+	}
+	return res
+}
+
+// Start starts the block tracker and the backfill of the registered
+// filters. The manager runs until the context is canceled.
+func (m *Manager) Start(ctx context.Context) error {
+	if err := m.blockTracker.Init(); err != nil {
+		return err
+	}
+	if m.config.BlockTracker == nil {
+		// the block tracker is owned by the manager
+		go m.blockTracker.Start()
+		go func() {
+			<-ctx.Done()
+			m.blockTracker.Close()
+		}()
+	}
+
+	sub := m.blockTracker.Subscribe()
+	go func() {
+		for {
+			select {
+			case evnt := <-sub:
+				select {
+				case m.BlockCh <- evnt:
+				default:
+				}
+				m.handleHead(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.ctx = ctx
+	for _, f := range m.filters {
+		m.startFilterLocked(f)
+	}
+	return nil
+}
+
+func (m *Manager) startFilterLocked(f *managedFilter) {
+	ctx, cancelFn := context.WithCancel(m.ctx)
+	f.cancelFn = cancelFn
+
+	go func() {
+		if err := f.tracker.BatchSync(ctx); err != nil {
+			if ctx.Err() == nil {
+				m.logger.Printf("[ERR]: failed to sync filter %s: %v", f.tracker.config.Filter.Hash, err)
+			}
+			return
+		}
+
+		m.lock.Lock()
+		defer m.lock.Unlock()
+
+		if ctx.Err() != nil {
+			// the filter was removed during the backfill
+			return
+		}
+		// process the blocks included since the backfill finished
+		if err := m.syncFilter(ctx, f.tracker); err != nil {
+			m.logger.Printf("[ERR]: failed to sync filter %s: %v", f.tracker.config.Filter.Hash, err)
+			return
+		}
+		f.live = true
+	}()
+}
+
+func (m *Manager) handleHead(ctx context.Context) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for hash, f := range m.filters {
+		if !f.live {
+			continue
+		}
+		if err := m.syncFilter(ctx, f.tracker); err != nil {
+			m.logger.Printf("[ERR]: failed to sync filter %s: %v", hash, err)
+		}
+	}
+	m.pruneCache()
+
+	if m.config.Finality != 0 {
+		// query the finalized block once for all the filters
+		block, err := m.provider.GetBlockByNumber(m.config.Finality, false)
+		if err != nil {
+			m.logger.Printf("[ERR]: failed to get the %s block: %v", m.config.Finality, err)
+			return
+		}
+		for hash, f := range m.filters {
+			if !f.live {
+				continue
+			}
+			if err := f.tracker.finalize(block.Number); err != nil {
+				m.logger.Printf("[ERR]: failed to finalize filter %s: %v", hash, err)
+			}
+		}
+	}
+}
+
+// syncFilter processes the blocks in the block tracker that are
+// ahead of the last block of the filter
+func (m *Manager) syncFilter(ctx context.Context, t *Tracker) error {
+	last, err := t.GetLastBlock()
+	if err != nil {
+		return err
+	}
+
+	// copy the blocks and release the block tracker before
+	// querying the provider to not stall the other filters
+	lock := m.blockTracker.AcquireLock()
+	lock.Lock()
+	blocks := m.blockTracker.BlocksBlocked()
+	lock.Unlock()
+
+	indx := -1
+	if last != nil {
+		for i, b := range blocks {
+			if b.Hash == last.Hash {
+				indx = i
+				break
+			}
+		}
+	}
+	if indx == -1 {
+		// there was a reorg or the filter is behind the
+		// backlog, the tracker knows how to reconcile it.
+		return t.syncImpl(ctx)
+	}
+
+	added := blocks[indx+1:]
+	if len(added) == 0 {
+		return nil
+	}
+	evnt, err := t.doFilter(added, nil)
+	if err != nil {
+		return err
+	}
+	t.emitEvent(evnt)
+	return nil
+}
+
+// getLogs returns the function used by the tracker of the filter to query
+// the logs. Queries for a block hash use the logs of the merged filter,
+// range queries from the backfill are done independently.
+func (m *Manager) getLogs(filter *FilterConfig) func(*core.LogFilter) ([]*core.Log, error) {
+	return func(query *core.LogFilter) ([]*core.Log, error) {
+		if query.BlockHash == nil {
+			return m.provider.GetLogs(query)
+		}
+		logs, err := m.blockLogs(*query.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+		res := []*core.Log{}
+		for _, log := range logs {
+			if filter.match(log) {
+				res = append(res, log)
+			}
+		}
+		return res, nil
+	}
+}
+
+// getBlock returns the function used by the tracker of the filter to fetch
+// the indexed blocks. The blocks are shared by all the filters.
+func (m *Manager) getBlock(t *Tracker) func(core.Hash) (*IndexedBlock, error) {
+	return func(hash core.Hash) (*IndexedBlock, error) {
+		// the lock is held during the fetch to not query
+		// the same block for the filters that sync concurrently
+		m.blocksLock.Lock()
+		defer m.blocksLock.Unlock()
+
+		if b, ok := m.blocks[hash]; ok {
+			return b, nil
+		}
+		b, err := t.fetchBlock(hash)
+		if err != nil {
+			return nil, err
+		}
+		m.blocks[hash] = b
+		return b, nil
+	}
+}
+
+func (m *Manager) blockLogs(hash core.Hash) ([]*core.Log, error) {
+	m.cacheLock.Lock()
+	if logs, ok := m.cache[hash]; ok {
+		m.cacheLock.Unlock()
+		return logs, nil
+	}
+	gen := m.cacheGen
+	query := *m.query
+	m.cacheLock.Unlock()
+
+	query.BlockHash = &hash
+	logs, err := m.provider.GetLogs(&query)
+	if err != nil {
+		return nil, err
+	}
+
+	m.cacheLock.Lock()
+	if gen == m.cacheGen {
+		// the filters did not change during the query
+		m.cache[hash] = logs
+	}
+	m.cacheLock.Unlock()
+	return logs, nil
+}
+
+// resetCacheLocked rebuilds the merged filter after the filters change
+func (m *Manager) resetCacheLocked() {
+	filters := []*FilterConfig{}
+	for _, f := range m.filters {
+		filters = append(filters, f.tracker.config.Filter)
+	}
+
+	m.cacheLock.Lock()
+	defer m.cacheLock.Unlock()
+
+	m.cacheGen++
+	m.cache = map[core.Hash][]*core.Log{}
+	m.query = mergeFilters(filters)
+}
+
+// pruneCache removes the logs and the indexed blocks that are not in the backlog
+func (m *Manager) pruneCache() {
+	lock := m.blockTracker.AcquireLock()
+	lock.Lock()
+	blocks := map[core.Hash]struct{}{}
+	for _, b := range m.blockTracker.BlocksBlocked() {
+		blocks[b.Hash] = struct{}{}
+	}
+	lock.Unlock()
+
+	m.cacheLock.Lock()
+	for hash := range m.cache {
+		if _, ok := blocks[hash]; !ok {
+			delete(m.cache, hash)
+		}
+	}
+	m.cacheLock.Unlock()
+
+	m.blocksLock.Lock()
+	for hash := range m.blocks {
+		if _, ok := blocks[hash]; !ok {
+			delete(m.blocks, hash)
+		}
+	}
+	m.blocksLock.Unlock()
+}
+
+// mergeFilters returns a log filter that matches the logs of any of the filters
+func mergeFilters(filters []*FilterConfig) *core.LogFilter {
+	query := &core.LogFilter{}
+	if len(filters) == 0 {
+		return query
+	}
+
+	// addresses
+	addrs := []core.Address{}
+	seen := map[core.Address]struct{}{}
+	for _, f := range filters {
+		if len(f.Address) == 0 {
+			// any address
+			addrs = nil
+			break
+		}
+		for _, addr := range f.Address {
+			if _, ok := seen[addr]; !ok {
+				seen[addr] = struct{}{}
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	if len(addrs) != 0 {
+		query.Address = addrs
+	}
+
+	// topics, a position is only restricted if all the filters restrict it
+	num := len(filters[0].Topics)
+	for _, f := range filters {
+		if len(f.Topics) < num {
+			num = len(f.Topics)
+		}
+	}
+	topics := make([][]*core.Hash, num)
+	for i := 0; i < num; i++ {
+		set := []*core.Hash{}
+		seen := map[core.Hash]struct{}{}
+		for _, f := range filters {
+			if !restricted(f.Topics[i]) {
+				set = nil
+				break
+			}
+			for _, topic := range f.Topics[i] {
+				if _, ok := seen[*topic]; !ok {
+					seen[*topic] = struct{}{}
+					set = append(set, topic)
+				}
+			}
+		}
+		topics[i] = set
+	}
+	for len(topics) != 0 && topics[len(topics)-1] == nil {
+		topics = topics[:len(topics)-1]
+	}
+	if len(topics) != 0 {
+		query.Topics = topics
+	}
+	return query
+}
+
+// restricted returns false if any topic matches the position
+func restricted(topics []*core.Hash) bool {
+	if len(topics) == 0 {
+		return false
+	}
+	for _, topic := range topics {
+		if topic == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// match returns true if the log is included in the filter
+func (f *FilterConfig) match(log *core.Log) bool {
+	if len(f.Address) != 0 {
+		found := false
+		for _, addr := range f.Address {
+			if addr == log.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for i, topics := range f.Topics {
+		if !restricted(topics) {
+			continue
+		}
+		if i >= len(log.Topics) {
+			return false
+		}
+		found := false
+		for _, topic := range topics {
+			if *topic == log.Topics[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
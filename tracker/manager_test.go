@@ -0,0 +1,248 @@
+package tracker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deep-nl/ethgo/blocktracker"
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/testutil"
+	"github.com/deep-nl/ethgo/tracker/store/inmem"
+	"github.com/stretchr/testify/require"
+)
+
+// filterMockClient is a mock client that applies the address
+// and topic filters and counts the log queries per block hash
+type filterMockClient struct {
+	*testutil.MockClient
+	hashQueries int32
+}
+
+func (f *filterMockClient) GetLogs(filter *core.LogFilter) ([]*core.Log, error) {
+	if filter.BlockHash != nil {
+		atomic.AddInt32(&f.hashQueries, 1)
+	}
+	logs, err := f.MockClient.GetLogs(filter)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &FilterConfig{Address: filter.Address, Topics: filter.Topics}
+	res := []*core.Log{}
+	for _, log := range logs {
+		if cfg.match(log) {
+			res = append(res, log)
+		}
+	}
+	return res, nil
+}
+
+type noopBlockTracker struct{}
+
+func (noopBlockTracker) Track(context.Context, func(block *core.Block) error) error {
+	return nil
+}
+
+func TestMergeFilters(t *testing.T) {
+	addr0, addr1 := core.Address{0x1}, core.Address{0x2}
+	topic0, topic1, topic2 := &core.Hash{0x1}, &core.Hash{0x2}, &core.Hash{0x3}
+
+	cases := []struct {
+		filters []*FilterConfig
+		query   *core.LogFilter
+	}{
+		{
+			[]*FilterConfig{
+				{Address: []core.Address{addr0}},
+				{Address: []core.Address{addr1, addr0}},
+			},
+			&core.LogFilter{Address: []core.Address{addr0, addr1}},
+		},
+		{
+			// any address
+			[]*FilterConfig{
+				{Address: []core.Address{addr0}},
+				{},
+			},
+			&core.LogFilter{},
+		},
+		{
+			[]*FilterConfig{
+				{Topics: [][]*core.Hash{{topic0}, {topic1}}},
+				{Topics: [][]*core.Hash{{topic2}, nil, {topic1}}},
+			},
+			&core.LogFilter{Topics: [][]*core.Hash{{topic0, topic2}}},
+		},
+		{
+			[]*FilterConfig{
+				{Topics: [][]*core.Hash{{topic0}}},
+				{Topics: [][]*core.Hash{{nil}}},
+			},
+			&core.LogFilter{},
+		},
+	}
+
+	for _, c := range cases {
+		require.Equal(t, c.query, mergeFilters(c.filters))
+	}
+}
+
+func TestFilterConfig_Match(t *testing.T) {
+	topic0, topic1 := &core.Hash{0x1}, &core.Hash{0x2}
+
+	f := &FilterConfig{
+		Address: []core.Address{{0x1}},
+		Topics:  [][]*core.Hash{nil, {topic0, topic1}},
+	}
+	require.True(t, f.match(&core.Log{Address: core.Address{0x1}, Topics: []core.Hash{{}, *topic1}}))
+	require.False(t, f.match(&core.Log{Address: core.Address{0x2}, Topics: []core.Hash{{}, *topic1}}))
+	require.False(t, f.match(&core.Log{Address: core.Address{0x1}, Topics: []core.Hash{{}, {0x3}}}))
+	require.False(t, f.match(&core.Log{Address: core.Address{0x1}, Topics: []core.Hash{{}}}))
+}
+
+func TestManager(t *testing.T) {
+	addr0, addr1, addr2 := core.Address{0x1}, core.Address{0x2}, core.Address{0x3}
+	addrs := []core.Address{addr0, addr1, addr2}
+
+	m := &filterMockClient{MockClient: &testutil.MockClient{}}
+
+	// advance creates the blocks and one log per address in every block
+	advance := func(from, to int) []*core.Block {
+		l := testutil.MockList{}
+		l.Create(from, to, func(b *testutil.MockBlock) {})
+		m.AddScenario(l)
+
+		logs := []*core.Log{}
+		for _, b := range l {
+			for _, addr := range addrs {
+				logs = append(logs, &core.Log{Address: addr, BlockNumber: uint64(b.GetNum()), BlockHash: b.Hash()})
+			}
+		}
+		m.AddLogs(logs)
+
+		blocks := []*core.Block{}
+		for _, b := range l {
+			block, err := m.GetBlockByHash(b.Hash(), false)
+			require.NoError(t, err)
+			blocks = append(blocks, block)
+		}
+		return blocks
+	}
+	advance(0, 50)
+
+	bt := blocktracker.NewBlockTracker(m, blocktracker.WithTracker(noopBlockTracker{}))
+	mgr := NewManager(m, testConfig(), WithBlockTracker(bt), WithStore(inmem.NewInmemStore()), WithWorkers(2))
+
+	tt0, err := mgr.AddFilter(&FilterConfig{Address: []core.Address{addr0}, Async: true})
+	require.NoError(t, err)
+
+	// the filters backfill with the workers of the manager
+	require.Equal(t, 2, tt0.config.Workers)
+	tt1, err := mgr.AddFilter(&FilterConfig{Address: []core.Address{addr1}, Async: true})
+	require.NoError(t, err)
+
+	_, err = mgr.AddFilter(&FilterConfig{Address: []core.Address{addr1}})
+	require.Error(t, err)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	require.NoError(t, mgr.Start(ctx))
+
+	// there is one log per filter in every block
+	numLogs := func(tt *Tracker, num uint64) func() bool {
+		return func() bool {
+			indx, _ := tt.entry.LastIndex()
+			return indx == num+1
+		}
+	}
+	waitLive := func(trackers ...*Tracker) {
+		require.Eventually(t, func() bool {
+			mgr.lock.Lock()
+			defer mgr.lock.Unlock()
+
+			for _, tt := range trackers {
+				if !mgr.filters[tt.config.Filter.Hash].live {
+					return false
+				}
+			}
+			return true
+		}, 2*time.Second, 10*time.Millisecond)
+	}
+
+	waitLive(tt0, tt1)
+	require.Eventually(t, numLogs(tt0, 49), 2*time.Second, 10*time.Millisecond)
+	require.Eventually(t, numLogs(tt1, 49), 2*time.Second, 10*time.Millisecond)
+
+	// new blocks are queried once for all the filters
+	atomic.StoreInt32(&m.hashQueries, 0)
+
+	for _, b := range advance(50, 55) {
+		require.NoError(t, bt.HandleReconcile(b))
+		require.Eventually(t, numLogs(tt0, b.Number), 2*time.Second, 10*time.Millisecond)
+		require.Eventually(t, numLogs(tt1, b.Number), 2*time.Second, 10*time.Millisecond)
+	}
+	require.Equal(t, int32(5), atomic.LoadInt32(&m.hashQueries))
+
+	for _, log := range tt1.entry.(*inmem.Entry).Logs() {
+		require.Equal(t, addr1, log.Address)
+	}
+
+	// add a filter at runtime with its own backfill
+	tt2, err := mgr.AddFilter(&FilterConfig{Address: []core.Address{addr2}, Async: true})
+	require.NoError(t, err)
+
+	waitLive(tt2)
+	require.Eventually(t, numLogs(tt2, 54), 2*time.Second, 10*time.Millisecond)
+
+	// removed filters do not get new logs
+	require.NoError(t, mgr.RemoveFilter(tt0.config.Filter.Hash))
+	require.Error(t, mgr.RemoveFilter(tt0.config.Filter.Hash))
+	require.Len(t, mgr.Filters(), 2)
+
+	for _, b := range advance(55, 60) {
+		require.NoError(t, bt.HandleReconcile(b))
+		require.Eventually(t, numLogs(tt1, b.Number), 2*time.Second, 10*time.Millisecond)
+		require.Eventually(t, numLogs(tt2, b.Number), 2*time.Second, 10*time.Millisecond)
+	}
+	indx, err := tt0.entry.LastIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(55), indx)
+}
+
+func TestManager_IndexBlocks(t *testing.T) {
+	m, l := newHandlerClient()
+	client := &receiptsMockClient{MockClient: m}
+
+	bt := blocktracker.NewBlockTracker(client, blocktracker.WithTracker(noopBlockTracker{}))
+	mgr := NewManager(client, testConfig(), WithBlockTracker(bt), WithStore(inmem.NewInmemStore()), WithBlockIndexing())
+
+	tt0, err := mgr.AddFilter(&FilterConfig{Topics: [][]*core.Hash{{&core.Hash{0x1}}}, Async: true})
+	require.NoError(t, err)
+	tt1, err := mgr.AddFilter(&FilterConfig{Topics: [][]*core.Hash{{&core.Hash{0x2}}}, Async: true})
+	require.NoError(t, err)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	require.NoError(t, mgr.Start(ctx))
+	require.Eventually(t, func() bool {
+		mgr.lock.Lock()
+		defer mgr.lock.Unlock()
+
+		return mgr.filters[tt0.config.Filter.Hash].live && mgr.filters[tt1.config.Filter.Hash].live
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// the blocks of the backlog are fetched once for both filters
+	require.Equal(t, int32(20), atomic.LoadInt32(&client.calls))
+
+	for _, tt := range []*Tracker{tt0, tt1} {
+		for i := uint64(40); i < 50; i++ {
+			b, err := tt.blockStore.GetBlock(i)
+			require.NoError(t, err)
+			require.NotNil(t, b)
+			require.Equal(t, l[i].Hash(), b.Block.Hash)
+		}
+	}
+}
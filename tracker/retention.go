@@ -0,0 +1,219 @@
+package tracker
+
+import (
+	"context"
+	"time"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/tracker/store"
+)
+
+const defaultCompactInterval = 1 * time.Minute
+
+// RetentionPolicy decides which logs of the tracker are kept in the store
+type RetentionPolicy interface {
+	// Cutoff returns the first block whose logs are kept given the last block
+	// processed by the tracker, or false if all the logs must be kept
+	Cutoff(t *Tracker, head *core.Block) (uint64, bool, error)
+}
+
+// KeepBlocks keeps the logs of the last n blocks
+func KeepBlocks(n uint64) RetentionPolicy {
+	return &keepBlocks{n: n}
+}
+
+type keepBlocks struct {
+	n uint64
+}
+
+func (k *keepBlocks) Cutoff(t *Tracker, head *core.Block) (uint64, bool, error) {
+	if head.Number+1 <= k.n {
+		return 0, false, nil
+	}
+	return head.Number + 1 - k.n, true, nil
+}
+
+// KeepDuration keeps the logs of the blocks newer than d
+func KeepDuration(d time.Duration) RetentionPolicy {
+	return &keepDuration{d: d, now: time.Now}
+}
+
+type keepDuration struct {
+	d   time.Duration
+	now func() time.Time
+}
+
+func (k *keepDuration) Cutoff(t *Tracker, head *core.Block) (uint64, bool, error) {
+	target := uint64(k.now().Add(-k.d).Unix())
+	if head.Timestamp < target {
+		return head.Number + 1, true, nil
+	}
+
+	// the first block of the search is the block of the first log
+	first, err := t.entry.FirstIndex()
+	if err != nil {
+		return 0, false, err
+	}
+	last, err := t.entry.LastIndex()
+	if err != nil {
+		return 0, false, err
+	}
+	if first == last {
+		return 0, false, nil
+	}
+	var log core.Log
+	if err := t.entry.GetLog(first, &log); err != nil {
+		return 0, false, err
+	}
+
+	// binary search of the first block at or after the target
+	lo, hi := log.BlockNumber, head.Number
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		block, err := t.provider.GetBlockByNumber(core.BlockNumber(mid), false)
+		if err != nil {
+			return 0, false, err
+		}
+		if block.Timestamp < target {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, true, nil
+}
+
+// FinalizedOnly restricts the policy to prune only the logs of finalized
+// blocks. It requires the tracker to be configured with WithFinality.
+func FinalizedOnly(p RetentionPolicy) RetentionPolicy {
+	return &finalizedOnly{p: p}
+}
+
+type finalizedOnly struct {
+	p RetentionPolicy
+}
+
+func (f *finalizedOnly) Cutoff(t *Tracker, head *core.Block) (uint64, bool, error) {
+	cutoff, ok, err := f.p.Cutoff(t, head)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	finalized, ok, err := t.GetLastFinalized()
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	return min(cutoff, finalized+1), true, nil
+}
+
+// Compact prunes the logs and the indexed blocks that are not kept by any of the
+// retention policies. The logs that the handlers have not processed yet and the
+// logs of the blocks in the backlog of the block tracker are kept.
+func (t *Tracker) Compact() error {
+	if len(t.config.Retention) == 0 {
+		return nil
+	}
+
+	head, err := t.GetLastBlock()
+	if err != nil {
+		return err
+	}
+	if head == nil {
+		return nil
+	}
+
+	var cutoff uint64
+	for i, policy := range t.config.Retention {
+		num, ok, err := policy.Cutoff(t, head)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if i == 0 || num < cutoff {
+			cutoff = num
+		}
+	}
+
+	// the logs and the blocks of the backlog can still be removed by a reorg
+	// while the tracker syncs, they are never pruned
+	backlog := uint64(defaultMaxBlockBacklog)
+	if t.blockTracker != nil {
+		backlog = t.blockTracker.MaxBlockBacklog()
+	}
+	if head.Number+1 <= backlog {
+		return nil
+	}
+	cutoff = min(cutoff, head.Number+1-backlog)
+
+	if t.blockStore != nil {
+		if err := t.blockStore.PruneBlocks(cutoff); err != nil {
+			return err
+		}
+	}
+
+	first, err := t.entry.FirstIndex()
+	if err != nil {
+		return err
+	}
+	q := &store.LogQuery{
+		Start: first,
+		Limit: 1,
+	}
+	q.SetFromBlock(cutoff)
+
+	res, err := t.entry.Query(q)
+	if err != nil {
+		return err
+	}
+	var index uint64
+	if len(res.Indexes) != 0 {
+		index = res.Indexes[0]
+	} else {
+		if index, err = t.entry.LastIndex(); err != nil {
+			return err
+		}
+	}
+
+	t.handlersLock.Lock()
+	defer t.handlersLock.Unlock()
+
+	for _, h := range t.handlers {
+		cursor, err := h.cursor()
+		if err != nil {
+			return err
+		}
+		index = min(index, cursor)
+	}
+	if index <= first {
+		return nil
+	}
+	return t.entry.PruneLogs(index)
+}
+
+// startCompactor runs Compact periodically if there are retention policies
+func (t *Tracker) startCompactor(ctx context.Context) {
+	if len(t.config.Retention) == 0 {
+		return
+	}
+	interval := t.config.CompactInterval
+	if interval <= 0 {
+		interval = defaultCompactInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := t.Compact(); err != nil {
+					t.logger.Printf("[ERR]: failed to compact the store: %v", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
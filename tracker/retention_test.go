@@ -0,0 +1,123 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/testutil"
+	"github.com/deep-nl/ethgo/tracker/store/inmem"
+	"github.com/stretchr/testify/require"
+)
+
+// timestampMockClient sets the timestamp of the blocks to ten seconds per block
+type timestampMockClient struct {
+	*testutil.MockClient
+}
+
+func (m *timestampMockClient) GetBlockByNumber(i core.BlockNumber, full bool) (*core.Block, error) {
+	b, err := m.MockClient.GetBlockByNumber(i, full)
+	if err != nil || b == nil {
+		return b, err
+	}
+	b = b.Copy()
+	b.Timestamp = b.Number * 10
+	return b, nil
+}
+
+func TestRetentionPolicies(t *testing.T) {
+	m, _ := newHandlerClient()
+
+	tt, err := NewTracker(&timestampMockClient{m}, testConfig(), WithFilter(&FilterConfig{Async: true}))
+	require.NoError(t, err)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, tt.Sync(ctx))
+
+	head := &core.Block{Number: 49, Timestamp: 490}
+
+	checkCutoff := func(p RetentionPolicy, expected uint64, found bool) {
+		t.Helper()
+
+		cutoff, ok, err := p.Cutoff(tt, head)
+		require.NoError(t, err)
+		require.Equal(t, found, ok)
+		require.Equal(t, expected, cutoff)
+	}
+
+	checkCutoff(KeepBlocks(10), 40, true)
+	checkCutoff(KeepBlocks(100), 0, false)
+
+	now := func(i int64) func() time.Time {
+		return func() time.Time {
+			return time.Unix(i, 0)
+		}
+	}
+	checkCutoff(&keepDuration{d: 100 * time.Second, now: now(490)}, 39, true)
+	checkCutoff(&keepDuration{d: 100 * time.Second, now: now(10000)}, 50, true)
+
+	// nothing is finalized yet
+	checkCutoff(FinalizedOnly(KeepBlocks(10)), 0, false)
+
+	require.NoError(t, tt.store.Set(dbFinalized+"_"+tt.config.Filter.Hash, "30"))
+	checkCutoff(FinalizedOnly(KeepBlocks(10)), 31, true)
+	checkCutoff(FinalizedOnly(KeepBlocks(5)), 31, true)
+	checkCutoff(FinalizedOnly(KeepBlocks(40)), 10, true)
+}
+
+func TestTrackerCompact(t *testing.T) {
+	m, l := newHandlerClient()
+
+	tt, err := NewTracker(m, testConfig(), WithFilter(&FilterConfig{Async: true}), WithRetention(KeepBlocks(10), KeepBlocks(5)))
+	require.NoError(t, err)
+
+	// the handler does not process any logs until ready is set
+	var ready int32
+	rec := &handlerRecorder{
+		fail: func() error {
+			if atomic.LoadInt32(&ready) == 0 {
+				return fmt.Errorf("not ready")
+			}
+			return nil
+		},
+	}
+	h := tt.OnEvent(rec.handle, WithRetryInterval(10*time.Millisecond))
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, tt.Sync(ctx))
+
+	// the logs pending in the handler are kept
+	require.NoError(t, tt.Compact())
+
+	first, err := tt.entry.FirstIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), first)
+
+	atomic.StoreInt32(&ready, 1)
+	require.Eventually(t, func() bool {
+		cursor, err := h.Cursor()
+		return err == nil && cursor == 50
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// the most permissive policy wins
+	require.NoError(t, tt.Compact())
+
+	first, err = tt.entry.FirstIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(40), first)
+	require.True(t, testutil.CompareLogs(l.GetLogs()[40:], tt.entry.(*inmem.Entry).Logs()))
+
+	// a reorg after the compaction
+	logs, err := tt.removeLogs(45, nil)
+	require.NoError(t, err)
+	require.Len(t, logs, 5)
+
+	last, err := tt.entry.LastIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(45), last)
+}
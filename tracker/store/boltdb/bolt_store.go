@@ -3,6 +3,8 @@ package trackerboltdb
 import (
 	"bytes"
 	"encoding/binary"
+	"math"
+
 	"github.com/deep-nl/ethgo/core"
 
 	"github.com/deep-nl/ethgo/tracker/store"
@@ -12,10 +14,26 @@ import (
 var _ store.Store = (*BoltStore)(nil)
 
 var (
-	dbLogs = []byte("logs")
-	dbConf = []byte("conf")
+	dbLogs  = []byte("logs")
+	dbIndex = []byte("index")
+	dbConf  = []byte("conf")
+)
+
+// prefixes of the keys in the index bucket of the entries. Each key
+// is the prefix, the indexed value and the index of the log.
+const (
+	indexBlock   = 'b'
+	indexAddress = 'a'
+	indexTopic   = 't'
+	indexTxHash  = 'x'
 )
 
+// indexFirstKey is the key in the index bucket of the first log index
+var indexFirstKey = []byte{'f'}
+
+// maxTopics is the maximum number of topics of a log
+const maxTopics = 4
+
 // BoltStore is a tracker store implementation.
 type BoltStore struct {
 	conn *bolt.DB
@@ -85,6 +103,22 @@ func (b *BoltStore) ListPrefix(prefix string) ([]string, error) {
 	return res, nil
 }
 
+// ListKeys implements the store interface
+func (b *BoltStore) ListKeys(prefix string) ([]string, error) {
+	txn, err := b.conn.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	defer txn.Rollback()
+
+	res := []string{}
+	c := txn.Bucket(dbConf).Cursor()
+	for k, _ := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, _ = c.Next() {
+		res = append(res, string(k))
+	}
+	return res, nil
+}
+
 // Set implements the store interface
 func (b *BoltStore) Set(k, v string) error {
 	txn, err := b.conn.Begin(true)
@@ -100,6 +134,21 @@ func (b *BoltStore) Set(k, v string) error {
 	return txn.Commit()
 }
 
+// Delete implements the store interface
+func (b *BoltStore) Delete(k string) error {
+	txn, err := b.conn.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	bucket := txn.Bucket(dbConf)
+	if err := bucket.Delete([]byte(k)); err != nil {
+		return err
+	}
+	return txn.Commit()
+}
+
 // GetEntry implements the store interface
 func (b *BoltStore) GetEntry(hash string) (store.Entry, error) {
 	txn, err := b.conn.Begin(true)
@@ -108,24 +157,69 @@ func (b *BoltStore) GetEntry(hash string) (store.Entry, error) {
 	}
 	defer txn.Rollback()
 
-	bucketName := append(dbLogs, []byte(hash)...)
-	if _, err := txn.CreateBucketIfNotExists(bucketName); err != nil {
+	bucketName := append(append([]byte{}, dbLogs...), []byte(hash)...)
+	logs, err := txn.CreateBucketIfNotExists(bucketName)
+	if err != nil {
 		return nil, err
 	}
+
+	indexName := append(append([]byte{}, dbIndex...), []byte(hash)...)
+	if txn.Bucket(indexName) == nil {
+		index, err := txn.CreateBucket(indexName)
+		if err != nil {
+			return nil, err
+		}
+		// index the logs stored before the index existed
+		if err := logs.ForEach(func(k, v []byte) error {
+			var log core.Log
+			if err := log.UnmarshalJSON(v); err != nil {
+				return err
+			}
+			return putIndex(index, bytesToUint64(k), &log)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := txn.Commit(); err != nil {
 		return nil, err
 	}
 	e := &Entry{
 		conn:   b.conn,
 		bucket: bucketName,
+		index:  indexName,
 	}
 	return e, nil
 }
 
-// Entry is an store.Entry implementation
+// ListEntries implements the store interface
+func (b *BoltStore) ListEntries() ([]string, error) {
+	txn, err := b.conn.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	defer txn.Rollback()
+
+	res := []string{}
+	err = txn.ForEach(func(name []byte, _ *bolt.Bucket) error {
+		if bytes.HasPrefix(name, dbLogs) {
+			res = append(res, string(name[len(dbLogs):]))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Entry is an store.Entry implementation. Besides the logs, each
+// entry has a bucket that indexes the logs by block number,
+// address, topics and transaction hash.
 type Entry struct {
 	conn   *bolt.DB
 	bucket []byte
+	index  []byte
 }
 
 // LastIndex implements the store interface
@@ -136,11 +230,33 @@ func (e *Entry) LastIndex() (uint64, error) {
 	}
 	defer tx.Rollback()
 
-	curs := tx.Bucket(e.bucket).Cursor()
-	if last, _ := curs.Last(); last != nil {
-		return bytesToUint64(last) + 1, nil
+	return lastIndex(tx.Bucket(e.bucket), tx.Bucket(e.index)), nil
+}
+
+func lastIndex(bucket, index *bolt.Bucket) uint64 {
+	if last, _ := bucket.Cursor().Last(); last != nil {
+		return bytesToUint64(last) + 1
+	}
+	// all the logs were pruned
+	return firstIndex(index)
+}
+
+// FirstIndex implements the store interface
+func (e *Entry) FirstIndex() (uint64, error) {
+	tx, err := e.conn.Begin(false)
+	if err != nil {
+		return 0, err
 	}
-	return 0, nil
+	defer tx.Rollback()
+
+	return firstIndex(tx.Bucket(e.index)), nil
+}
+
+func firstIndex(index *bolt.Bucket) uint64 {
+	if val := index.Get(indexFirstKey); val != nil {
+		return bytesToUint64(val)
+	}
+	return 0
 }
 
 // StoreLog implements the store interface
@@ -156,12 +272,10 @@ func (e *Entry) StoreLogs(logs []*core.Log) error {
 	}
 	defer tx.Rollback()
 
-	indx, err := e.LastIndex()
-	if err != nil {
-		return err
-	}
-
 	bucket := tx.Bucket(e.bucket)
+	index := tx.Bucket(e.index)
+
+	indx := lastIndex(bucket, index)
 	for logIndx, log := range logs {
 		key := uint64ToBytes(indx + uint64(logIndx))
 
@@ -172,6 +286,9 @@ func (e *Entry) StoreLogs(logs []*core.Log) error {
 		if err := bucket.Put(key, val); err != nil {
 			return err
 		}
+		if err := putIndex(index, indx+uint64(logIndx), log); err != nil {
+			return err
+		}
 	}
 	return tx.Commit()
 }
@@ -186,8 +303,19 @@ func (e *Entry) RemoveLogs(indx uint64) error {
 	}
 	defer tx.Rollback()
 
+	index := tx.Bucket(e.index)
+
 	curs := tx.Bucket(e.bucket).Cursor()
-	for k, _ := curs.Seek(indxKey); k != nil; k, _ = curs.Next() {
+	for k, v := curs.Seek(indxKey); k != nil; k, v = curs.Next() {
+		var log core.Log
+		if err := log.UnmarshalJSON(v); err != nil {
+			return err
+		}
+		for _, key := range indexKeys(bytesToUint64(k), &log) {
+			if err := index.Delete(key); err != nil {
+				return err
+			}
+		}
 		if err := curs.Delete(); err != nil {
 			return err
 		}
@@ -196,6 +324,48 @@ func (e *Entry) RemoveLogs(indx uint64) error {
 	return tx.Commit()
 }
 
+// PruneLogs implements the store interface
+func (e *Entry) PruneLogs(indx uint64) error {
+	tx, err := e.conn.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	index := tx.Bucket(e.index)
+	if indx <= firstIndex(index) {
+		return nil
+	}
+
+	bucket := tx.Bucket(e.bucket)
+
+	// collect the keys first since deleting with the cursor may skip keys
+	logKeys, indexes := [][]byte{}, [][]byte{}
+	curs := bucket.Cursor()
+	for k, v := curs.First(); k != nil && bytesToUint64(k) < indx; k, v = curs.Next() {
+		var log core.Log
+		if err := log.UnmarshalJSON(v); err != nil {
+			return err
+		}
+		logKeys = append(logKeys, append([]byte{}, k...))
+		indexes = append(indexes, indexKeys(bytesToUint64(k), &log)...)
+	}
+	for _, key := range logKeys {
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+	}
+	for _, key := range indexes {
+		if err := index.Delete(key); err != nil {
+			return err
+		}
+	}
+	if err := index.Put(indexFirstKey, uint64ToBytes(indx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // GetLog implements the store interface
 func (e *Entry) GetLog(indx uint64, log *core.Log) error {
 	txn, err := e.conn.Begin(false)
@@ -213,6 +383,209 @@ func (e *Entry) GetLog(indx uint64, log *core.Log) error {
 	return nil
 }
 
+// Query implements the store interface. The logs are stored in block order,
+// so the block range is resolved to a range of indexes. The logs in the range
+// are looked up in the index by the transaction hash, the addresses or the
+// topics, in that order of preference, or scanned if there are none.
+func (e *Entry) Query(q *store.LogQuery) (*store.LogQueryResult, error) {
+	txn, err := e.conn.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	defer txn.Rollback()
+
+	bucket := txn.Bucket(e.bucket)
+	index := txn.Bucket(e.index)
+
+	from, to := q.Start, lastIndex(bucket, index)
+	if q.FromBlock != nil {
+		if indx := seekBlock(index, *q.FromBlock, to); indx > from {
+			from = indx
+		}
+	}
+	if q.ToBlock != nil && *q.ToBlock != math.MaxUint64 {
+		if indx := seekBlock(index, *q.ToBlock+1, to); indx < to {
+			to = indx
+		}
+	}
+
+	logs := []*core.Log{}
+	indexes := []uint64{}
+
+	// add appends the log at indx if it matches the query and
+	// returns false once the page is full
+	add := func(indx uint64, val []byte) (bool, error) {
+		var log core.Log
+		if err := log.UnmarshalJSON(val); err != nil {
+			return false, err
+		}
+		if q.Match(&log) {
+			logs = append(logs, &log)
+			indexes = append(indexes, indx)
+		}
+		return q.Limit == 0 || uint64(len(logs)) <= q.Limit, nil
+	}
+
+	if from < to {
+		prefixes, ok := queryPrefixes(q)
+		if ok {
+			err := lookupIndex(index, prefixes, from, to, func(indx uint64) (bool, error) {
+				return add(indx, bucket.Get(uint64ToBytes(indx)))
+			})
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			curs := bucket.Cursor()
+			for k, v := curs.Seek(uint64ToBytes(from)); k != nil && bytesToUint64(k) < to; k, v = curs.Next() {
+				next, err := add(bytesToUint64(k), v)
+				if err != nil {
+					return nil, err
+				}
+				if !next {
+					break
+				}
+			}
+		}
+	}
+	return q.Paginate(logs, indexes), nil
+}
+
+// queryPrefixes returns the index prefixes of the logs that may match the
+// query or false if the query has no indexed criteria besides the blocks
+func queryPrefixes(q *store.LogQuery) ([][]byte, bool) {
+	if q.TxHash != nil {
+		return [][]byte{indexKey(indexTxHash, q.TxHash[:])}, true
+	}
+	if len(q.Address) != 0 {
+		prefixes := [][]byte{}
+		for _, addr := range q.Address {
+			prefixes = append(prefixes, indexKey(indexAddress, addr[:]))
+		}
+		return prefixes, true
+	}
+	for i, topics := range q.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		prefixes := [][]byte{}
+		if i < maxTopics {
+			for _, topic := range topics {
+				prefixes = append(prefixes, indexKey(indexTopic, []byte{byte(i)}, topic[:]))
+			}
+		}
+		return prefixes, true
+	}
+	return nil, false
+}
+
+// indexCursor iterates over the indexes of the keys with a prefix
+type indexCursor struct {
+	curs   *bolt.Cursor
+	prefix []byte
+	to     uint64
+	indx   uint64
+	done   bool
+}
+
+func (c *indexCursor) set(k []byte) {
+	if k == nil || !bytes.HasPrefix(k, c.prefix) {
+		c.done = true
+		return
+	}
+	c.indx = bytesToUint64(k[len(c.prefix):])
+	if c.indx >= c.to {
+		c.done = true
+	}
+}
+
+// lookupIndex calls fn in ascending order with the indexes between from and to
+// of the keys in the index with any of the prefixes until fn returns false.
+// The cursors of the prefixes are merged so only the visited keys are read.
+func lookupIndex(index *bolt.Bucket, prefixes [][]byte, from, to uint64, fn func(indx uint64) (bool, error)) error {
+	cursors := make([]*indexCursor, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		c := &indexCursor{
+			curs:   index.Cursor(),
+			prefix: prefix,
+			to:     to,
+		}
+		k, _ := c.curs.Seek(append(append([]byte{}, prefix...), uint64ToBytes(from)...))
+		c.set(k)
+		cursors = append(cursors, c)
+	}
+
+	for {
+		var next *indexCursor
+		for _, c := range cursors {
+			if !c.done && (next == nil || c.indx < next.indx) {
+				next = c
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		indx := next.indx
+
+		// move all the cursors at the index to skip the duplicates
+		for _, c := range cursors {
+			if !c.done && c.indx == indx {
+				k, _ := c.curs.Next()
+				c.set(k)
+			}
+		}
+		ok, err := fn(indx)
+		if err != nil || !ok {
+			return err
+		}
+	}
+}
+
+// seekBlock returns the index of the first log at or after the block num
+func seekBlock(index *bolt.Bucket, num uint64, last uint64) uint64 {
+	prefix := []byte{indexBlock}
+	k, _ := index.Cursor().Seek(indexKey(indexBlock, uint64ToBytes(num)))
+	if k == nil || !bytes.HasPrefix(k, prefix) {
+		return last
+	}
+	return bytesToUint64(k[9:])
+}
+
+func putIndex(index *bolt.Bucket, indx uint64, log *core.Log) error {
+	for _, key := range indexKeys(indx, log) {
+		if err := index.Put(key, []byte{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexKeys returns the keys of the log in the index bucket
+func indexKeys(indx uint64, log *core.Log) [][]byte {
+	i := uint64ToBytes(indx)
+
+	keys := [][]byte{
+		indexKey(indexBlock, uint64ToBytes(log.BlockNumber), i),
+		indexKey(indexAddress, log.Address[:], i),
+		indexKey(indexTxHash, log.TransactionHash[:], i),
+	}
+	for pos, topic := range log.Topics {
+		if pos >= maxTopics {
+			break
+		}
+		keys = append(keys, indexKey(indexTopic, []byte{byte(pos)}, topic[:], i))
+	}
+	return keys
+}
+
+func indexKey(prefix byte, parts ...[]byte) []byte {
+	key := []byte{prefix}
+	for _, part := range parts {
+		key = append(key, part...)
+	}
+	return key
+}
+
 func bytesToUint64(b []byte) uint64 {
 	return binary.BigEndian.Uint64(b)
 }
@@ -4,9 +4,12 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
+	"github.com/deep-nl/ethgo/core"
 	"github.com/deep-nl/ethgo/tracker/store"
+	bolt "go.etcd.io/bbolt"
 )
 
 func setupDB(t *testing.T) (store.Store, func()) {
@@ -32,3 +35,90 @@ func setupDB(t *testing.T) (store.Store, func()) {
 func TestBoltDBStore(t *testing.T) {
 	store.TestStore(t, setupDB)
 }
+
+func TestBoltDBStore_Reindex(t *testing.T) {
+	s, close := setupDB(t)
+	defer close()
+
+	entry, err := s.GetEntry("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := []*core.Log{
+		{BlockNumber: 1, Address: core.Address{0x1}},
+		{BlockNumber: 2, Address: core.Address{0x2}},
+	}
+	if err := entry.StoreLogs(logs); err != nil {
+		t.Fatal(err)
+	}
+
+	// drop the index as in the stores created before it existed
+	b := s.(*BoltStore)
+	if err := b.conn.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket([]byte(string(dbIndex) + "1"))
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err = s.GetEntry("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := entry.Query(&store.LogQuery{Address: []core.Address{{0x2}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Indexes) != 1 || res.Indexes[0] != 1 {
+		t.Fatal("bad")
+	}
+}
+
+func TestBoltDBStore_LookupIndex(t *testing.T) {
+	s, close := setupDB(t)
+	defer close()
+
+	entry, err := s.GetEntry("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := []*core.Log{}
+	for i := 0; i < 10; i++ {
+		logs = append(logs, &core.Log{BlockNumber: uint64(i), Address: core.Address{byte(i % 3)}})
+	}
+	if err := entry.StoreLogs(logs); err != nil {
+		t.Fatal(err)
+	}
+
+	prefixes := [][]byte{
+		indexKey(indexAddress, core.Address{0x1}.Bytes()),
+		indexKey(indexAddress, core.Address{0x2}.Bytes()),
+		// duplicated prefix
+		indexKey(indexAddress, core.Address{0x1}.Bytes()),
+	}
+	lookup := func(from, to uint64, limit int) []uint64 {
+		res := []uint64{}
+		err := s.(*BoltStore).conn.View(func(tx *bolt.Tx) error {
+			index := tx.Bucket([]byte(string(dbIndex) + "1"))
+			return lookupIndex(index, prefixes, from, to, func(indx uint64) (bool, error) {
+				res = append(res, indx)
+				return len(res) < limit, nil
+			})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	// the indexes of the prefixes are merged in order
+	if res := lookup(0, 10, 10); !reflect.DeepEqual(res, []uint64{1, 2, 4, 5, 7, 8}) {
+		t.Fatalf("bad %v", res)
+	}
+	if res := lookup(2, 7, 10); !reflect.DeepEqual(res, []uint64{2, 4, 5}) {
+		t.Fatalf("bad %v", res)
+	}
+	// it stops once the callback returns false
+	if res := lookup(0, 10, 3); !reflect.DeepEqual(res, []uint64{1, 2, 4}) {
+		t.Fatalf("bad %v", res)
+	}
+}
@@ -0,0 +1,192 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/deep-nl/ethgo/core"
+)
+
+// exportVersion is the version of the export format
+const exportVersion = 1
+
+// exportBatchSize is the number of logs read or written at once
+const exportBatchSize = 1000
+
+// Types of the records of an export
+const (
+	recordHeader = "header"
+	recordKV     = "kv"
+	recordEntry  = "entry"
+	recordLog    = "log"
+)
+
+// record is a line of an export. An entry record is followed by the
+// log records of the entry and its index is the first index of the entry.
+type record struct {
+	Type    string    `json:"type"`
+	Version int       `json:"version,omitempty"`
+	Key     string    `json:"key,omitempty"`
+	Value   string    `json:"value,omitempty"`
+	Entry   string    `json:"entry,omitempty"`
+	Index   uint64    `json:"index,omitempty"`
+	Log     *core.Log `json:"log,omitempty"`
+}
+
+// Export writes the values and the entries of the store as
+// newline delimited JSON, which can be imported in any store
+func Export(s Store, w io.Writer) error {
+	enc := json.NewEncoder(w)
+
+	if err := enc.Encode(&record{Type: recordHeader, Version: exportVersion}); err != nil {
+		return err
+	}
+
+	keys, err := s.ListKeys("")
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		val, err := s.Get(key)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(&record{Type: recordKV, Key: key, Value: val}); err != nil {
+			return err
+		}
+	}
+
+	hashes, err := s.ListEntries()
+	if err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		entry, err := s.GetEntry(hash)
+		if err != nil {
+			return err
+		}
+		first, err := entry.FirstIndex()
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(&record{Type: recordEntry, Entry: hash, Index: first}); err != nil {
+			return err
+		}
+
+		q := &LogQuery{
+			Start: first,
+			Limit: exportBatchSize,
+		}
+		for {
+			res, err := entry.Query(q)
+			if err != nil {
+				return err
+			}
+			for i, log := range res.Logs {
+				if err := enc.Encode(&record{Type: recordLog, Entry: hash, Index: res.Indexes[i], Log: log}); err != nil {
+					return err
+				}
+			}
+			if res.Next == 0 {
+				break
+			}
+			q.Start = res.Next
+		}
+	}
+	return nil
+}
+
+// Import reads an export into the store. The entries of
+// the export must not have any logs in the store.
+func Import(s Store, r io.Reader) error {
+	dec := json.NewDecoder(r)
+
+	var header record
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("failed to read the header: %v", err)
+	}
+	if header.Type != recordHeader {
+		return fmt.Errorf("header expected but found '%s'", header.Type)
+	}
+	if header.Version != exportVersion {
+		return fmt.Errorf("export version %d not supported", header.Version)
+	}
+
+	var (
+		hash  string
+		entry Entry
+		next  uint64
+		batch []*core.Log
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := entry.StoreLogs(batch); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		var rec record
+		if err := dec.Decode(&rec); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+
+		switch rec.Type {
+		case recordKV:
+			if err := s.Set(rec.Key, rec.Value); err != nil {
+				return err
+			}
+
+		case recordEntry:
+			if err := flush(); err != nil {
+				return err
+			}
+			e, err := s.GetEntry(rec.Entry)
+			if err != nil {
+				return err
+			}
+			last, err := e.LastIndex()
+			if err != nil {
+				return err
+			}
+			if last != 0 {
+				return fmt.Errorf("entry %s is not empty", rec.Entry)
+			}
+			if err := e.PruneLogs(rec.Index); err != nil {
+				return err
+			}
+			hash, entry, next = rec.Entry, e, rec.Index
+
+		case recordLog:
+			if entry == nil || rec.Entry != hash {
+				return fmt.Errorf("log of entry %s out of place", rec.Entry)
+			}
+			if rec.Index != next {
+				return fmt.Errorf("log %d of entry %s expected but found %d", next, hash, rec.Index)
+			}
+			if rec.Log == nil {
+				return fmt.Errorf("log %d of entry %s is empty", rec.Index, hash)
+			}
+			batch = append(batch, rec.Log)
+			next++
+
+			if len(batch) == exportBatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+
+		default:
+			return fmt.Errorf("unknown record type '%s'", rec.Type)
+		}
+	}
+	return flush()
+}
@@ -1,10 +1,13 @@
 package inmem
 
 import (
-	"github.com/deep-nl/ethgo/core"
+	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
+	"github.com/deep-nl/ethgo/core"
+
 	"github.com/deep-nl/ethgo/tracker/store"
 )
 
@@ -51,6 +54,21 @@ func (i *InmemStore) ListPrefix(prefix string) ([]string, error) {
 	return res, nil
 }
 
+// ListKeys implements the store interface
+func (i *InmemStore) ListKeys(prefix string) ([]string, error) {
+	i.l.Lock()
+	defer i.l.Unlock()
+
+	res := []string{}
+	for k := range i.kv {
+		if strings.HasPrefix(k, prefix) {
+			res = append(res, k)
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
 // Set implements the store interface
 func (i *InmemStore) Set(k, v string) error {
 	i.l.Lock()
@@ -59,6 +77,14 @@ func (i *InmemStore) Set(k, v string) error {
 	return nil
 }
 
+// Delete implements the store interface
+func (i *InmemStore) Delete(k string) error {
+	i.l.Lock()
+	defer i.l.Unlock()
+	delete(i.kv, k)
+	return nil
+}
+
 // GetEntry implements the store interface
 func (i *InmemStore) GetEntry(hash string) (store.Entry, error) {
 	i.l.Lock()
@@ -74,21 +100,46 @@ func (i *InmemStore) GetEntry(hash string) (store.Entry, error) {
 	return e, nil
 }
 
+// ListEntries implements the store interface
+func (i *InmemStore) ListEntries() ([]string, error) {
+	i.l.Lock()
+	defer i.l.Unlock()
+
+	res := []string{}
+	for hash := range i.entries {
+		res = append(res, hash)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
 // Entry is a store.Entry implementation
 type Entry struct {
 	l    sync.RWMutex
 	logs []*core.Log
+
+	// first is the index of the first log in logs
+	first uint64
 }
 
 // LastIndex implements the store interface
 func (e *Entry) LastIndex() (uint64, error) {
 	e.l.Lock()
 	defer e.l.Unlock()
-	return uint64(len(e.logs)), nil
+	return e.first + uint64(len(e.logs)), nil
+}
+
+// FirstIndex implements the store interface
+func (e *Entry) FirstIndex() (uint64, error) {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return e.first, nil
 }
 
-// Logs returns the logs of the inmemory store
+// Logs returns the logs of the inmemory store that were not pruned
 func (e *Entry) Logs() []*core.Log {
+	e.l.RLock()
+	defer e.l.RUnlock()
 	return e.logs
 }
 
@@ -106,12 +157,63 @@ func (e *Entry) StoreLogs(logs []*core.Log) error {
 func (e *Entry) RemoveLogs(indx uint64) error {
 	e.l.Lock()
 	defer e.l.Unlock()
-	e.logs = e.logs[:indx]
+	if indx < e.first {
+		indx = e.first
+	}
+	if indx-e.first < uint64(len(e.logs)) {
+		e.logs = e.logs[:indx-e.first]
+	}
+	return nil
+}
+
+// PruneLogs implements the store interface
+func (e *Entry) PruneLogs(indx uint64) error {
+	e.l.Lock()
+	defer e.l.Unlock()
+	if indx <= e.first {
+		return nil
+	}
+	if n := indx - e.first; n < uint64(len(e.logs)) {
+		e.logs = append([]*core.Log{}, e.logs[n:]...)
+	} else {
+		e.logs = []*core.Log{}
+	}
+	e.first = indx
 	return nil
 }
 
 // GetLog implements the store interface
 func (e *Entry) GetLog(indx uint64, log *core.Log) error {
-	*log = *e.logs[indx]
+	e.l.RLock()
+	defer e.l.RUnlock()
+	if indx < e.first || indx-e.first >= uint64(len(e.logs)) {
+		return fmt.Errorf("log %d not found", indx)
+	}
+	*log = *e.logs[indx-e.first]
 	return nil
 }
+
+// Query implements the store interface
+func (e *Entry) Query(q *store.LogQuery) (*store.LogQueryResult, error) {
+	e.l.RLock()
+	defer e.l.RUnlock()
+
+	logs := []*core.Log{}
+	indexes := []uint64{}
+	start := uint64(0)
+	if q.Start > e.first {
+		start = q.Start - e.first
+	}
+	for i := start; i < uint64(len(e.logs)); i++ {
+		if !q.Match(e.logs[i]) {
+			continue
+		}
+		log := *e.logs[i]
+		logs = append(logs, &log)
+		indexes = append(indexes, e.first+i)
+		if q.Limit != 0 && uint64(len(logs)) > q.Limit {
+			break
+		}
+	}
+	return q.Paginate(logs, indexes), nil
+}
@@ -0,0 +1,284 @@
+package trackerpostgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// migration is a versioned change of the schema of the store
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sqlx.Tx, c *config) error
+}
+
+// migrations are the changes of the schema applied in order. The
+// applied versions are recorded in the migrations table.
+var migrations = []migration{
+	{1, "create kv table", migrateKV},
+	{2, "create logs table", migrateLogs},
+	{3, "move the logs of the legacy tables", migrateLegacyLogs},
+	{4, "create entries table", migrateEntries},
+}
+
+// migrate applies the migrations that are not recorded in the store
+func (p *PostgreSQLStore) migrate() error {
+	if p.config.schema != "" {
+		if _, err := p.db.Exec("CREATE SCHEMA IF NOT EXISTS " + pq.QuoteIdentifier(p.config.schema)); err != nil {
+			return err
+		}
+	}
+
+	table := p.config.table("migrations")
+	schema := `
+	CREATE TABLE IF NOT EXISTS ` + table + ` (
+		version 	integer PRIMARY KEY,
+		name 		text NOT NULL,
+		applied_at 	timestamptz NOT NULL DEFAULT now()
+	);
+	`
+	if _, err := p.db.Exec(schema); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if err := p.applyMigration(table, m); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %v", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+func (p *PostgreSQLStore) applyMigration(table string, m migration) error {
+	tx, err := p.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// serialize the stores that migrate the same tables at the same time
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext($1))", table); err != nil {
+		return err
+	}
+
+	var applied bool
+	if err := tx.Get(&applied, "SELECT EXISTS (SELECT 1 FROM "+table+" WHERE version=$1)", m.version); err != nil {
+		return err
+	}
+	if applied {
+		return nil
+	}
+
+	if err := m.up(tx, p.config); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO "+table+" (version, name) VALUES ($1, $2)", m.version, m.name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SchemaVersion returns the version of the last migration applied to the store
+func (p *PostgreSQLStore) SchemaVersion() (int, error) {
+	var version int
+	if err := p.db.Get(&version, "SELECT COALESCE(MAX(version), 0) FROM "+p.config.table("migrations")); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func migrateKV(tx *sqlx.Tx, c *config) error {
+	// the kv table of the legacy stores has the same layout
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS ` + c.table("kv") + ` (
+		key text PRIMARY KEY,
+		val text
+	);
+	`)
+	return err
+}
+
+func migrateLogs(tx *sqlx.Tx, c *config) error {
+	logs := c.table("logs")
+	index := func(name string) string {
+		return pq.QuoteIdentifier(c.prefix + "logs_" + name)
+	}
+
+	_, err := tx.Exec(`
+	CREATE TABLE ` + logs + ` (
+		entry 		text NOT NULL,
+		indx 		bigint NOT NULL,
+		block_num 	numeric(20) NOT NULL,
+		block_hash 	bytea NOT NULL,
+		tx_index 	bigint NOT NULL,
+		tx_hash 	bytea NOT NULL,
+		log_index 	bigint NOT NULL,
+		address 	bytea NOT NULL,
+		topic0 		bytea,
+		topic1 		bytea,
+		topic2 		bytea,
+		topic3 		bytea,
+		data 		bytea,
+		removed 	boolean NOT NULL DEFAULT false,
+		PRIMARY KEY (entry, indx)
+	);
+
+	CREATE INDEX ` + index("block_num") + ` ON ` + logs + ` (entry, block_num);
+	CREATE INDEX ` + index("address") + ` ON ` + logs + ` (entry, address);
+	CREATE INDEX ` + index("tx_hash") + ` ON ` + logs + ` (entry, tx_hash);
+	CREATE INDEX ` + index("topic0") + ` ON ` + logs + ` (entry, topic0);
+	CREATE INDEX ` + index("topic1") + ` ON ` + logs + ` (entry, topic1);
+	CREATE INDEX ` + index("topic2") + ` ON ` + logs + ` (entry, topic2);
+	CREATE INDEX ` + index("topic3") + ` ON ` + logs + ` (entry, topic3);
+	`)
+	return err
+}
+
+func migrateEntries(tx *sqlx.Tx, c *config) error {
+	entries := c.table("entries")
+
+	_, err := tx.Exec(`
+	CREATE TABLE ` + entries + ` (
+		entry 		text PRIMARY KEY,
+		first_index bigint NOT NULL DEFAULT 0
+	);
+
+	INSERT INTO ` + entries + ` (entry) SELECT DISTINCT entry FROM ` + c.table("logs") + `;
+	`)
+	return err
+}
+
+// legacyTablePrefix is the prefix of the tables of the legacy stores,
+// which had one table per entry with the values encoded as hex text
+const legacyTablePrefix = "logs_"
+
+// maxIdentifierLen is the maximum length of an identifier in PostgreSQL,
+// the longer table names of the legacy stores were truncated
+const maxIdentifierLen = 63
+
+// legacyColumns are the columns of the tables of the legacy stores
+var legacyColumns = map[string]string{
+	"indx":       "numeric",
+	"tx_index":   "numeric",
+	"tx_hash":    "text",
+	"block_num":  "numeric",
+	"block_hash": "text",
+	"address":    "text",
+	"topics":     "text",
+	"data":       "text",
+}
+
+// migrateLegacyLogs moves the logs of the legacy tables to the logs table.
+// The legacy stores did not support prefixes so it only runs without one.
+// Only the tables with the columns of the legacy tables and whose entry has
+// a filter in the kv table are moved, any other table is left untouched.
+func migrateLegacyLogs(tx *sqlx.Tx, c *config) error {
+	if c.prefix != "" {
+		return nil
+	}
+
+	var tables []string
+	query := "SELECT table_name FROM information_schema.tables WHERE table_schema = COALESCE(NULLIF($1::text, ''), current_schema()) AND table_type = 'BASE TABLE' AND substr(table_name::text, 1, length($2::text)) = $2"
+	if err := tx.Select(&tables, query, c.schema, legacyTablePrefix); err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		ok, err := isLegacyTable(tx, c, table)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		hash, ok, err := legacyEntryHash(tx, c, table)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		legacy := c.table(table)
+		topic := func(i int) string {
+			part := fmt.Sprintf("split_part(topics, ',', %d)", i)
+			return "CASE WHEN " + part + " = '' THEN NULL ELSE decode(substr(" + part + ", 3), 'hex') END"
+		}
+
+		_, err = tx.Exec(`
+		INSERT INTO `+c.table("logs")+` (entry, `+logColumns+`)
+		SELECT
+			$1,
+			indx,
+			block_num,
+			decode(substr(block_hash, 3), 'hex'),
+			tx_index,
+			decode(substr(tx_hash, 3), 'hex'),
+			0,
+			decode(substr(address, 3), 'hex'),
+			`+topic(1)+`,
+			`+topic(2)+`,
+			`+topic(3)+`,
+			`+topic(4)+`,
+			CASE WHEN data = '' THEN NULL ELSE decode(substr(data, 3), 'hex') END,
+			false
+		FROM `+legacy, hash)
+		if err != nil {
+			return fmt.Errorf("failed to move the logs of %s: %v", table, err)
+		}
+		if _, err := tx.Exec("DROP TABLE " + legacy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isLegacyTable checks that the table has the columns of the legacy tables
+func isLegacyTable(tx *sqlx.Tx, c *config, table string) (bool, error) {
+	var columns []struct {
+		Name string `db:"column_name"`
+		Type string `db:"data_type"`
+	}
+	query := "SELECT column_name, data_type FROM information_schema.columns WHERE table_schema = COALESCE(NULLIF($1::text, ''), current_schema()) AND table_name = $2"
+	if err := tx.Select(&columns, query, c.schema, table); err != nil {
+		return false, err
+	}
+	if len(columns) != len(legacyColumns) {
+		return false, nil
+	}
+	for _, col := range columns {
+		if typ, ok := legacyColumns[col.Name]; !ok || typ != col.Type {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// legacyEntryHash returns the hash of the entry of a legacy table, which must
+// have the filter config that the tracker stores in the kv table. If the name
+// of the table was truncated, the hash is resolved with the key of the filter.
+// It returns false if there is no filter for the table.
+func legacyEntryHash(tx *sqlx.Tx, c *config, table string) (string, bool, error) {
+	hash := strings.TrimPrefix(table, legacyTablePrefix)
+
+	var keys []string
+	if len(table) < maxIdentifierLen {
+		if err := tx.Select(&keys, "SELECT key FROM "+c.table("kv")+" WHERE key = $1", "filter_"+hash); err != nil {
+			return "", false, err
+		}
+	} else {
+		if err := tx.Select(&keys, "SELECT key FROM "+c.table("kv")+" WHERE substr(key, 1, length($1::text)) = $1", "filter_"+hash); err != nil {
+			return "", false, err
+		}
+	}
+	if len(keys) == 0 {
+		return "", false, nil
+	}
+	if len(keys) != 1 {
+		return "", false, fmt.Errorf("cannot resolve the entry of the legacy table %s", table)
+	}
+	return strings.TrimPrefix(keys[0], "filter_"), true, nil
+}
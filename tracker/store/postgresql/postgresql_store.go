@@ -2,43 +2,81 @@ package trackerpostgresql
 
 import (
 	"database/sql"
-	"encoding/hex"
 	"fmt"
-	"github.com/deep-nl/ethgo/core"
 	"strings"
 
+	"github.com/deep-nl/ethgo/core"
 	"github.com/deep-nl/ethgo/tracker/store"
 	"github.com/jmoiron/sqlx"
-
-	// Enable postgres for sqlx
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 var _ store.Store = (*PostgreSQLStore)(nil)
 
+// Option is an option to configure the PostgreSQL store
+type Option func(*config)
+
+type config struct {
+	schema string
+	prefix string
+}
+
+// WithSchema sets the schema of the tables. By default, the
+// tables are created in the current schema of the connection.
+func WithSchema(schema string) Option {
+	return func(c *config) {
+		c.schema = schema
+	}
+}
+
+// WithTablePrefix sets a prefix for the names of the tables so that
+// multiple stores can share the same schema
+func WithTablePrefix(prefix string) Option {
+	return func(c *config) {
+		c.prefix = prefix
+	}
+}
+
+// table returns the quoted name of the table with the schema and the prefix
+func (c *config) table(name string) string {
+	name = pq.QuoteIdentifier(c.prefix + name)
+	if c.schema != "" {
+		name = pq.QuoteIdentifier(c.schema) + "." + name
+	}
+	return name
+}
+
 // PostgreSQLStore is a tracker store implementation that uses PostgreSQL as a backend.
 type PostgreSQLStore struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	config *config
 }
 
 // NewPostgreSQLStore creates a new PostgreSQL store
-func NewPostgreSQLStore(endpoint string) (*PostgreSQLStore, error) {
+func NewPostgreSQLStore(endpoint string, opts ...Option) (*PostgreSQLStore, error) {
 	db, err := sql.Open("postgres", endpoint)
 	if err != nil {
 		return nil, err
 	}
-	return NewSQLStore(db, "postgres")
+	return NewSQLStore(db, "postgres", opts...)
 }
 
-// NewSQLStore creates a new store with an sql driver
-func NewSQLStore(db *sql.DB, driver string) (*PostgreSQLStore, error) {
-	sqlxDB := sqlx.NewDb(db, driver)
+// NewSQLStore creates a new store with an sql driver. The schema of
+// the store is migrated to the latest version if required.
+func NewSQLStore(db *sql.DB, driver string, opts ...Option) (*PostgreSQLStore, error) {
+	config := &config{}
+	for _, opt := range opts {
+		opt(config)
+	}
 
-	// create the kv database if it does not exists
-	if _, err := db.Exec(kvSQLSchema); err != nil {
-		return nil, err
+	p := &PostgreSQLStore{
+		db:     sqlx.NewDb(db, driver),
+		config: config,
 	}
-	return &PostgreSQLStore{db: sqlxDB}, nil
+	if err := p.migrate(); err != nil {
+		return nil, fmt.Errorf("failed to migrate the schema: %v", err)
+	}
+	return p, nil
 }
 
 // Close implements the store interface
@@ -49,7 +87,7 @@ func (p *PostgreSQLStore) Close() error {
 // Get implements the store interface
 func (p *PostgreSQLStore) Get(k string) (string, error) {
 	var out string
-	if err := p.db.Get(&out, "SELECT val FROM kv WHERE key=$1", string(k)); err != nil {
+	if err := p.db.Get(&out, "SELECT val FROM "+p.config.table("kv")+" WHERE key=$1", k); err != nil {
 		if err == sql.ErrNoRows {
 			return "", nil
 		}
@@ -61,7 +99,16 @@ func (p *PostgreSQLStore) Get(k string) (string, error) {
 // ListPrefix implements the store interface
 func (p *PostgreSQLStore) ListPrefix(prefix string) ([]string, error) {
 	var out []string
-	if err := p.db.Select(&out, "SELECT val FROM kv WHERE key LIKE $1", string(prefix)+"%"); err != nil {
+	if err := p.db.Select(&out, "SELECT val FROM "+p.config.table("kv")+" WHERE substr(key, 1, length($1::text)) = $1", prefix); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListKeys implements the store interface
+func (p *PostgreSQLStore) ListKeys(prefix string) ([]string, error) {
+	var out []string
+	if err := p.db.Select(&out, "SELECT key FROM "+p.config.table("kv")+" WHERE substr(key, 1, length($1::text)) = $1 ORDER BY key", prefix); err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -69,7 +116,15 @@ func (p *PostgreSQLStore) ListPrefix(prefix string) ([]string, error) {
 
 // Set implements the store interface
 func (p *PostgreSQLStore) Set(k, v string) error {
-	if _, err := p.db.Exec("INSERT INTO kv (key, val) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET val = $2", k, v); err != nil {
+	if _, err := p.db.Exec("INSERT INTO "+p.config.table("kv")+" (key, val) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET val = $2", k, v); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete implements the store interface
+func (p *PostgreSQLStore) Delete(k string) error {
+	if _, err := p.db.Exec("DELETE FROM "+p.config.table("kv")+" WHERE key=$1", k); err != nil {
 		return err
 	}
 	return nil
@@ -77,69 +132,100 @@ func (p *PostgreSQLStore) Set(k, v string) error {
 
 // GetEntry implements the store interface
 func (p *PostgreSQLStore) GetEntry(hash string) (store.Entry, error) {
-	tableName := "logs_" + hash
-	if _, err := p.db.Exec(logSQLSchema(tableName)); err != nil {
-		return nil, err
-	}
 	e := &Entry{
-		table: tableName,
-		db:    p.db,
+		hash:    hash,
+		table:   p.config.table("logs"),
+		entries: p.config.table("entries"),
+		db:      p.db,
+	}
+	if _, err := p.db.Exec("INSERT INTO "+e.entries+" (entry) VALUES ($1) ON CONFLICT (entry) DO NOTHING", hash); err != nil {
+		return nil, err
 	}
 	return e, nil
 }
 
-// Entry is an store.Entry implementation
+// ListEntries implements the store interface
+func (p *PostgreSQLStore) ListEntries() ([]string, error) {
+	var out []string
+	if err := p.db.Select(&out, "SELECT entry FROM "+p.config.table("entries")+" ORDER BY entry"); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Entry is an store.Entry implementation. The logs of all
+// the entries are stored in the same table.
 type Entry struct {
-	table string
-	db    *sqlx.DB
+	hash    string
+	table   string
+	entries string
+	db      *sqlx.DB
 }
 
 // LastIndex implements the store interface
 func (e *Entry) LastIndex() (uint64, error) {
+	return e.lastIndex(e.db)
+}
+
+// lastIndex returns the index after the last log or the
+// first index if all the logs were pruned
+func (e *Entry) lastIndex(db sqlx.Queryer) (uint64, error) {
+	query := "SELECT GREATEST(first_index, COALESCE((SELECT MAX(indx) + 1 FROM " + e.table + " WHERE entry=$1), 0)) FROM " + e.entries + " WHERE entry=$1"
+
 	var index uint64
-	if err := e.db.Get(&index, "SELECT indx FROM "+e.table+" ORDER BY indx DESC LIMIT 1"); err != nil {
-		if err == sql.ErrNoRows {
-			return 0, nil
-		}
+	if err := sqlx.Get(db, &index, query, e.hash); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+// FirstIndex implements the store interface
+func (e *Entry) FirstIndex() (uint64, error) {
+	var index uint64
+	if err := e.db.Get(&index, "SELECT first_index FROM "+e.entries+" WHERE entry=$1", e.hash); err != nil {
 		return 0, err
 	}
-	return index + 1, nil
+	return index, nil
 }
 
 // StoreLogs implements the store interface
 func (e *Entry) StoreLogs(logs []*core.Log) error {
-	lastIndex, err := e.LastIndex()
+	tx, err := e.db.Beginx()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	tx, err := e.db.Beginx()
+	lastIndex, err := e.lastIndex(tx)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
 
-	query := "INSERT INTO " + e.table + " (indx, tx_index, tx_hash, block_num, block_hash, address, data, topics) VALUES (:indx, :tx_index, :tx_hash, :block_num, :block_hash, :address, :data, :topics)"
+	query := "INSERT INTO " + e.table + " (entry, " + logColumns + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)"
 
 	for indx, log := range logs {
-		topics := []string{}
-		for _, topic := range log.Topics {
-			topics = append(topics, topic.String())
+		if len(log.Topics) > maxTopics {
+			return fmt.Errorf("log with %d topics, at most %d expected", len(log.Topics), maxTopics)
 		}
-		obj := &logObj{
-			Index:     lastIndex + uint64(indx),
-			TxIndex:   log.TransactionIndex,
-			TxHash:    log.TransactionHash.String(),
-			BlockNum:  log.BlockNumber,
-			BlockHash: log.BlockHash.String(),
-			Address:   log.Address.String(),
-			Topics:    strings.Join(topics, ","),
-		}
-		if log.Data != nil {
-			obj.Data = "0x" + hex.EncodeToString(log.Data)
+		topics := make([]interface{}, maxTopics)
+		for i, topic := range log.Topics {
+			topics[i] = topic.Bytes()
 		}
 
-		if _, err := tx.NamedExec(query, obj); err != nil {
+		_, err := tx.Exec(query,
+			e.hash,
+			lastIndex+uint64(indx),
+			log.BlockNumber,
+			log.BlockHash.Bytes(),
+			log.TransactionIndex,
+			log.TransactionHash.Bytes(),
+			log.LogIndex,
+			log.Address.Bytes(),
+			topics[0], topics[1], topics[2], topics[3],
+			log.Data,
+			log.Removed,
+		)
+		if err != nil {
 			return err
 		}
 	}
@@ -151,89 +237,153 @@ func (e *Entry) StoreLogs(logs []*core.Log) error {
 
 // RemoveLogs implements the store interface
 func (e *Entry) RemoveLogs(indx uint64) error {
-	if _, err := e.db.Exec("DELETE FROM "+e.table+" WHERE indx >= $1", indx); err != nil {
+	if _, err := e.db.Exec("DELETE FROM "+e.table+" WHERE entry=$1 AND indx >= $2", e.hash, indx); err != nil {
 		return err
 	}
 	return nil
 }
 
-// GetLog implements the store interface
-func (e *Entry) GetLog(indx uint64, log *core.Log) error {
-	obj := logObj{}
-	if err := e.db.Get(&obj, "SELECT * FROM "+e.table+" WHERE indx=$1", indx); err != nil {
+// PruneLogs implements the store interface
+func (e *Entry) PruneLogs(indx uint64) error {
+	tx, err := e.db.Beginx()
+	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	log.TransactionIndex = obj.TxIndex
-	if err := log.TransactionHash.UnmarshalText([]byte(obj.TxHash)); err != nil {
+	if _, err := tx.Exec("DELETE FROM "+e.table+" WHERE entry=$1 AND indx < $2", e.hash, indx); err != nil {
 		return err
 	}
-	log.BlockNumber = obj.BlockNum
-	if err := log.BlockHash.UnmarshalText([]byte(obj.BlockHash)); err != nil {
+	if _, err := tx.Exec("UPDATE "+e.entries+" SET first_index = GREATEST(first_index, $2) WHERE entry=$1", e.hash, indx); err != nil {
 		return err
 	}
-	if err := log.Address.UnmarshalText([]byte(obj.Address)); err != nil {
+	return tx.Commit()
+}
+
+// GetLog implements the store interface
+func (e *Entry) GetLog(indx uint64, log *core.Log) error {
+	obj := logObj{}
+	if err := e.db.Get(&obj, "SELECT "+logColumns+" FROM "+e.table+" WHERE entry=$1 AND indx=$2", e.hash, indx); err != nil {
 		return err
 	}
+	obj.decode(log)
+	return nil
+}
 
-	if obj.Topics != "" {
-		log.Topics = []core.Hash{}
-		for _, item := range strings.Split(obj.Topics, ",") {
-			var topic core.Hash
-			if err := topic.UnmarshalText([]byte(item)); err != nil {
-				return err
-			}
-			log.Topics = append(log.Topics, topic)
+// Query implements the store interface
+func (e *Entry) Query(q *store.LogQuery) (*store.LogQueryResult, error) {
+	where := []string{}
+	args := []interface{}{}
+
+	// arg adds an argument and returns its placeholder
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	in := func(vals [][]byte) string {
+		items := []string{}
+		for _, v := range vals {
+			items = append(items, arg(v))
 		}
-	} else {
-		log.Topics = nil
+		return "(" + strings.Join(items, ", ") + ")"
 	}
 
-	if obj.Data != "" {
-		if !strings.HasPrefix(obj.Data, "0x") {
-			return fmt.Errorf("0x prefix not found in data")
+	where = append(where, "entry = "+arg(e.hash), "indx >= "+arg(q.Start))
+	if q.FromBlock != nil {
+		where = append(where, "block_num >= "+arg(*q.FromBlock))
+	}
+	if q.ToBlock != nil {
+		where = append(where, "block_num <= "+arg(*q.ToBlock))
+	}
+	if q.TxHash != nil {
+		where = append(where, "tx_hash = "+arg(q.TxHash.Bytes()))
+	}
+	if len(q.Address) != 0 {
+		addrs := [][]byte{}
+		for _, addr := range q.Address {
+			addrs = append(addrs, addr.Bytes())
 		}
-		buf, err := hex.DecodeString(obj.Data[2:])
-		if err != nil {
-			return err
+		where = append(where, "address IN "+in(addrs))
+	}
+	for i, topics := range q.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		if i >= maxTopics {
+			// no log has a topic in this position
+			return &store.LogQueryResult{}, nil
+		}
+		hashes := [][]byte{}
+		for _, topic := range topics {
+			hashes = append(hashes, topic.Bytes())
 		}
-		log.Data = buf
-	} else {
-		log.Data = nil
+		where = append(where, fmt.Sprintf("topic%d IN %s", i, in(hashes)))
 	}
 
-	return nil
+	query := "SELECT " + logColumns + " FROM " + e.table + " WHERE " + strings.Join(where, " AND ") + " ORDER BY indx"
+	if q.Limit != 0 {
+		// query one more log to know if there is a next page
+		query += " LIMIT " + arg(q.Limit+1)
+	}
+
+	var objs []logObj
+	if err := e.db.Select(&objs, query, args...); err != nil {
+		return nil, err
+	}
+
+	logs := []*core.Log{}
+	indexes := []uint64{}
+	for _, obj := range objs {
+		log := new(core.Log)
+		obj.decode(log)
+
+		logs = append(logs, log)
+		indexes = append(indexes, obj.Index)
+	}
+	return q.Paginate(logs, indexes), nil
 }
 
+// maxTopics is the maximum number of topics of a log
+const maxTopics = 4
+
+var logColumns = "indx, block_num, block_hash, tx_index, tx_hash, log_index, address, topic0, topic1, topic2, topic3, data, removed"
+
 type logObj struct {
 	Index     uint64 `db:"indx"`
-	TxIndex   uint64 `db:"tx_index"`
-	TxHash    string `db:"tx_hash"`
 	BlockNum  uint64 `db:"block_num"`
-	BlockHash string `db:"block_hash"`
-	Address   string `db:"address"`
-	Topics    string `db:"topics"`
-	Data      string `db:"data"`
-}
-
-var kvSQLSchema = `
-CREATE TABLE IF NOT EXISTS kv (
-	key text unique,
-	val text
-);
-`
-
-func logSQLSchema(name string) string {
-	return `
-	CREATE TABLE IF NOT EXISTS ` + name + ` (
-		indx 		numeric,
-		tx_index 	numeric,
-		tx_hash 	text,
-		block_num 	numeric,
-		block_hash 	text,
-		address 	text,
-		topics 		text,
-		data 		text
-	);
-	`
+	BlockHash []byte `db:"block_hash"`
+	TxIndex   uint64 `db:"tx_index"`
+	TxHash    []byte `db:"tx_hash"`
+	LogIndex  uint64 `db:"log_index"`
+	Address   []byte `db:"address"`
+	Topic0    []byte `db:"topic0"`
+	Topic1    []byte `db:"topic1"`
+	Topic2    []byte `db:"topic2"`
+	Topic3    []byte `db:"topic3"`
+	Data      []byte `db:"data"`
+	Removed   bool   `db:"removed"`
+}
+
+func (obj *logObj) decode(log *core.Log) {
+	log.BlockNumber = obj.BlockNum
+	log.BlockHash = core.BytesToHash(obj.BlockHash)
+	log.TransactionIndex = obj.TxIndex
+	log.TransactionHash = core.BytesToHash(obj.TxHash)
+	log.LogIndex = obj.LogIndex
+	log.Address = core.BytesToAddress(obj.Address)
+	log.Removed = obj.Removed
+
+	// the topics are stored in order, the first null column is the end
+	log.Topics = nil
+	for _, topic := range [][]byte{obj.Topic0, obj.Topic1, obj.Topic2, obj.Topic3} {
+		if topic == nil {
+			break
+		}
+		log.Topics = append(log.Topics, core.BytesToHash(topic))
+	}
+
+	log.Data = nil
+	if len(obj.Data) != 0 {
+		log.Data = obj.Data
+	}
 }
@@ -1,42 +1,150 @@
 package trackerpostgresql
 
-//func setupDB(t *testing.T) (store.Store, func()) {
-//	pool, err := dockertest.NewPool("")
-//	if err != nil {
-//		t.Fatalf("Could not connect to docker: %s", err)
-//	}
-//
-//	resource, err := pool.Run("postgres", "latest", []string{"POSTGRES_HOST_AUTH_METHOD=trust"})
-//	if err != nil {
-//		t.Fatalf("Could not start resource: %s", err)
-//	}
-//
-//	endpoint := fmt.Sprintf("postgres://postgres@localhost:%s/postgres?sslmode=disable", resource.GetPort("5432/tcp"))
-//
-//	// wait for the db to be running
-//	if err := pool.Retry(func() error {
-//		db, err := sql.Open("postgres", endpoint)
-//		if err != nil {
-//			return err
-//		}
-//		return db.Ping()
-//	}); err != nil {
-//		t.Fatal(err)
-//	}
-//
-//	cleanup := func() {
-//		if err := pool.Purge(resource); err != nil {
-//			t.Fatalf("Could not purge resource: %s", err)
-//		}
-//	}
-//
-//	store, err := NewPostgreSQLStore(endpoint)
-//	if err != nil {
-//		t.Fatal(err)
-//	}
-//	return store, cleanup
-//}
-
-//func TestPostgreSQLStore(t *testing.T) {
-//	store.TestStore(t, setupDB)
-//}
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/tracker/store"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Table(t *testing.T) {
+	c := &config{}
+	require.Equal(t, `"kv"`, c.table("kv"))
+
+	WithTablePrefix("tracker_")(c)
+	require.Equal(t, `"tracker_logs"`, c.table("logs"))
+
+	WithSchema("eth")(c)
+	require.Equal(t, `"eth"."tracker_logs"`, c.table("logs"))
+}
+
+// testDSN returns the endpoint of the database of the integration
+// tests, they are skipped if POSTGRES_TEST_DSN is not specified
+func testDSN(t *testing.T) string {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN not specified")
+	}
+	return dsn
+}
+
+// testSchema creates an empty schema that is dropped at the end of the test
+func testSchema(t *testing.T, dsn string) string {
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+
+	schema := fmt.Sprintf("ethgo_test_%d", time.Now().UnixNano())
+	_, err = db.Exec("CREATE SCHEMA " + pq.QuoteIdentifier(schema))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.Exec("DROP SCHEMA " + pq.QuoteIdentifier(schema) + " CASCADE")
+		db.Close()
+	})
+	return schema
+}
+
+func setupDB(t *testing.T) (store.Store, func()) {
+	dsn := testDSN(t)
+
+	s, err := NewPostgreSQLStore(dsn, WithSchema(testSchema(t, dsn)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, func() {
+		s.Close()
+	}
+}
+
+func TestPostgreSQLStore(t *testing.T) {
+	store.TestStore(t, setupDB)
+}
+
+func TestPostgreSQLStore_MigrateLegacy(t *testing.T) {
+	dsn := testDSN(t)
+	schema := testSchema(t, dsn)
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	table := func(name string) string {
+		return pq.QuoteIdentifier(schema) + "." + pq.QuoteIdentifier(name)
+	}
+	legacyTable := func(name string) {
+		_, err := db.Exec(`
+		CREATE TABLE ` + table(name) + ` (
+			indx 		numeric,
+			tx_index 	numeric,
+			tx_hash 	text,
+			block_num 	numeric,
+			block_hash 	text,
+			address 	text,
+			topics 		text,
+			data 		text
+		);`)
+		require.NoError(t, err)
+	}
+
+	// kv table of the legacy store with the filter of the entry "a"
+	_, err = db.Exec("CREATE TABLE " + table("kv") + " (key text unique, val text)")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO "+table("kv")+" (key, val) VALUES ($1, $2)", "filter_a", "{}")
+	require.NoError(t, err)
+
+	log := &core.Log{
+		TransactionIndex: 1,
+		TransactionHash:  core.Hash{0x1},
+		BlockNumber:      10,
+		BlockHash:        core.Hash{0x2},
+		Address:          core.Address{0x3},
+		Topics:           []core.Hash{{0x4}, {0x5}},
+		Data:             []byte{0x6},
+	}
+	legacyTable("logs_a")
+	_, err = db.Exec("INSERT INTO "+table("logs_a")+" (indx, tx_index, tx_hash, block_num, block_hash, address, topics, data) VALUES (0, $1, $2, $3, $4, $5, $6, $7)",
+		log.TransactionIndex,
+		log.TransactionHash.String(),
+		log.BlockNumber,
+		log.BlockHash.String(),
+		log.Address.String(),
+		log.Topics[0].String()+","+log.Topics[1].String(),
+		"0x06",
+	)
+	require.NoError(t, err)
+
+	// a legacy table without a filter and a table of another application
+	legacyTable("logs_b")
+	_, err = db.Exec("CREATE TABLE " + table("logs_other") + " (id integer)")
+	require.NoError(t, err)
+
+	s, err := NewPostgreSQLStore(dsn, WithSchema(schema))
+	require.NoError(t, err)
+	defer s.Close()
+
+	entry, err := s.GetEntry("a")
+	require.NoError(t, err)
+
+	last, err := entry.LastIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), last)
+
+	var found core.Log
+	require.NoError(t, entry.GetLog(0, &found))
+	require.Equal(t, log, &found)
+
+	exists := func(name string) bool {
+		var ok bool
+		require.NoError(t, db.QueryRow("SELECT to_regclass($1) IS NOT NULL", table(name)).Scan(&ok))
+		return ok
+	}
+	require.False(t, exists("logs_a"))
+	require.True(t, exists("logs_b"))
+	require.True(t, exists("logs_other"))
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"github.com/deep-nl/ethgo/core"
+)
+
+// LogQuery is a query over the logs of an entry. The criteria are
+// combined with an AND and the zero value matches all the logs.
+type LogQuery struct {
+	// FromBlock and ToBlock are the range of blocks (inclusive)
+	FromBlock *uint64
+	ToBlock   *uint64
+
+	// Address matches the logs emitted by any of the addresses
+	Address []core.Address
+
+	// Topics matches the logs by topic position. Each position matches
+	// any of its topics and an empty position matches any topic.
+	Topics [][]core.Hash
+
+	// TxHash matches the logs of a transaction
+	TxHash *core.Hash
+
+	// Start is the index of the first log to consider
+	Start uint64
+
+	// Limit is the maximum number of logs to return, zero means no limit
+	Limit uint64
+}
+
+// SetFromBlock sets the first block of the range
+func (q *LogQuery) SetFromBlock(num uint64) {
+	q.FromBlock = &num
+}
+
+// SetToBlock sets the last block of the range
+func (q *LogQuery) SetToBlock(num uint64) {
+	q.ToBlock = &num
+}
+
+// SetTxHash sets the transaction of the logs
+func (q *LogQuery) SetTxHash(hash core.Hash) {
+	q.TxHash = &hash
+}
+
+// Match returns whether the log matches the criteria of the query
+func (q *LogQuery) Match(log *core.Log) bool {
+	if q.FromBlock != nil && log.BlockNumber < *q.FromBlock {
+		return false
+	}
+	if q.ToBlock != nil && log.BlockNumber > *q.ToBlock {
+		return false
+	}
+	if q.TxHash != nil && log.TransactionHash != *q.TxHash {
+		return false
+	}
+	if len(q.Address) != 0 && !containsAddress(q.Address, log.Address) {
+		return false
+	}
+	for i, topics := range q.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		if i >= len(log.Topics) || !containsHash(topics, log.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// LogQueryResult is a page of the results of a query
+type LogQueryResult struct {
+	// Logs are the logs that match the query
+	Logs []*core.Log
+
+	// Indexes are the indexes in the entry of the logs
+	Indexes []uint64
+
+	// Next is the Start of the query for the next page. It is
+	// zero when there are no more logs to return.
+	Next uint64
+}
+
+// Paginate returns the result of the matched logs and their indexes,
+// which may include one log more than the limit of the query
+func (q *LogQuery) Paginate(logs []*core.Log, indexes []uint64) *LogQueryResult {
+	res := &LogQueryResult{
+		Logs:    logs,
+		Indexes: indexes,
+	}
+	if q.Limit != 0 && uint64(len(logs)) > q.Limit {
+		res.Next = indexes[q.Limit]
+		res.Logs = logs[:q.Limit]
+		res.Indexes = indexes[:q.Limit]
+	}
+	return res
+}
+
+func containsAddress(addrs []core.Address, addr core.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func containsHash(hashes []core.Hash, hash core.Hash) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
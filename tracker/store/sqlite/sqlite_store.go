@@ -0,0 +1,458 @@
+package trackersqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/tracker/store"
+
+	// Enable the pure-go sqlite driver
+	_ "modernc.org/sqlite"
+)
+
+var _ store.Store = (*SQLiteStore)(nil)
+
+// maxTopics is the maximum number of topics of a log
+const maxTopics = 4
+
+// SQLiteStore is a tracker store implementation that uses an embedded SQLite
+// database as a backend. The logs of all the entries are stored in the same
+// table with one column per topic.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// New creates a new SQLite store in the file of the path
+func New(path string) (*SQLiteStore, error) {
+	dsn := "file:" + path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// sqlite only supports one writer at a time
+	db.SetMaxOpenConns(1)
+
+	if err := setupSchema(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func setupSchema(db *sql.DB) error {
+	if _, err := db.Exec(sqlSchema); err != nil {
+		return err
+	}
+
+	// add the first index to the entries of the stores created before it
+	var found bool
+	if err := db.QueryRow("SELECT COUNT(*) > 0 FROM pragma_table_info('entries') WHERE name = 'first_index'").Scan(&found); err != nil {
+		return err
+	}
+	if !found {
+		if _, err := db.Exec("ALTER TABLE entries ADD COLUMN first_index INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DB returns the underlying database
+func (s *SQLiteStore) DB() *sql.DB {
+	return s.db
+}
+
+// Close implements the store interface
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Get implements the store interface
+func (s *SQLiteStore) Get(k string) (string, error) {
+	var out string
+	if err := s.db.QueryRow("SELECT val FROM kv WHERE key = ?", k).Scan(&out); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	return out, nil
+}
+
+// ListPrefix implements the store interface
+func (s *SQLiteStore) ListPrefix(prefix string) ([]string, error) {
+	// substr avoids the escaping of the LIKE wildcards in the prefix
+	rows, err := s.db.Query("SELECT val FROM kv WHERE substr(key, 1, ?) = ?", len(prefix), prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []string{}
+	for rows.Next() {
+		var val string
+		if err := rows.Scan(&val); err != nil {
+			return nil, err
+		}
+		out = append(out, val)
+	}
+	return out, rows.Err()
+}
+
+// ListKeys implements the store interface
+func (s *SQLiteStore) ListKeys(prefix string) ([]string, error) {
+	rows, err := s.db.Query("SELECT key FROM kv WHERE substr(key, 1, ?) = ? ORDER BY key", len(prefix), prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		out = append(out, key)
+	}
+	return out, rows.Err()
+}
+
+// Set implements the store interface
+func (s *SQLiteStore) Set(k, v string) error {
+	_, err := s.db.Exec("INSERT INTO kv (key, val) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET val = excluded.val", k, v)
+	return err
+}
+
+// Delete implements the store interface
+func (s *SQLiteStore) Delete(k string) error {
+	_, err := s.db.Exec("DELETE FROM kv WHERE key = ?", k)
+	return err
+}
+
+// GetEntry implements the store interface
+func (s *SQLiteStore) GetEntry(hash string) (store.Entry, error) {
+	if _, err := s.db.Exec("INSERT INTO entries (hash) VALUES (?) ON CONFLICT (hash) DO NOTHING", hash); err != nil {
+		return nil, err
+	}
+	e := &Entry{
+		hash: hash,
+		db:   s.db,
+	}
+	return e, nil
+}
+
+// ListEntries implements the store interface
+func (s *SQLiteStore) ListEntries() ([]string, error) {
+	rows, err := s.db.Query("SELECT hash FROM entries ORDER BY hash")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []string{}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		out = append(out, hash)
+	}
+	return out, rows.Err()
+}
+
+// Entry is an store.Entry implementation
+type Entry struct {
+	hash string
+	db   *sql.DB
+}
+
+// lastIndexSQL returns the index after the last log or the
+// first index if all the logs were pruned
+var lastIndexSQL = "SELECT MAX(first_index, COALESCE((SELECT MAX(indx) + 1 FROM logs WHERE entry = hash), 0)) FROM entries WHERE hash = ?"
+
+// LastIndex implements the store interface
+func (e *Entry) LastIndex() (uint64, error) {
+	var index int64
+	if err := e.db.QueryRow(lastIndexSQL, e.hash).Scan(&index); err != nil {
+		return 0, err
+	}
+	return uint64(index), nil
+}
+
+// FirstIndex implements the store interface
+func (e *Entry) FirstIndex() (uint64, error) {
+	var index int64
+	if err := e.db.QueryRow("SELECT first_index FROM entries WHERE hash = ?", e.hash).Scan(&index); err != nil {
+		return 0, err
+	}
+	return uint64(index), nil
+}
+
+// StoreLogs implements the store interface
+func (e *Entry) StoreLogs(logs []*core.Log) error {
+	tx, err := e.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var index int64
+	if err := tx.QueryRow(lastIndexSQL, e.hash).Scan(&index); err != nil {
+		return err
+	}
+	lastIndex := uint64(index)
+
+	stmt, err := tx.Prepare(insertLogSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for indx, log := range logs {
+		if len(log.Topics) > maxTopics {
+			return fmt.Errorf("log with %d topics, at most %d expected", len(log.Topics), maxTopics)
+		}
+		topics := make([]interface{}, maxTopics)
+		for i, topic := range log.Topics {
+			topics[i] = topic.Bytes()
+		}
+
+		_, err := stmt.Exec(
+			e.hash,
+			int64(lastIndex+uint64(indx)),
+			int64(log.BlockNumber),
+			log.BlockHash.Bytes(),
+			int64(log.TransactionIndex),
+			log.TransactionHash.Bytes(),
+			int64(log.LogIndex),
+			log.Address.Bytes(),
+			len(log.Topics),
+			topics[0], topics[1], topics[2], topics[3],
+			log.Data,
+			log.Removed,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RemoveLogs implements the store interface
+func (e *Entry) RemoveLogs(indx uint64) error {
+	_, err := e.db.Exec("DELETE FROM logs WHERE entry = ? AND indx >= ?", e.hash, int64(indx))
+	return err
+}
+
+// PruneLogs implements the store interface
+func (e *Entry) PruneLogs(indx uint64) error {
+	tx, err := e.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM logs WHERE entry = ? AND indx < ?", e.hash, int64(indx)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE entries SET first_index = MAX(first_index, ?) WHERE hash = ?", int64(indx), e.hash); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetLog implements the store interface
+func (e *Entry) GetLog(indx uint64, log *core.Log) error {
+	row := e.db.QueryRow("SELECT "+logColumns+" FROM logs WHERE entry = ? AND indx = ?", e.hash, int64(indx))
+	if err := scanLog(row, log); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("log %d not found", indx)
+		}
+		return err
+	}
+	return nil
+}
+
+// Query implements the store interface
+func (e *Entry) Query(q *store.LogQuery) (*store.LogQueryResult, error) {
+	where := []string{"entry = ?", "indx >= ?"}
+	args := []interface{}{e.hash, int64(q.Start)}
+
+	if q.FromBlock != nil {
+		where = append(where, "block_num >= ?")
+		args = append(args, int64(*q.FromBlock))
+	}
+	if q.ToBlock != nil {
+		where = append(where, "block_num <= ?")
+		args = append(args, int64(*q.ToBlock))
+	}
+	if q.TxHash != nil {
+		where = append(where, "tx_hash = ?")
+		args = append(args, q.TxHash.Bytes())
+	}
+	if len(q.Address) != 0 {
+		where = append(where, "address IN ("+placeholders(len(q.Address))+")")
+		for _, addr := range q.Address {
+			args = append(args, addr.Bytes())
+		}
+	}
+	for i, topics := range q.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		if i >= maxTopics {
+			// no log has a topic in this position
+			return &store.LogQueryResult{}, nil
+		}
+		where = append(where, fmt.Sprintf("topic%d IN (%s)", i, placeholders(len(topics))))
+		for _, topic := range topics {
+			args = append(args, topic.Bytes())
+		}
+	}
+
+	query := "SELECT indx, " + logColumns + " FROM logs WHERE " + strings.Join(where, " AND ") + " ORDER BY indx"
+	if q.Limit != 0 {
+		// query one more log to know if there is a next page
+		query += " LIMIT ?"
+		args = append(args, int64(q.Limit+1))
+	}
+
+	rows, err := e.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []*core.Log{}
+	indexes := []uint64{}
+	for rows.Next() {
+		var (
+			indx int64
+			log  core.Log
+		)
+		if err := scanLog(rows, &log, &indx); err != nil {
+			return nil, err
+		}
+		logs = append(logs, &log)
+		indexes = append(indexes, uint64(indx))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return q.Paginate(logs, indexes), nil
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanLog scans a row with the log columns, after the given dest values
+func scanLog(row scanner, log *core.Log, dest ...interface{}) error {
+	var (
+		blockNum, txIndex, logIndex int64
+		blockHash, txHash, address  []byte
+		numTopics                   int
+		topics                      [maxTopics][]byte
+		data                        []byte
+	)
+	err := row.Scan(append(dest,
+		&blockNum,
+		&blockHash,
+		&txIndex,
+		&txHash,
+		&logIndex,
+		&address,
+		&numTopics,
+		&topics[0], &topics[1], &topics[2], &topics[3],
+		&data,
+		&log.Removed,
+	)...)
+	if err != nil {
+		return err
+	}
+
+	log.BlockNumber = uint64(blockNum)
+	log.BlockHash = core.BytesToHash(blockHash)
+	log.TransactionIndex = uint64(txIndex)
+	log.TransactionHash = core.BytesToHash(txHash)
+	log.LogIndex = uint64(logIndex)
+	log.Address = core.BytesToAddress(address)
+
+	log.Topics = nil
+	if numTopics != 0 {
+		log.Topics = make([]core.Hash, numTopics)
+		for i := 0; i < numTopics; i++ {
+			log.Topics[i] = core.BytesToHash(topics[i])
+		}
+	}
+	log.Data = nil
+	if len(data) != 0 {
+		log.Data = data
+	}
+	return nil
+}
+
+var logColumns = strings.Join([]string{
+	"block_num",
+	"block_hash",
+	"tx_index",
+	"tx_hash",
+	"log_index",
+	"address",
+	"num_topics",
+	"topic0",
+	"topic1",
+	"topic2",
+	"topic3",
+	"data",
+	"removed",
+}, ", ")
+
+var insertLogSQL = "INSERT INTO logs (entry, indx, " + logColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+var sqlSchema = `
+CREATE TABLE IF NOT EXISTS kv (
+	key TEXT PRIMARY KEY,
+	val TEXT
+);
+
+CREATE TABLE IF NOT EXISTS entries (
+	hash        TEXT PRIMARY KEY,
+	first_index INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS logs (
+	entry      TEXT NOT NULL REFERENCES entries (hash),
+	indx       INTEGER NOT NULL,
+	block_num  INTEGER NOT NULL,
+	block_hash BLOB NOT NULL,
+	tx_index   INTEGER NOT NULL,
+	tx_hash    BLOB NOT NULL,
+	log_index  INTEGER NOT NULL,
+	address    BLOB NOT NULL,
+	num_topics INTEGER NOT NULL,
+	topic0     BLOB,
+	topic1     BLOB,
+	topic2     BLOB,
+	topic3     BLOB,
+	data       BLOB,
+	removed    BOOLEAN NOT NULL DEFAULT FALSE,
+	PRIMARY KEY (entry, indx)
+);
+
+CREATE INDEX IF NOT EXISTS logs_block_num ON logs (entry, block_num);
+CREATE INDEX IF NOT EXISTS logs_address ON logs (entry, address);
+CREATE INDEX IF NOT EXISTS logs_topic0 ON logs (entry, topic0);
+CREATE INDEX IF NOT EXISTS logs_topic1 ON logs (entry, topic1);
+CREATE INDEX IF NOT EXISTS logs_topic2 ON logs (entry, topic2);
+CREATE INDEX IF NOT EXISTS logs_topic3 ON logs (entry, topic3);
+CREATE INDEX IF NOT EXISTS logs_tx_hash ON logs (entry, tx_hash);
+`
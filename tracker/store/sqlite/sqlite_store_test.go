@@ -0,0 +1,74 @@
+package trackersqlite
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deep-nl/ethgo/core"
+	"github.com/deep-nl/ethgo/tracker/store"
+	"github.com/deep-nl/ethgo/tracker/store/inmem"
+)
+
+func setupDB(t *testing.T) (store.Store, func()) {
+	dir, err := ioutil.TempDir("/tmp", "sqlite-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "test.db")
+	store, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	close := func() {
+		if err := store.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store, close
+}
+
+func TestSQLiteStore(t *testing.T) {
+	store.TestStore(t, setupDB)
+}
+
+func TestSQLiteStore_ImportFromInmem(t *testing.T) {
+	src := inmem.NewInmemStore()
+	entry, err := src.GetEntry("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entry.StoreLogs([]*core.Log{{BlockNumber: 1}, {BlockNumber: 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := store.Export(src, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dst, close := setupDB(t)
+	defer close()
+
+	if err := store.Import(dst, &buf); err != nil {
+		t.Fatal(err)
+	}
+	entry, err = dst.GetEntry("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log core.Log
+	if err := entry.GetLog(1, &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.BlockNumber != 2 {
+		t.Fatal("bad")
+	}
+}
@@ -12,14 +12,23 @@ type Store interface {
 	// ListPrefix lists values by prefix
 	ListPrefix(prefix string) ([]string, error)
 
+	// ListKeys lists keys by prefix
+	ListKeys(prefix string) ([]string, error)
+
 	// Set sets a value
 	Set(k, v string) error
 
+	// Delete removes a value
+	Delete(k string) error
+
 	// Close closes the store
 	Close() error
 
 	// GetEntry returns a specific entry
 	GetEntry(hash string) (Entry, error)
+
+	// ListEntries returns the hashes of the entries
+	ListEntries() ([]string, error)
 }
 
 // Entry is a filter entry in the store
@@ -27,12 +36,22 @@ type Entry interface {
 	// LastIndex returns index of the last stored event
 	LastIndex() (uint64, error)
 
+	// FirstIndex returns the index of the first stored event
+	FirstIndex() (uint64, error)
+
 	// StoreLogs stores the web3 logs of the event
 	StoreLogs(logs []*core.Log) error
 
 	// RemoveLogs all the logs starting at index 'indx'
 	RemoveLogs(indx uint64) error
 
+	// PruneLogs removes all the logs before index 'indx'. The
+	// indexes of the pruned logs are not used again.
+	PruneLogs(indx uint64) error
+
 	// GetLog returns the log at indx
 	GetLog(indx uint64, log *core.Log) error
+
+	// Query returns the logs that match the query sorted by index
+	Query(q *LogQuery) (*LogQueryResult, error)
 }
@@ -1,9 +1,11 @@
 package store
 
 import (
-	"github.com/deep-nl/ethgo/core"
+	"bytes"
 	"reflect"
 	"testing"
+
+	"github.com/deep-nl/ethgo/core"
 )
 
 // SetupDB is a function that creates a backend
@@ -16,6 +18,9 @@ func TestStore(t *testing.T, setup SetupDB) {
 	testRemoveLogs(t, setup)
 	testStoreLogs(t, setup)
 	testPrefix(t, setup)
+	testQuery(t, setup)
+	testPruneLogs(t, setup)
+	testExport(t, setup)
 }
 
 func testMultipleStores(t *testing.T, setup SetupDB) {
@@ -139,6 +144,30 @@ func testGetSet(t *testing.T, setup SetupDB) {
 	if res != v2 {
 		t.Fatal("bad")
 	}
+
+	// delete the entry
+	if err := store.Delete(k1); err != nil {
+		t.Fatal(err)
+	}
+	res, err = store.Get(k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 0 {
+		t.Fatal("expected empty")
+	}
+	keys, err := store.ListKeys("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 0 {
+		t.Fatal("expected no keys")
+	}
+
+	// deleting a missing entry is not an error
+	if err := store.Delete(k1); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func testStoreLogs(t *testing.T, setup SetupDB) {
@@ -239,3 +268,278 @@ func testRemoveLogs(t *testing.T, setup SetupDB) {
 		t.Fatal("bad")
 	}
 }
+
+func testQuery(t *testing.T, setup SetupDB) {
+	store, close := setup(t)
+	defer close()
+
+	entry, err := store.GetEntry("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr0 := core.Address{0x1}
+	addr1 := core.Address{0x2}
+	topic0 := core.Hash{0x1}
+	topic1 := core.Hash{0x2}
+
+	// two logs per block with alternate addresses and topics
+	logs := []*core.Log{}
+	for i := uint64(0); i < 20; i++ {
+		log := &core.Log{
+			BlockNumber:     i / 2,
+			LogIndex:        i % 2,
+			TransactionHash: core.Hash{byte(i / 2)},
+			Address:         addr0,
+			Topics:          []core.Hash{topic0},
+		}
+		if i%2 == 1 {
+			log.Address = addr1
+			log.Topics = []core.Hash{topic0, topic1}
+		}
+		logs = append(logs, log)
+	}
+	if err := entry.StoreLogs(logs); err != nil {
+		t.Fatal(err)
+	}
+
+	checkQuery := func(q *LogQuery, expected []uint64, next uint64) {
+		t.Helper()
+
+		res, err := entry.Query(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(expected) == 0 {
+			expected = nil
+		}
+		indexes := res.Indexes
+		if len(indexes) == 0 {
+			indexes = nil
+		}
+		if !reflect.DeepEqual(indexes, expected) {
+			t.Fatalf("expected indexes %v but found %v", expected, indexes)
+		}
+		if len(res.Logs) != len(res.Indexes) {
+			t.Fatal("bad")
+		}
+		for i, log := range res.Logs {
+			if !reflect.DeepEqual(log, logs[res.Indexes[i]]) {
+				t.Fatalf("bad log %d", res.Indexes[i])
+			}
+		}
+		if res.Next != next {
+			t.Fatalf("expected next %d but found %d", next, res.Next)
+		}
+	}
+
+	// all the logs
+	all := []uint64{}
+	for i := uint64(0); i < 20; i++ {
+		all = append(all, i)
+	}
+	checkQuery(&LogQuery{}, all, 0)
+
+	// block range
+	q := &LogQuery{}
+	q.SetFromBlock(3)
+	q.SetToBlock(4)
+	checkQuery(q, []uint64{6, 7, 8, 9}, 0)
+
+	// address
+	q = &LogQuery{
+		Address: []core.Address{addr1},
+	}
+	q.SetToBlock(3)
+	checkQuery(q, []uint64{1, 3, 5, 7}, 0)
+
+	// topics
+	checkQuery(&LogQuery{Topics: [][]core.Hash{{}, {topic1}}, Start: 14}, []uint64{15, 17, 19}, 0)
+	checkQuery(&LogQuery{Topics: [][]core.Hash{{topic1}}}, nil, 0)
+	checkQuery(&LogQuery{Topics: [][]core.Hash{{topic0, topic1}}, Address: []core.Address{addr0, addr1}, Start: 17}, []uint64{17, 18, 19}, 0)
+
+	// transaction hash
+	q = &LogQuery{}
+	q.SetTxHash(core.Hash{0x5})
+	checkQuery(q, []uint64{10, 11}, 0)
+
+	// pagination
+	q = &LogQuery{
+		Address: []core.Address{addr0},
+		Limit:   4,
+	}
+	checkQuery(q, []uint64{0, 2, 4, 6}, 8)
+	q.Start = 8
+	checkQuery(q, []uint64{8, 10, 12, 14}, 16)
+	q.Start = 16
+	checkQuery(q, []uint64{16, 18}, 0)
+
+	// the removed logs are not returned
+	if err := entry.RemoveLogs(10); err != nil {
+		t.Fatal(err)
+	}
+	q = &LogQuery{}
+	q.SetTxHash(core.Hash{0x5})
+	checkQuery(q, nil, 0)
+	checkQuery(&LogQuery{Start: 8}, []uint64{8, 9}, 0)
+}
+
+func testPruneLogs(t *testing.T, setup SetupDB) {
+	store, close := setup(t)
+	defer close()
+
+	logs := []*core.Log{}
+	for i := uint64(0); i < 10; i++ {
+		logs = append(logs, &core.Log{
+			BlockNumber: i,
+		})
+	}
+
+	entry, err := store.GetEntry("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entry.StoreLogs(logs); err != nil {
+		t.Fatal(err)
+	}
+
+	checkIndexes := func(first, last uint64) {
+		t.Helper()
+
+		indx, err := entry.FirstIndex()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if indx != first {
+			t.Fatalf("expected first index %d but found %d", first, indx)
+		}
+		indx, err = entry.LastIndex()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if indx != last {
+			t.Fatalf("expected last index %d but found %d", last, indx)
+		}
+	}
+	checkIndexes(0, 10)
+
+	if err := entry.PruneLogs(4); err != nil {
+		t.Fatal(err)
+	}
+	checkIndexes(4, 10)
+
+	var log core.Log
+	if err := entry.GetLog(4, &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.BlockNumber != 4 {
+		t.Fatal("bad")
+	}
+	res, err := entry.Query(&LogQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Logs) != 6 || res.Indexes[0] != 4 {
+		t.Fatal("bad")
+	}
+
+	// pruning before the first index does nothing
+	if err := entry.PruneLogs(2); err != nil {
+		t.Fatal(err)
+	}
+	checkIndexes(4, 10)
+
+	// the indexes are not used again after all the logs are pruned
+	if err := entry.PruneLogs(10); err != nil {
+		t.Fatal(err)
+	}
+	checkIndexes(10, 10)
+
+	if err := entry.StoreLogs(logs[:1]); err != nil {
+		t.Fatal(err)
+	}
+	checkIndexes(10, 11)
+
+	if err := entry.GetLog(10, &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.BlockNumber != 0 {
+		t.Fatal("bad")
+	}
+}
+
+func testExport(t *testing.T, setup SetupDB) {
+	src, closeSrc := setup(t)
+	defer closeSrc()
+
+	if err := src.Set("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	logs := []*core.Log{}
+	for i := uint64(0); i < 10; i++ {
+		logs = append(logs, &core.Log{
+			BlockNumber: i,
+			Address:     core.Address{0x1},
+			Topics:      []core.Hash{{0x1}},
+			Data:        []byte{0x1, 0x2},
+		})
+	}
+	entry, err := src.GetEntry("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entry.StoreLogs(logs); err != nil {
+		t.Fatal(err)
+	}
+	if err := entry.PruneLogs(3); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Export(src, &buf); err != nil {
+		t.Fatal(err)
+	}
+	raw := buf.Bytes()
+
+	dst, closeDst := setup(t)
+	defer closeDst()
+
+	if err := Import(dst, bytes.NewReader(raw)); err != nil {
+		t.Fatal(err)
+	}
+
+	val, err := dst.Get("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val != "b" {
+		t.Fatal("bad")
+	}
+
+	entry, err = dst.GetEntry("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := entry.FirstIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != 3 {
+		t.Fatal("bad first index")
+	}
+	for i := uint64(3); i < 10; i++ {
+		var log core.Log
+		if err := entry.GetLog(i, &log); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(&log, logs[i]) {
+			t.Fatalf("bad log %d", i)
+		}
+	}
+
+	// the entries with logs cannot be imported again
+	if err := Import(dst, bytes.NewReader(raw)); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/deep-nl/ethgo/abi"
 	"github.com/deep-nl/ethgo/blocktracker"
 	"github.com/deep-nl/ethgo/etherscan"
 	"github.com/deep-nl/ethgo/jsonrpc/codec"
@@ -28,11 +29,19 @@ var (
 	dbChainID   = "chainID"
 	dbLastBlock = "lastBlock"
 	dbFilter    = "filter"
+	dbFinalized = "finalized"
+	dbHandler   = "handler"
+	dbRemoved   = "removed"
+
+	dbBlock       = "block"
+	dbBlocksFirst = "blocksFirst"
+	dbBlocksLast  = "blocksLast"
 )
 
 const (
 	defaultMaxBlockBacklog = 10
 	defaultBatchSize       = 100
+	defaultWorkers         = 1
 )
 
 // FilterConfig is a tracker filter configuration
@@ -41,7 +50,19 @@ type FilterConfig struct {
 	Topics  [][]*core.Hash `json:"topics"`
 	Start   uint64
 	Hash    string
-	Async   bool
+
+	// Async drops the events sent to EventCh if there is no reader
+	// instead of blocking the tracker
+	Async bool
+
+	// Events and the events of the ABI are used to decode the logs. If
+	// there are no Topics, the logs are filtered by the ids of the events.
+	Events []*abi.Event `json:"-"`
+	ABI    *abi.ABI     `json:"-"`
+
+	// Types are the structs (or pointers to them) used to decode
+	// the arguments of the events indexed by the name of the event
+	Types map[string]interface{} `json:"-"`
 }
 
 func (f *FilterConfig) buildHash() {
@@ -87,6 +108,16 @@ type Config struct {
 	EtherscanAPIKey string
 	Filter          *FilterConfig
 	Store           store.Store
+	Finality        core.BlockNumber
+	Workers         int
+	Retention       []RetentionPolicy
+	CompactInterval time.Duration
+
+	// IndexBlocks indexes the full transactions and the receipts of the
+	// blocks processed by the tracker in the BlockStore
+	IndexBlocks       bool
+	BlockStore        BlockStore
+	ReceiptsBatchSize int
 }
 
 type ConfigOption func(*Config)
@@ -121,13 +152,72 @@ func WithEtherscan(k string) ConfigOption {
 	}
 }
 
+// WithWorkers sets the number of concurrent eth_getLogs queries
+// during the historical sync
+func WithWorkers(n int) ConfigOption {
+	return func(c *Config) {
+		c.Workers = n
+	}
+}
+
+// WithFinality emits an EventFinalized event with the logs that reach
+// the block of the tag (core.Safe or core.Finalized)
+func WithFinality(tag core.BlockNumber) ConfigOption {
+	return func(c *Config) {
+		c.Finality = tag
+	}
+}
+
+// WithRetention prunes the logs that are not kept by any of the policies
+func WithRetention(policies ...RetentionPolicy) ConfigOption {
+	return func(c *Config) {
+		c.Retention = policies
+	}
+}
+
+// WithCompactInterval sets how often the retention policies are applied
+func WithCompactInterval(d time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.CompactInterval = d
+	}
+}
+
+// WithBlockIndexing fetches the full transactions and the receipts of each
+// new block and emits them in the events along with the logs. The blocks of
+// the historical sync below the block backlog are not indexed.
+func WithBlockIndexing() ConfigOption {
+	return func(c *Config) {
+		c.IndexBlocks = true
+	}
+}
+
+// WithBlockStore sets the store of the indexed blocks and enables the block
+// indexing. By default, the blocks are stored in the tracker store.
+func WithBlockStore(s BlockStore) ConfigOption {
+	return func(c *Config) {
+		c.IndexBlocks = true
+		c.BlockStore = s
+	}
+}
+
+// WithReceiptsBatchSize sets the number of concurrent eth_getTransactionReceipt
+// queries if the provider does not have eth_getBlockReceipts
+func WithReceiptsBatchSize(n int) ConfigOption {
+	return func(c *Config) {
+		c.ReceiptsBatchSize = n
+	}
+}
+
 // DefaultConfig returns the default tracker config
 func DefaultConfig() *Config {
 	return &Config{
-		BatchSize:       defaultBatchSize,
-		Store:           inmem.NewInmemStore(),
-		Filter:          &FilterConfig{},
-		EtherscanAPIKey: "",
+		BatchSize:         defaultBatchSize,
+		Workers:           defaultWorkers,
+		CompactInterval:   defaultCompactInterval,
+		ReceiptsBatchSize: defaultReceiptsBatchSize,
+		Store:             inmem.NewInmemStore(),
+		Filter:            &FilterConfig{},
+		EtherscanAPIKey:   "",
 	}
 }
 
@@ -147,12 +237,20 @@ type Tracker struct {
 	config       *Config
 	store        store.Store
 	entry        store.Entry
+	blockStore   BlockStore
 	preSyncOnce  sync.Once
 	blockTracker *blocktracker.BlockTracker
 	synced       int32
+	decoder      *eventDecoder
+	handlers     []*Handler
+	handlersLock sync.Mutex
+	handlersCtx  context.Context
+	compactOnce  sync.Once
+	getLogs      func(filter *core.LogFilter) ([]*core.Log, error)
+	getBlock     func(hash core.Hash) (*IndexedBlock, error)
 	BlockCh      chan *blocktracker.BlockEvent
 	ReadyCh      chan struct{}
-	SyncCh       chan uint64
+	SyncCh       chan *SyncProgress
 	EventCh      chan *Event
 	DoneCh       chan struct{}
 }
@@ -174,12 +272,23 @@ func NewTracker(provider Provider, opts ...ConfigOption) (*Tracker, error) {
 		blockTracker: config.BlockTracker,
 		DoneCh:       make(chan struct{}, 1),
 		EventCh:      make(chan *Event),
-		SyncCh:       make(chan uint64, 1),
+		SyncCh:       make(chan *SyncProgress, 1),
 		synced:       0,
 	}
+	t.getLogs = provider.GetLogs
+	t.getBlock = t.fetchBlock
 	if err := t.setupFilter(); err != nil {
 		return nil, err
 	}
+	if config.IndexBlocks {
+		if !hasReceipts(provider) {
+			return nil, fmt.Errorf("block indexing requires a provider with transaction receipts")
+		}
+		t.blockStore = config.BlockStore
+		if t.blockStore == nil {
+			t.blockStore = newKVBlockStore(t.store, t.config.Filter.Hash)
+		}
+	}
 	return t, nil
 }
 
@@ -190,6 +299,9 @@ func (t *Tracker) setupFilter() error {
 		t.config.Filter = &FilterConfig{}
 	}
 
+	t.config.Filter.buildTopics()
+	t.decoder = newEventDecoder(t.config.Filter)
+
 	// generate a random hash if not provided
 	if t.config.Filter.Hash == "" {
 		t.config.Filter.buildHash()
@@ -246,6 +358,8 @@ func (t *Tracker) GetLastBlock() (*core.Block, error) {
 
 func (t *Tracker) storeLastBlock(b *core.Block) error {
 	if b.Difficulty == nil {
+		// the block might be shared with other trackers
+		b = b.Copy()
 		b.Difficulty = big.NewInt(0)
 	}
 	buf, err := b.MarshalJSON()
@@ -260,6 +374,11 @@ func (t *Tracker) emitEvent(evnt *Event) {
 	if evnt == nil {
 		return
 	}
+	t.notifyHandlers()
+
+	if t.decoder != nil {
+		t.decodeEvent(evnt)
+	}
 	if t.config.Filter.Async {
 		select {
 		case t.EventCh <- evnt:
@@ -270,6 +389,12 @@ func (t *Tracker) emitEvent(evnt *Event) {
 	}
 }
 
+func (t *Tracker) decodeEvent(evnt *Event) {
+	evnt.AddedEvents = t.decoder.decode(evnt.Added)
+	evnt.RemovedEvents = t.decoder.decode(evnt.Removed)
+	evnt.FinalizedEvents = t.decoder.decode(evnt.Finalized)
+}
+
 // IsSynced returns true if the filter is synced to head
 func (t *Tracker) IsSynced() bool {
 	return atomic.LoadInt32(&t.synced) != 0
@@ -334,81 +459,127 @@ func (t *Tracker) emitLogs(typ EventType, logs []*core.Log) {
 	t.emitEvent(evnt)
 }
 
-func tooMuchDataRequestedError(err error) bool {
-	obj, ok := err.(*codec.ErrorObject)
-	if !ok {
-		return false
+// GetLastFinalized returns the number of the last finalized block for this filter
+func (t *Tracker) GetLastFinalized() (uint64, bool, error) {
+	buf, err := t.store.Get(dbFinalized + "_" + t.config.Filter.Hash)
+	if err != nil {
+		return 0, false, err
 	}
-	if obj.Message == "query returned more than 10000 results" {
-		return true
+	if len(buf) == 0 {
+		return 0, false, nil
 	}
-	return false
+	num, err := strconv.ParseUint(buf, 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return num, true, nil
 }
 
-func (t *Tracker) syncBatch(ctx context.Context, from, to uint64) error {
-	query := t.config.Filter.getFilterSearch()
-
-	batchSize := t.config.BatchSize
-	additiveFactor := uint64(float64(batchSize) * 0.10)
-
-	i := from
-
-START:
-	dst := min(to, i+batchSize)
+// checkFinalized emits the logs finalized since the last check
+func (t *Tracker) checkFinalized() error {
+	if t.config.Finality == 0 {
+		return nil
+	}
+	block, err := t.provider.GetBlockByNumber(t.config.Finality, false)
+	if err != nil {
+		return err
+	}
+	return t.finalize(block.Number)
+}
 
-	query.SetFromUint64(i)
-	query.SetToUint64(dst)
+// finalizedBatchSize is the maximum number of logs of each EventFinalized
+// event. A batch is only cut between blocks.
+var finalizedBatchSize = 1000
 
-	logs, err := t.provider.GetLogs(query)
+func (t *Tracker) finalize(num uint64) error {
+	last, ok, err := t.GetLastFinalized()
 	if err != nil {
-		if tooMuchDataRequestedError(err) {
-			// multiplicative decrease
-			batchSize = batchSize / 2
-			goto START
-		}
 		return err
 	}
-
-	if t.SyncCh != nil {
-		select {
-		case t.SyncCh <- dst:
-		default:
-		}
+	if ok && num <= last {
+		return nil
 	}
 
-	// add logs to the store
-	if err := t.entry.StoreLogs(logs); err != nil {
+	lastBlock, err := t.GetLastBlock()
+	if err != nil {
 		return err
 	}
-	t.emitLogs(EventAdd, logs)
+	if lastBlock == nil {
+		return nil
+	}
+	if lastBlock.Number < num {
+		// only the logs already processed are finalized
+		num = lastBlock.Number
+	}
 
-	// update the last block entry
-	block, err := t.provider.GetBlockByNumber(core.BlockNumber(dst), false)
+	index, err := t.entry.LastIndex()
 	if err != nil {
 		return err
 	}
-	if err := t.storeLastBlock(block); err != nil {
+	first, err := t.entry.FirstIndex()
+	if err != nil {
 		return err
 	}
 
-	// check if the execution is over after each query batch
-	if err := ctx.Err(); err != nil {
-		return err
+	// find the first log after the last finalized block
+	start := first
+	if ok {
+		for start = index; start > first; start-- {
+			var log core.Log
+			if err := t.entry.GetLog(start-1, &log); err != nil {
+				return err
+			}
+			if log.BlockNumber <= last {
+				break
+			}
+		}
 	}
 
-	i += batchSize + 1
-
-	// update the batchSize with additive increase
-	if batchSize < t.config.BatchSize {
-		batchSize = min(t.config.BatchSize, batchSize+additiveFactor)
+	var logs []*core.Log
+	for i := start; i < index; i++ {
+		var log core.Log
+		if err := t.entry.GetLog(i, &log); err != nil {
+			return err
+		}
+		if log.BlockNumber > num {
+			break
+		}
+		if len(logs) >= finalizedBatchSize && logs[len(logs)-1].BlockNumber != log.BlockNumber {
+			if err := t.emitFinalized(logs, logs[len(logs)-1].BlockNumber); err != nil {
+				return err
+			}
+			logs = nil
+		}
+		logs = append(logs, &log)
 	}
+	return t.emitFinalized(logs, num)
+}
 
-	if i <= to {
-		goto START
+// emitFinalized moves the finalized cursor to the block and emits the logs
+func (t *Tracker) emitFinalized(logs []*core.Log, num uint64) error {
+	if err := t.store.Set(dbFinalized+"_"+t.config.Filter.Hash, strconv.FormatUint(num, 10)); err != nil {
+		return err
+	}
+	if len(logs) != 0 {
+		t.emitEvent(&Event{
+			Type:      EventFinalized,
+			Finalized: logs,
+		})
 	}
 	return nil
 }
 
+func tooMuchDataRequestedError(err error) bool {
+	obj, ok := err.(*codec.ErrorObject)
+	if !ok {
+		return false
+	}
+	if obj.Message == "query returned more than 10000 results" {
+		return true
+	}
+	return false
+}
+
 func (t *Tracker) preSyncCheck() error {
 	var err error
 	t.preSyncOnce.Do(func() {
@@ -532,6 +703,7 @@ func (t *Tracker) BatchSync(ctx context.Context) error {
 	if err := t.preSyncCheck(); err != nil {
 		return err
 	}
+	t.startHandlers(ctx)
 
 	if t.blockTracker == nil {
 		// run a specfic block tracker
@@ -552,11 +724,19 @@ func (t *Tracker) BatchSync(ctx context.Context) error {
 		}
 	}
 
+	// the compactor reads the backlog of the block tracker
+	t.compactOnce.Do(func() {
+		t.startCompactor(ctx)
+	})
+
 	close(t.ReadyCh)
 
 	if err := t.syncImpl(ctx); err != nil {
 		return err
 	}
+	if err := t.checkFinalized(); err != nil {
+		return err
+	}
 
 	select {
 	case t.DoneCh <- struct{}{}:
@@ -672,7 +852,14 @@ func (t *Tracker) syncImpl(ctx context.Context) error {
 			if err != nil {
 				return err
 			}
-			t.emitLogs(EventDel, logs)
+			blocks, err := t.removeBlocks(ancestor + 1)
+			if err != nil {
+				return err
+			}
+			t.emitEvent(&Event{
+				Removed:       logs,
+				RemovedBlocks: blocks,
+			})
 
 			last, err = t.provider.GetBlockByNumber(core.BlockNumber(ancestor), false)
 			if err != nil {
@@ -734,9 +921,14 @@ func (t *Tracker) removeLogs(number uint64, hash *core.Hash) ([]*core.Log, error
 	if err != nil {
 		return nil, err
 	}
-	if index == 0 {
+	first, err := t.entry.FirstIndex()
+	if err != nil {
+		return nil, err
+	}
+	if index == first {
 		return nil, nil
 	}
+	last := index
 
 	var remove []*core.Log
 	for {
@@ -755,13 +947,20 @@ func (t *Tracker) removeLogs(number uint64, hash *core.Hash) ([]*core.Log, error
 			break
 		}
 		remove = append(remove, &log)
-		if elemIndex == 0 {
-			index = 0
+		if elemIndex == first {
+			index = first
 			break
 		}
 		index = elemIndex
 	}
 
+	t.handlersLock.Lock()
+	defer t.handlersLock.Unlock()
+
+	// move back the handlers before the logs are removed
+	if err := t.rewindHandlers(index, last, remove); err != nil {
+		return nil, err
+	}
 	if err := t.entry.RemoveLogs(index); err != nil {
 		return nil, err
 	}
@@ -794,6 +993,9 @@ func (t *Tracker) handleBlockEvnt(blockEvnt *blocktracker.BlockEvent) error {
 		if evnt != nil {
 			t.emitEvent(evnt)
 		}
+		if err := t.checkFinalized(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -807,8 +1009,17 @@ func (t *Tracker) doFilter(added []*core.Block, removed []*core.Block) (*Event,
 			return nil, err
 		}
 		evnt.Removed = append(evnt.Removed, revertLogs(logs)...)
+
+		blocks, err := t.removeBlocks(pivot.Number)
+		if err != nil {
+			return nil, err
+		}
+		evnt.RemovedBlocks = blocks
 	}
 
+	// query the logs and the indexed blocks before storing anything, if any
+	// query fails the blocks are processed again from the last block stored
+	addedLogs := []*core.Log{}
 	for _, block := range added {
 		// check logs for this blocks
 		query := t.config.Filter.getFilterSearch()
@@ -819,7 +1030,7 @@ func (t *Tracker) doFilter(added []*core.Block, removed []*core.Block) (*Event,
 		var err error
 
 		for i := 0; i < 5; i++ {
-			logs, err = t.provider.GetLogs(query)
+			logs, err = t.getLogs(query)
 			if err == nil {
 				break
 			}
@@ -828,13 +1039,22 @@ func (t *Tracker) doFilter(added []*core.Block, removed []*core.Block) (*Event,
 		if err != nil {
 			return nil, err
 		}
+		addedLogs = append(addedLogs, logs...)
+	}
 
-		// add logs to the store
-		if err := t.entry.StoreLogs(logs); err != nil {
+	if t.blockStore != nil {
+		blocks, err := t.indexBlocks(added)
+		if err != nil {
 			return nil, err
 		}
-		evnt.Added = append(evnt.Added, logs...)
+		evnt.AddedBlocks = blocks
+	}
+
+	// add logs to the store
+	if err := t.entry.StoreLogs(addedLogs); err != nil {
+		return nil, err
 	}
+	evnt.Added = append(evnt.Added, addedLogs...)
 
 	// store the last block as the new index
 	if err := t.storeLastBlock(added[len(added)-1]); err != nil {
@@ -851,13 +1071,28 @@ const (
 	EventAdd EventType = iota
 	// EventDel may happen when there is a reorg and a past event is deleted
 	EventDel
+	// EventFinalized happens when past events reach the finality tag of the
+	// tracker and cannot be removed anymore
+	EventFinalized
 )
 
 // Event is an event emitted when a new log is included
 type Event struct {
-	Type    EventType
-	Added   []*core.Log
-	Removed []*core.Log
+	Type      EventType
+	Added     []*core.Log
+	Removed   []*core.Log
+	Finalized []*core.Log
+
+	// AddedEvents, RemovedEvents and FinalizedEvents are the logs decoded
+	// with the events of the filter, if any
+	AddedEvents     []*DecodedEvent
+	RemovedEvents   []*DecodedEvent
+	FinalizedEvents []*DecodedEvent
+
+	// AddedBlocks and RemovedBlocks are the blocks included and
+	// removed from the chain if the block indexing is enabled
+	AddedBlocks   []*IndexedBlock
+	RemovedBlocks []*IndexedBlock
 }
 
 // BlockEvent is an event emitted when a new block is included
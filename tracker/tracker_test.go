@@ -16,6 +16,7 @@ import (
 	"github.com/deep-nl/ethgo/jsonrpc"
 	"github.com/deep-nl/ethgo/jsonrpc/codec"
 	"github.com/deep-nl/ethgo/testutil"
+	"github.com/deep-nl/ethgo/testutil/simulated"
 	"github.com/deep-nl/ethgo/tracker/store/inmem"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -44,7 +45,7 @@ func testFilter(t *testing.T, provider Provider, filterConfig *FilterConfig) []*
 func TestPolling(t *testing.T) {
 	t.Skip()
 
-	s := testutil.NewTestServer(t)
+	s, _ := simulated.NewTestServer(t)
 
 	client, _ := jsonrpc.NewClient(s.HTTPAddr())
 
@@ -101,9 +102,10 @@ EXIT:
 }
 
 func TestFilterIntegration(t *testing.T) {
-	s := testutil.NewTestServer(t)
+	s, chain := simulated.NewTestServer(t)
 
-	client, _ := jsonrpc.NewClient(s.HTTPAddr())
+	client := jsonrpc.NewClientWithTransport(chain.Transport())
+	defer client.Close()
 
 	c0 := &testutil.Contract{}
 	c0.AddEvent(testutil.NewEvent("A").Add("uint256", true).Add("uint256", true))
@@ -774,3 +776,72 @@ func TestTooMuchDataRequested(t *testing.T) {
 		t.Fatal("not the same count")
 	}
 }
+
+func TestTrackerFinality(t *testing.T) {
+	l := testutil.MockList{}
+	l.Create(0, 30, func(b *testutil.MockBlock) {
+		b.Log("0x1")
+	})
+
+	m := &testutil.MockClient{}
+	m.AddScenario(l)
+	m.SetBlockTag(core.Finalized, 20)
+
+	// the finalized logs of the first sync are emitted in batches
+	defer func(size int) {
+		finalizedBatchSize = size
+	}(finalizedBatchSize)
+	finalizedBatchSize = 5
+
+	tt, err := NewTracker(m,
+		testConfig(),
+		WithFinality(core.Finalized),
+	)
+	require.NoError(t, err)
+
+	go func() {
+		if err := tt.Sync(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+
+	var added, finalized []*core.Log
+	batches := 0
+	for {
+		select {
+		case evnt := <-tt.EventCh:
+			added = append(added, evnt.Added...)
+			if evnt.Type == EventFinalized {
+				require.LessOrEqual(t, len(evnt.Finalized), 5)
+				finalized = append(finalized, evnt.Finalized...)
+				batches++
+			}
+		case <-tt.DoneCh:
+			goto EXIT
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout to sync")
+		}
+	}
+EXIT:
+	require.Len(t, added, 30)
+	require.True(t, testutil.CompareLogs(l.GetLogs()[:21], finalized))
+	require.Equal(t, 5, batches)
+
+	num, ok, err := tt.GetLastFinalized()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(20), num)
+
+	// only the new finalized logs are emitted
+	go func() {
+		require.NoError(t, tt.finalize(25))
+	}()
+
+	select {
+	case evnt := <-tt.EventCh:
+		require.Equal(t, EventFinalized, evnt.Type)
+		require.True(t, testutil.CompareLogs(l.GetLogs()[21:26], evnt.Finalized))
+	case <-time.After(2 * time.Second):
+		t.Fatal("finalized event expected")
+	}
+}